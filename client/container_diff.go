@@ -0,0 +1,78 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opencontainers/image-spec/identity"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/containerd/containerd/v2/core/diff"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/rootfs"
+)
+
+// ExportRWDiff computes the diff of the container's writable layer against its
+// parent snapshot and writes it to the content store, returning the descriptor
+// of the diff blob. The container's task should be paused (or stopped) while
+// the diff is computed, otherwise writes racing with the export may be lost.
+//
+// Together with ImportRWDiff this is a building block for migrating a
+// container between nodes: the returned blob can be streamed to a peer's
+// content store with the existing content and transfer APIs and re-applied
+// there on top of the same image chain.
+func (c *Client) ExportRWDiff(ctx context.Context, containerID string, opts ...diff.Opt) (ocispec.Descriptor, error) {
+	info, err := c.ContainerService().Get(ctx, containerID)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if info.SnapshotKey == "" {
+		return ocispec.Descriptor{}, fmt.Errorf("container %s has no snapshot", containerID)
+	}
+	if len(opts) == 0 {
+		opts = []diff.Opt{
+			diff.WithReference(fmt.Sprintf("export-rw-%s", containerID)),
+		}
+	}
+	return rootfs.CreateDiff(ctx, info.SnapshotKey, c.SnapshotService(info.Snapshotter), c.DiffService(), opts...)
+}
+
+// ImportRWDiff prepares an active snapshot with the given key on top of the
+// image's rootfs chain and applies a previously exported writable layer diff
+// to it. The image must already be unpacked for the given snapshotter. On
+// success the mounts of the new active snapshot are returned; the caller can
+// use them to create a container resuming from the exported state.
+func (c *Client) ImportRWDiff(ctx context.Context, snapshotter, key string, image Image, desc ocispec.Descriptor) ([]mount.Mount, error) {
+	diffIDs, err := image.RootFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parent := identity.ChainID(diffIDs).String()
+
+	sn := c.SnapshotService(snapshotter)
+	mounts, err := sn.Prepare(ctx, key, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare snapshot on parent %s: %w", parent, err)
+	}
+	if _, err := c.DiffService().Apply(ctx, desc, mounts); err != nil {
+		sn.Remove(ctx, key)
+		return nil, fmt.Errorf("failed to apply rw diff %s: %w", desc.Digest, err)
+	}
+	return mounts, nil
+}
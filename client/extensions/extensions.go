@@ -0,0 +1,105 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package extensions exposes typed constants and helpers for the snapshot
+// labels specific to this containerd distribution, so tooling built on the
+// client does not hard-code label strings.
+package extensions
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/containerd/containerd/v2/core/snapshots"
+)
+
+const (
+	// LabelWritableLayerPath is the snapshot label under which snapshotters
+	// with a quota driver report the on-disk writable layer directory of an
+	// active snapshot. Same value as snapshots.LabelWritableLayerPath.
+	LabelWritableLayerPath = snapshots.LabelWritableLayerPath
+
+	// LabelActiveQuota is the snapshot label requesting a size limit in
+	// bytes for the writable layer of a new active snapshot, enforced by the
+	// snapshotter's quota driver. Same value as the overlay snapshotter's
+	// SnapshotterLabelOverlayActiveQuota.
+	LabelActiveQuota = "containerd.io/snapshot/overlay.active-quota"
+
+	// LabelRWLayerPod is the snapshot label carrying the pod sandbox ID a
+	// writable layer belongs to, consumed by rw-layer drivers e.g. to
+	// encrypt the layer with per-pod keys.
+	LabelRWLayerPod = "containerd.io/snapshot/rwlayer.pod"
+
+	// LabelOverlaybdStatsPrefix is the snapshot label prefix under which the
+	// overlaybd proxy snapshotter reports on-demand fetch statistics, e.g.
+	// "remote-bytes-fetched" or "cache-hit-count".
+	LabelOverlaybdStatsPrefix = "containerd.io/snapshot/overlaybd.stats/"
+)
+
+// WithActiveQuota labels a new active snapshot with a writable layer size
+// limit in bytes.
+func WithActiveQuota(size int64) snapshots.Opt {
+	return snapshots.WithLabels(map[string]string{
+		LabelActiveQuota: strconv.FormatInt(size, 10),
+	})
+}
+
+// WithRWLayerPod associates a new active snapshot with a pod sandbox ID, so
+// a configured rw-layer driver prepares its writable layer for that pod.
+func WithRWLayerPod(podID string) snapshots.Opt {
+	return snapshots.WithLabels(map[string]string{
+		LabelRWLayerPod: podID,
+	})
+}
+
+// WritableLayerPath returns the on-disk writable layer directory of an
+// active snapshot, when the snapshotter reports it.
+func WritableLayerPath(info snapshots.Info) (string, bool) {
+	path, ok := info.Labels[LabelWritableLayerPath]
+	return path, ok
+}
+
+// ActiveQuota returns the writable layer size limit of a snapshot in bytes,
+// when one was requested.
+func ActiveQuota(info snapshots.Info) (int64, bool) {
+	v, ok := info.Labels[LabelActiveQuota]
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return size, true
+}
+
+// OverlaybdStats returns the on-demand fetch statistics of a snapshot as
+// reported by the overlaybd snapshotter, keyed without the label prefix.
+// Nil when the snapshot reports none.
+func OverlaybdStats(info snapshots.Info) map[string]string {
+	var stats map[string]string
+	for k, v := range info.Labels {
+		name := strings.TrimPrefix(k, LabelOverlaybdStatsPrefix)
+		if name == k || name == "" {
+			continue
+		}
+		if stats == nil {
+			stats = make(map[string]string)
+		}
+		stats[name] = v
+	}
+	return stats
+}
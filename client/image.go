@@ -36,6 +36,7 @@ import (
 	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/identity"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/sync/semaphore"
 )
 
 // Image describes an image used by containers
@@ -258,6 +259,12 @@ type UnpackConfig struct {
 	// in-flight fetch request or unpack handler for a given descriptor's
 	// digest or chain ID.
 	DuplicationSuppressor kmutex.KeyedLocker
+	// VerifyDiffID is whether to recompute the diffID of each applied layer
+	// and verify it against the image config before committing the snapshot.
+	VerifyDiffID bool
+	// ApplyLimiter bounds the layer applications running at once. It may be
+	// shared across pulls to enforce a global limit.
+	ApplyLimiter *semaphore.Weighted
 }
 
 // UnpackOpt provides configuration for unpack
@@ -271,6 +278,14 @@ func WithSnapshotterPlatformCheck() UnpackOpt {
 	}
 }
 
+// WithUnpackVerifyDiffID sets `VerifyDiffID` on the UnpackConfig
+func WithUnpackVerifyDiffID() UnpackOpt {
+	return func(ctx context.Context, uc *UnpackConfig) error {
+		uc.VerifyDiffID = true
+		return nil
+	}
+}
+
 // WithUnpackDuplicationSuppressor sets `DuplicationSuppressor` on the UnpackConfig.
 func WithUnpackDuplicationSuppressor(suppressor kmutex.KeyedLocker) UnpackOpt {
 	return func(ctx context.Context, uc *UnpackConfig) error {
@@ -279,6 +294,14 @@ func WithUnpackDuplicationSuppressor(suppressor kmutex.KeyedLocker) UnpackOpt {
 	}
 }
 
+// WithUnpackApplyLimiter sets `ApplyLimiter` on the UnpackConfig.
+func WithUnpackApplyLimiter(l *semaphore.Weighted) UnpackOpt {
+	return func(ctx context.Context, uc *UnpackConfig) error {
+		uc.ApplyLimiter = l
+		return nil
+	}
+}
+
 // WithUnpackApplyOpts appends new apply options on the UnpackConfig.
 func WithUnpackApplyOpts(opts ...diff.ApplyOpt) UnpackOpt {
 	return func(ctx context.Context, uc *UnpackConfig) error {
@@ -107,6 +107,8 @@ func (c *Client) Pull(ctx context.Context, ref string, opts ...RemoteOpt) (_ Ima
 			SnapshotOpts:   append(pullCtx.SnapshotterOpts, uconfig.SnapshotOpts...),
 			Applier:        c.DiffService(),
 			ApplyOpts:      uconfig.ApplyOpts,
+			Comparer:       c.DiffService(),
+			VerifyDiffID:   uconfig.VerifyDiffID,
 		}
 		uopts := []unpack.UnpackerOpt{unpack.WithUnpackPlatform(platform)}
 		if pullCtx.MaxConcurrentDownloads > 0 {
@@ -115,6 +117,9 @@ func (c *Client) Pull(ctx context.Context, ref string, opts ...RemoteOpt) (_ Ima
 		if uconfig.DuplicationSuppressor != nil {
 			uopts = append(uopts, unpack.WithDuplicationSuppressor(uconfig.DuplicationSuppressor))
 		}
+		if uconfig.ApplyLimiter != nil {
+			uopts = append(uopts, unpack.WithApplyLimiter(uconfig.ApplyLimiter))
+		}
 		unpacker, err = unpack.NewUnpacker(ctx, c.ContentStore(), uopts...)
 		if err != nil {
 			return nil, fmt.Errorf("unable to initialize unpacker: %w", err)
@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// containerd-coredump-helper is a kernel core_pattern pipe handler which
+// redirects core dumps of containerized processes into size-capped per-pod
+// directories managed by containerd. Configure it with e.g.
+//
+//	kernel.core_pattern = |/usr/local/bin/containerd-coredump-helper -dir /var/lib/containerd/coredumps -max-size 134217728 %P
+//
+// Dumps of processes which cannot be attributed to a container are discarded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/containerd/containerd/v2/pkg/coredump"
+)
+
+func main() {
+	var (
+		dir     = flag.String("dir", "", "base directory for per-pod core dumps")
+		maxSize = flag.Int64("max-size", 0, "maximum size in bytes of a single dump, 0 for no limit")
+	)
+	flag.Parse()
+	if err := run(*dir, *maxSize, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, maxSize int64, args []string) error {
+	if dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("expected the dumping pid (core_pattern %%P) as single argument")
+	}
+	pid, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid pid %q: %w", args[0], err)
+	}
+
+	podID, containerID, err := coredump.ResolveIDs(pid)
+	if err != nil {
+		return fmt.Errorf("failed to attribute core dump of pid %d: %w", pid, err)
+	}
+	if _, err := coredump.EnsureDir(dir, podID); err != nil {
+		return err
+	}
+	return coredump.WriteCapped(os.Stdin, coredump.DumpPath(dir, podID, containerID, pid), maxSize)
+}
@@ -117,6 +117,7 @@ can be used and modified as necessary as a custom configuration.`
 		configCommand,
 		publishCommand,
 		ociHook,
+		restoreCommand,
 	}
 	app.Action = func(context *cli.Context) error {
 		var (
@@ -262,6 +263,13 @@ can be used and modified as necessary as a custom configuration.`
 			}
 			serve(ctx, l, server.ServeMetrics)
 		}
+		if config.Metrics.CgroupMetricsAddress != "" {
+			l, err := net.Listen("tcp", config.Metrics.CgroupMetricsAddress)
+			if err != nil {
+				return fmt.Errorf("failed to get listener for cgroup metrics endpoint: %w", err)
+			}
+			serve(ctx, l, server.ServeCgroupMetrics)
+		}
 		// setup the ttrpc endpoint
 		tl, err := sys.GetLocalListener(config.TTRPC.Address, config.TTRPC.UID, config.TTRPC.GID)
 		if err != nil {
@@ -0,0 +1,54 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package command
+
+import (
+	"errors"
+
+	"github.com/containerd/containerd/v2/defaults"
+	"github.com/containerd/containerd/v2/plugins/replication"
+	"github.com/urfave/cli/v2"
+)
+
+var restoreCommand = &cli.Command{
+	Name:  "restore-metadata",
+	Usage: "Restore metadata databases from a replication target directory",
+	Description: `Copy the metadata databases written by the metadata-replicator plugin back
+under the containerd root, e.g. after a disk replacement. The daemon must be
+stopped while restoring.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "source",
+			Usage: "Directory holding the replicated databases",
+		},
+		&cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Replace databases which already exist under the root",
+		},
+	},
+	Action: func(context *cli.Context) error {
+		source := context.String("source")
+		if source == "" {
+			return errors.New("a source directory must be provided with --source")
+		}
+		root := context.String("root")
+		if root == "" {
+			root = defaults.DefaultRootDir
+		}
+		return replication.Restore(source, root, context.Bool("overwrite"))
+	},
+}
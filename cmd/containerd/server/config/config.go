@@ -85,6 +85,10 @@ type Config struct {
 	Plugins map[string]interface{} `toml:"plugins"`
 	// OOMScore adjust the containerd's oom score
 	OOMScore int `toml:"oom_score"`
+	// MaxProcs overrides GOMAXPROCS for the containerd daemon. When zero,
+	// GOMAXPROCS is tuned to the daemon's own cgroup CPU quota instead of the
+	// machine's CPU count.
+	MaxProcs int `toml:"max_procs"`
 	// Cgroup specifies cgroup information for the containerd daemon process
 	Cgroup CgroupConfig `toml:"cgroup"`
 	// ProxyPlugins configures plugins which are communicated to over GRPC
@@ -225,6 +229,12 @@ type Debug struct {
 type MetricsConfig struct {
 	Address       string `toml:"address"`
 	GRPCHistogram bool   `toml:"grpc_histogram"`
+	// CgroupMetricsAddress optionally serves the high-cardinality per-container
+	// cgroup metrics ("container_*" series) on a separate endpoint. When set,
+	// those series are removed from the main metrics address, which keeps
+	// serving the low-cardinality daemon metrics, so scrape intervals and
+	// access control can differ between the two.
+	CgroupMetricsAddress string `toml:"cgroup_metrics_address"`
 }
 
 // CgroupConfig provides cgroup configuration
@@ -30,6 +30,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,6 +41,8 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
@@ -56,6 +59,7 @@ import (
 	sbproxy "github.com/containerd/containerd/v2/core/sandbox/proxy"
 	ssproxy "github.com/containerd/containerd/v2/core/snapshots/proxy"
 	"github.com/containerd/containerd/v2/defaults"
+	"github.com/containerd/containerd/v2/internal/cputune"
 	"github.com/containerd/containerd/v2/pkg/deprecation"
 	"github.com/containerd/containerd/v2/pkg/dialer"
 	"github.com/containerd/containerd/v2/pkg/sys"
@@ -128,6 +132,9 @@ func New(ctx context.Context, config *srvconfig.Config) (*Server, error) {
 	if err := apply(ctx, config); err != nil {
 		return nil, err
 	}
+	// Tune GOMAXPROCS after the daemon has joined its cgroup so the detected
+	// CPU quota is the one it actually runs under.
+	cputune.Apply(config.MaxProcs)
 	for key, sec := range config.Timeouts {
 		d, err := time.ParseDuration(sec)
 		if err != nil {
@@ -152,6 +159,8 @@ func New(ctx context.Context, config *srvconfig.Config) (*Server, error) {
 	prometheusServerMetrics := grpc_prometheus.NewServerMetrics(prometheusServerMetricsOpts...)
 	prometheus.MustRegister(prometheusServerMetrics)
 
+	registerSelfMetrics(config.Root)
+
 	serverOpts := []grpc.ServerOption{
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainStreamInterceptor(
@@ -393,10 +402,22 @@ func (s *Server) ServeTTRPC(l net.Listener) error {
 	return trapClosedConnErr(s.ttrpcServer.Serve(context.Background(), l))
 }
 
+// cgroupMetricsPrefix matches the per-container cgroup metric families
+// registered by the cgroup metrics namespace.
+const cgroupMetricsPrefix = "container_"
+
 // ServeMetrics provides a prometheus endpoint for exposing metrics
 func (s *Server) ServeMetrics(l net.Listener) error {
 	m := http.NewServeMux()
-	m.Handle("/v1/metrics", metrics.Handler())
+	handler := metrics.Handler()
+	if s.config.Metrics.CgroupMetricsAddress != "" {
+		// The per-container cgroup metrics are served by ServeCgroupMetrics
+		// on their own address instead.
+		handler = filteredMetricsHandler(func(name string) bool {
+			return !strings.HasPrefix(name, cgroupMetricsPrefix)
+		})
+	}
+	m.Handle("/v1/metrics", handler)
 	srv := &http.Server{
 		Handler:           m,
 		ReadHeaderTimeout: 5 * time.Minute, // "G112: Potential Slowloris Attack (gosec)"; not a real concern for our use, so setting a long timeout.
@@ -404,6 +425,39 @@ func (s *Server) ServeMetrics(l net.Listener) error {
 	return trapClosedConnErr(srv.Serve(l))
 }
 
+// ServeCgroupMetrics provides a prometheus endpoint exposing only the
+// high-cardinality per-container cgroup metrics.
+func (s *Server) ServeCgroupMetrics(l net.Listener) error {
+	m := http.NewServeMux()
+	m.Handle("/v1/metrics", filteredMetricsHandler(func(name string) bool {
+		return strings.HasPrefix(name, cgroupMetricsPrefix)
+	}))
+	srv := &http.Server{
+		Handler:           m,
+		ReadHeaderTimeout: 5 * time.Minute,
+	}
+	return trapClosedConnErr(srv.Serve(l))
+}
+
+// filteredMetricsHandler serves the default prometheus gatherer restricted to
+// metric families whose name is accepted by match.
+func filteredMetricsHandler(match func(name string) bool) http.Handler {
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		mfs, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			return nil, err
+		}
+		filtered := mfs[:0]
+		for _, mf := range mfs {
+			if match(mf.GetName()) {
+				filtered = append(filtered, mf)
+			}
+		}
+		return filtered, nil
+	})
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
 // ServeTCP allows services to serve over tcp
 func (s *Server) ServeTCP(l net.Listener) error {
 	s.prometheusServerMetrics.InitializeMetrics(s.tcpServer)
@@ -421,6 +475,9 @@ func (s *Server) ServeDebug(l net.Listener) error {
 	m.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
 	m.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
 	m.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	m.Handle("/debug/tasks", http.HandlerFunc(s.handleTasksDebug))
+	m.Handle("/debug/shim-logs", http.HandlerFunc(s.handleShimLogsDebug))
+	m.Handle("/debug/resources", http.HandlerFunc(s.handleResourcesDebug))
 	srv := &http.Server{
 		Handler:           m,
 		ReadHeaderTimeout: 5 * time.Minute, // "G112: Potential Slowloris Attack (gosec)"; not a real concern for our use, so setting a long timeout.
@@ -0,0 +1,184 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"time"
+
+	"github.com/containerd/log"
+	"github.com/containerd/plugin"
+
+	"github.com/containerd/containerd/v2/core/runtime"
+	runtimev2 "github.com/containerd/containerd/v2/core/runtime/v2"
+	"github.com/containerd/containerd/v2/plugins"
+)
+
+// debugProcess describes one process of a task including its threads on the
+// /debug/tasks endpoint.
+type debugProcess struct {
+	Pid     uint32   `json:"pid"`
+	Threads []uint32 `json:"threads,omitempty"`
+}
+
+// debugTask describes one task on the /debug/tasks endpoint.
+type debugTask struct {
+	Namespace string         `json:"namespace"`
+	ID        string         `json:"id"`
+	Processes []debugProcess `json:"processes,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// handleTasksDebug serves a listing of all tasks with their processes and
+// threads for debugging purposes.
+func (s *Server) handleTasksDebug(w http.ResponseWriter, r *http.Request) {
+	rt, err := s.taskRuntime()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	tasks, err := rt.Tasks(ctx, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]debugTask, 0, len(tasks))
+	for _, t := range tasks {
+		dt := debugTask{
+			Namespace: t.Namespace(),
+			ID:        t.ID(),
+		}
+		pids, err := t.Pids(ctx)
+		if err != nil {
+			// Keep going, a single unreachable shim should not break the listing.
+			dt.Error = err.Error()
+			resp = append(resp, dt)
+			continue
+		}
+		for _, p := range pids {
+			dt.Processes = append(dt.Processes, debugProcess{
+				Pid:     p.Pid,
+				Threads: processThreads(p.Pid),
+			})
+		}
+		resp = append(resp, dt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.G(ctx).WithError(err).Error("failed to encode tasks debug response")
+	}
+}
+
+// handleShimLogsDebug serves the persisted log of a shim, optionally filtered
+// by a time range. Query parameters: `namespace` and `id` select the shim,
+// `since` and `until` (RFC3339) bound the returned entries.
+func (s *Server) handleShimLogsDebug(w http.ResponseWriter, r *http.Request) {
+	var (
+		ns = r.URL.Query().Get("namespace")
+		id = r.URL.Query().Get("id")
+	)
+	if ns == "" || id == "" {
+		http.Error(w, "namespace and id parameters are required", http.StatusBadRequest)
+		return
+	}
+	// Reject path traversal in the shim selector.
+	if filepath.Base(ns) != ns || filepath.Base(id) != id {
+		http.Error(w, "invalid namespace or id", http.StatusBadRequest)
+		return
+	}
+	var since, until time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	if v := r.URL.Query().Get("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid until parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+		until = t
+	}
+
+	bundlePath := filepath.Join(s.config.State, plugins.RuntimePluginV2.String()+".task", ns, id)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := runtimev2.QueryShimLogs(bundlePath, since, until, w); err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, fmt.Sprintf("no shim log for %s/%s", ns, id), http.StatusNotFound)
+			return
+		}
+		log.G(r.Context()).WithError(err).Error("failed to query shim logs")
+	}
+}
+
+// taskRuntime returns the v2 task runtime plugin instance.
+func (s *Server) taskRuntime() (runtime.PlatformRuntime, error) {
+	var p *plugin.Plugin
+	for _, pl := range s.plugins {
+		if pl.Registration.Type == plugins.RuntimePluginV2 && pl.Registration.ID == "task" {
+			p = pl
+			break
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("task runtime plugin not found")
+	}
+	i, err := p.Instance()
+	if err != nil {
+		return nil, err
+	}
+	rt, ok := i.(runtime.PlatformRuntime)
+	if !ok {
+		return nil, fmt.Errorf("unexpected task runtime plugin type %T", i)
+	}
+	return rt, nil
+}
+
+// processThreads returns the thread IDs of a process read from procfs. It
+// returns nil on platforms without procfs or when the process is gone.
+func processThreads(pid uint32) []uint32 {
+	if goruntime.GOOS != "linux" {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join("/proc", strconv.FormatUint(uint64(pid), 10), "task"))
+	if err != nil {
+		return nil
+	}
+	threads := make([]uint32, 0, len(entries))
+	for _, e := range entries {
+		tid, err := strconv.ParseUint(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		threads = append(threads, uint32(tid))
+	}
+	return threads
+}
@@ -48,6 +48,7 @@ var Command = &cli.Command{
 	Usage:   "Manage snapshots",
 	Flags:   commands.SnapshotterFlags,
 	Subcommands: cli.Commands{
+		cleanupCommand,
 		commitCommand,
 		diffCommand,
 		infoCommand,
@@ -63,6 +64,24 @@ var Command = &cli.Command{
 	},
 }
 
+var cleanupCommand = &cli.Command{
+	Name:  "cleanup",
+	Usage: "Ask the snapshotter to remove resources no snapshot references, e.g. leaked after a crash",
+	Action: func(context *cli.Context) error {
+		client, ctx, cancel, err := commands.NewClient(context)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		snapshotter := client.SnapshotService(context.String("snapshotter"))
+		c, ok := snapshotter.(snapshots.Cleaner)
+		if !ok {
+			return fmt.Errorf("snapshotter does not support cleanup")
+		}
+		return c.Cleanup(ctx)
+	},
+}
+
 var listCommand = &cli.Command{
 	Name:    "list",
 	Aliases: []string{"ls"},
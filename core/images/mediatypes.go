@@ -54,6 +54,11 @@ const (
 	// MediaTypeDockerSchema1Manifest is the legacy Docker schema1 manifest
 	MediaTypeDockerSchema1Manifest = "application/vnd.docker.distribution.manifest.v1+prettyjws"
 
+	// WebAssembly artifact media types
+
+	MediaTypeWasmConfig       = "application/vnd.wasm.config.v1+json"
+	MediaTypeWasmContentLayer = "application/vnd.wasm.content.layer.v1+wasm"
+
 	// Encrypted media types
 
 	MediaTypeImageLayerEncrypted     = ocispec.MediaTypeImageLayer + "+encrypted"
@@ -135,6 +140,9 @@ func IsLayerType(mt string) bool {
 	if strings.HasPrefix(mt, "application/vnd.oci.image.layer.") {
 		return true
 	}
+	if strings.HasPrefix(mt, "application/vnd.wasm.content.layer.") {
+		return true
+	}
 
 	// Parse Docker media types, strip off any + suffixes first
 	switch base, _ := parseMediaTypes(mt); base {
@@ -175,7 +183,7 @@ func IsIndexType(mt string) bool {
 // No support for containerd checkpoint configs.
 func IsConfigType(mt string) bool {
 	switch mt {
-	case MediaTypeDockerSchema2Config, ocispec.MediaTypeImageConfig:
+	case MediaTypeDockerSchema2Config, ocispec.MediaTypeImageConfig, MediaTypeWasmConfig:
 		return true
 	default:
 		return false
@@ -186,7 +194,7 @@ func IsConfigType(mt string) bool {
 // including containerd checkpoint configs
 func IsKnownConfig(mt string) bool {
 	switch mt {
-	case MediaTypeDockerSchema2Config, ocispec.MediaTypeImageConfig,
+	case MediaTypeDockerSchema2Config, ocispec.MediaTypeImageConfig, MediaTypeWasmConfig,
 		MediaTypeContainerd1Checkpoint, MediaTypeContainerd1CheckpointConfig:
 		return true
 	}
@@ -0,0 +1,92 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NetworkStat holds the counters of one network interface as seen from a
+// task's network namespace.
+type NetworkStat struct {
+	Name      string
+	RxBytes   uint64
+	RxPackets uint64
+	RxErrors  uint64
+	RxDropped uint64
+	TxBytes   uint64
+	TxPackets uint64
+	TxErrors  uint64
+	TxDropped uint64
+}
+
+// ReadNetworkStats returns the per-interface counters of the network
+// namespace of the given process, read from /proc/<pid>/net/dev.
+func ReadNetworkStats(pid uint32) ([]NetworkStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/net/dev", pid))
+	if err != nil {
+		return nil, err
+	}
+	return parseNetDev(string(data)), nil
+}
+
+// parseNetDev parses the /proc/<pid>/net/dev format: two header lines
+// followed by one "<interface>: <16 counters>" line per interface, the first
+// eight counters for receive and the following eight for transmit.
+func parseNetDev(data string) []NetworkStat {
+	var stats []NetworkStat
+	for i, line := range strings.Split(data, "\n") {
+		if i < 2 {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) < 16 {
+			continue
+		}
+		values := make([]uint64, 16)
+		var err error
+		for i := range values {
+			if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+		stats = append(stats, NetworkStat{
+			Name:      strings.TrimSpace(name),
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			RxErrors:  values[2],
+			RxDropped: values[3],
+			TxBytes:   values[8],
+			TxPackets: values[9],
+			TxErrors:  values[10],
+			TxDropped: values[11],
+		})
+	}
+	return stats
+}
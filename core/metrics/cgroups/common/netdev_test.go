@@ -0,0 +1,57 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNetDev(t *testing.T) {
+	data := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:    1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0
+  eth0: 5000000    4000    2    1    0     0          0         0  3000000    2500    3    4    0     0       0          0
+`
+	stats := parseNetDev(data)
+	assert.Equal(t, []NetworkStat{
+		{
+			Name:      "lo",
+			RxBytes:   1000,
+			RxPackets: 10,
+			TxBytes:   1000,
+			TxPackets: 10,
+		},
+		{
+			Name:      "eth0",
+			RxBytes:   5000000,
+			RxPackets: 4000,
+			RxErrors:  2,
+			RxDropped: 1,
+			TxBytes:   3000000,
+			TxPackets: 2500,
+			TxErrors:  3,
+			TxDropped: 4,
+		},
+	}, stats)
+
+	// Malformed and truncated lines are skipped.
+	assert.Empty(t, parseNetDev("header\nheader\neth0: 1 2 3\nnot a stats line\n"))
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
 	"github.com/docker/go-metrics"
 )
 
@@ -38,12 +39,16 @@ func NewTaskMonitor(ctx context.Context, publisher events.Publisher, ns *metrics
 	if err != nil {
 		return nil, err
 	}
-	return &cgroupsMonitor{
+	m := &cgroupsMonitor{
 		collector: collector,
 		oom:       oom,
 		context:   ctx,
 		publisher: publisher,
-	}, nil
+	}
+	if subscriber, ok := publisher.(events.Subscriber); ok {
+		go m.watchExits(ctx, subscriber)
+	}
+	return m, nil
 }
 
 type cgroupsMonitor struct {
@@ -85,6 +90,37 @@ func (m *cgroupsMonitor) Stop(c runtime.Task) error {
 	return nil
 }
 
+// watchExits reacts to task lifecycle events instead of waiting for the next
+// scrape: an exit captures a final stats sample and then removes the task, so
+// short-lived containers appear in metrics and series of exited containers do
+// not linger; an OOM captures a sample immediately so the state change is
+// visible at the next scrape even if the task is killed right after.
+func (m *cgroupsMonitor) watchExits(ctx context.Context, subscriber events.Subscriber) {
+	evts, errs := subscriber.Subscribe(ctx, `topic=="/tasks/exit"`, `topic=="/tasks/oom"`)
+	for {
+		select {
+		case e := <-evts:
+			evt, err := typeurl.UnmarshalAny(e.Event)
+			if err != nil {
+				continue
+			}
+			switch ev := evt.(type) {
+			case *eventstypes.TaskExit:
+				if ev.ID == ev.ContainerID {
+					m.collector.CaptureFinal(ev.ContainerID, e.Namespace)
+				}
+			case *eventstypes.TaskOOM:
+				m.collector.Trigger(ev.ContainerID, e.Namespace)
+			}
+		case err := <-errs:
+			if err != nil {
+				log.G(ctx).WithError(err).Warn("cgroup metrics event watcher stopped")
+			}
+			return
+		}
+	}
+}
+
 func (m *cgroupsMonitor) trigger(id, namespace string, cg cgroups.Cgroup) {
 	ctx := namespaces.WithNamespace(m.context, namespace)
 	if err := m.publisher.Publish(ctx, runtime.TaskOOMEventTopic, &eventstypes.TaskOOM{
@@ -0,0 +1,128 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/containerd/containerd/v2/core/metrics/cgroups/common"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// networkMetric is a per-interface metric read from the task's network
+// namespace rather than from the cgroup stats.
+type networkMetric struct {
+	name     string
+	help     string
+	unit     metrics.Unit
+	vt       prometheus.ValueType
+	getValue func(s common.NetworkStat) float64
+}
+
+func (m *networkMetric) desc(ns *metrics.Namespace) *prometheus.Desc {
+	return ns.NewDesc(m.name, m.help, m.unit, IDName, "namespace", "interface")
+}
+
+func (m *networkMetric) collect(id, namespace string, stats []common.NetworkStat, ns *metrics.Namespace, ch chan<- prometheus.Metric, block bool) {
+	for _, s := range stats {
+		pm := prometheus.MustNewConstMetric(m.desc(ns), m.vt, m.getValue(s), id, namespace, s.Name)
+		if block {
+			ch <- pm
+			continue
+		}
+		select {
+		case ch <- pm:
+		default:
+		}
+	}
+}
+
+var networkMetrics = []*networkMetric{
+	{
+		name: "network_rx",
+		help: "The network interface received bytes",
+		unit: metrics.Bytes,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.RxBytes)
+		},
+	},
+	{
+		name: "network_rx_packets",
+		help: "The network interface received packets",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.RxPackets)
+		},
+	},
+	{
+		name: "network_rx_errors",
+		help: "The network interface receive errors",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.RxErrors)
+		},
+	},
+	{
+		name: "network_rx_dropped",
+		help: "The network interface received packets dropped",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.RxDropped)
+		},
+	},
+	{
+		name: "network_tx",
+		help: "The network interface transmitted bytes",
+		unit: metrics.Bytes,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.TxBytes)
+		},
+	},
+	{
+		name: "network_tx_packets",
+		help: "The network interface transmitted packets",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.TxPackets)
+		},
+	},
+	{
+		name: "network_tx_errors",
+		help: "The network interface transmit errors",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.TxErrors)
+		},
+	},
+	{
+		name: "network_tx_dropped",
+		help: "The network interface transmitted packets dropped",
+		unit: metrics.Total,
+		vt:   prometheus.GaugeValue,
+		getValue: func(s common.NetworkStat) float64 {
+			return float64(s.TxDropped)
+		},
+	},
+}
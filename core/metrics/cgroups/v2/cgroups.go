@@ -21,19 +21,26 @@ package v2
 import (
 	"context"
 
+	eventstypes "github.com/containerd/containerd/v2/api/events"
 	"github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/containerd/v2/core/runtime"
+	"github.com/containerd/log"
+	"github.com/containerd/typeurl/v2"
 	"github.com/docker/go-metrics"
 )
 
 // NewTaskMonitor returns a new cgroups monitor
 func NewTaskMonitor(ctx context.Context, publisher events.Publisher, ns *metrics.Namespace) (runtime.TaskMonitor, error) {
 	collector := NewCollector(ns)
-	return &cgroupsMonitor{
+	m := &cgroupsMonitor{
 		collector: collector,
 		context:   ctx,
 		publisher: publisher,
-	}, nil
+	}
+	if subscriber, ok := publisher.(events.Subscriber); ok {
+		go m.watchExits(ctx, subscriber)
+	}
+	return m, nil
 }
 
 type cgroupsMonitor struct {
@@ -53,3 +60,35 @@ func (m *cgroupsMonitor) Stop(c runtime.Task) error {
 	m.collector.Remove(c)
 	return nil
 }
+
+// watchExits reacts to task lifecycle events instead of waiting for the next
+// scrape: an exit captures a final stats sample and then removes the task, so
+// short-lived containers appear in metrics and series of exited containers do
+// not linger; an OOM captures a sample immediately so the state change is
+// visible at the next scrape even if the task is killed right after.
+func (m *cgroupsMonitor) watchExits(ctx context.Context, subscriber events.Subscriber) {
+	evts, errs := subscriber.Subscribe(ctx, `topic=="/tasks/exit"`, `topic=="/tasks/oom"`)
+	for {
+		select {
+		case e := <-evts:
+			evt, err := typeurl.UnmarshalAny(e.Event)
+			if err != nil {
+				continue
+			}
+			switch ev := evt.(type) {
+			case *eventstypes.TaskExit:
+				if ev.ID == ev.ContainerID {
+					m.collector.CaptureFinal(ev.ContainerID, e.Namespace)
+				}
+			case *eventstypes.TaskOOM:
+				m.collector.Trigger(ev.ContainerID, e.Namespace)
+			}
+		case err := <-errs:
+			if err != nil {
+				log.G(ctx).WithError(err).Warn("cgroup metrics event watcher stopped")
+			}
+			return
+		}
+	}
+}
+
@@ -48,6 +48,7 @@ func NewCollector(ns *metrics.Namespace) *Collector {
 	c.metrics = append(c.metrics, cpuMetrics...)
 	c.metrics = append(c.metrics, memoryMetrics...)
 	c.metrics = append(c.metrics, ioMetrics...)
+	c.metrics = append(c.metrics, pressureMetrics...)
 	c.storedMetrics = make(chan prometheus.Metric, 100*len(c.metrics))
 	ns.Add(c)
 	return c
@@ -59,6 +60,9 @@ func taskID(id, namespace string) string {
 
 type entry struct {
 	task common.Statable
+	// pid of the task's init process, used to read the network statistics of
+	// its network namespace. Zero when unknown.
+	pid uint32
 	// ns is an optional child namespace that contains additional to parent labels.
 	// This can be used to append task specific labels to be able to differentiate the different containerd metrics.
 	ns *metrics.Namespace
@@ -102,6 +106,9 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	for _, m := range c.metrics {
 		ch <- m.desc(c.ns)
 	}
+	for _, m := range networkMetrics {
+		ch <- m.desc(c.ns)
+	}
 }
 
 // Collect prometheus metrics
@@ -158,6 +165,13 @@ func (c *Collector) collect(entry entry, ch chan<- prometheus.Metric, block bool
 	for _, m := range c.metrics {
 		m.collect(t.ID(), t.Namespace(), s, ns, ch, block)
 	}
+	if entry.pid != 0 {
+		if netStats, err := common.ReadNetworkStats(entry.pid); err == nil {
+			for _, m := range networkMetrics {
+				m.collect(t.ID(), t.Namespace(), netStats, ns, ch, block)
+			}
+		}
+	}
 }
 
 // Add adds the provided cgroup and id so that metrics are collected and exported
@@ -173,6 +187,17 @@ func (c *Collector) Add(t common.Statable, labels map[string]string) error {
 		return nil // requests to collect metrics should be idempotent
 	}
 	entry := entry{task: t}
+	// Capture the task pid once so per-interface network statistics can be
+	// read from its network namespace on every scrape.
+	if p, ok := t.(interface {
+		PID(context.Context) (uint32, error)
+	}); ok {
+		ctx, cancel := timeout.WithContext(namespaces.WithNamespace(context.Background(), t.Namespace()), cmetrics.ShimStatsRequestTimeout)
+		if pid, err := p.PID(ctx); err == nil {
+			entry.pid = pid
+		}
+		cancel()
+	}
 	if labels != nil {
 		entry.ns = c.ns.WithConstLabels(labels)
 	}
@@ -192,6 +217,43 @@ func (c *Collector) Remove(t common.Statable) {
 	delete(c.tasks, taskID(t.ID(), t.Namespace()))
 }
 
+// Trigger captures a stats sample of the task with the given id and
+// namespace outside of the scrape cycle and stores it for the next scrape,
+// e.g. when an OOM event flags a state change that should be visible
+// immediately.
+func (c *Collector) Trigger(id, namespace string) {
+	if c.ns == nil {
+		return
+	}
+	c.mu.RLock()
+	entry, ok := c.tasks[taskID(id, namespace)]
+	c.mu.RUnlock()
+	if !ok {
+		return
+	}
+	c.collect(entry, c.storedMetrics, false, nil)
+}
+
+// CaptureFinal stores one last stats sample of an exiting task for the next
+// scrape before removing it from the collector, so even containers whose
+// whole life fits between two scrapes appear in metrics.
+func (c *Collector) CaptureFinal(id, namespace string) {
+	c.Trigger(id, namespace)
+	c.RemoveByID(id, namespace)
+}
+
+// RemoveByID removes the task with the given id and namespace from the
+// collector without needing the task handle, e.g. when its exit event is
+// observed before the task is deleted.
+func (c *Collector) RemoveByID(id, namespace string) {
+	if c.ns == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tasks, taskID(id, namespace))
+}
+
 // RemoveAll statable items from the collector
 func (c *Collector) RemoveAll() {
 	if c.ns == nil {
@@ -0,0 +1,95 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	v2 "github.com/containerd/containerd/v2/core/metrics/types/v2"
+	metrics "github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pressureMetrics exposes the pressure stall information of the cpu, memory
+// and io controllers, labeled by kind ("some": at least one task stalled,
+// "full": all tasks stalled). The kernel only reports PSI when built with
+// CONFIG_PSI; without it the stats are nil and no series are emitted.
+var pressureMetrics = func() []*metric {
+	var ms []*metric
+	for controller, getPSI := range map[string]func(stats *v2.Metrics) *v2.PSIStats{
+		"cpu":    func(stats *v2.Metrics) *v2.PSIStats { return stats.GetCPU().GetPSI() },
+		"memory": func(stats *v2.Metrics) *v2.PSIStats { return stats.GetMemory().GetPSI() },
+		"io":     func(stats *v2.Metrics) *v2.PSIStats { return stats.GetIo().GetPSI() },
+	} {
+		ms = append(ms, psiMetrics(controller, getPSI)...)
+	}
+	return ms
+}()
+
+// psiMetrics builds the pressure stall metrics of one controller.
+func psiMetrics(controller string, getPSI func(stats *v2.Metrics) *v2.PSIStats) []*metric {
+	byKind := func(get func(data *v2.PSIData) float64) func(stats *v2.Metrics) []value {
+		return func(stats *v2.Metrics) []value {
+			psi := getPSI(stats)
+			if psi == nil {
+				return nil
+			}
+			var values []value
+			if psi.Some != nil {
+				values = append(values, value{v: get(psi.Some), l: []string{"some"}})
+			}
+			if psi.Full != nil {
+				values = append(values, value{v: get(psi.Full), l: []string{"full"}})
+			}
+			return values
+		}
+	}
+	return []*metric{
+		{
+			name:      controller + "_pressure_avg10",
+			help:      "The " + controller + " pressure stall average over 10 seconds (cgroup v2)",
+			unit:      metrics.Unit("percent"),
+			vt:        prometheus.GaugeValue,
+			labels:    []string{"kind"},
+			getValues: byKind(func(data *v2.PSIData) float64 { return data.Avg10 }),
+		},
+		{
+			name:      controller + "_pressure_avg60",
+			help:      "The " + controller + " pressure stall average over 60 seconds (cgroup v2)",
+			unit:      metrics.Unit("percent"),
+			vt:        prometheus.GaugeValue,
+			labels:    []string{"kind"},
+			getValues: byKind(func(data *v2.PSIData) float64 { return data.Avg60 }),
+		},
+		{
+			name:      controller + "_pressure_avg300",
+			help:      "The " + controller + " pressure stall average over 300 seconds (cgroup v2)",
+			unit:      metrics.Unit("percent"),
+			vt:        prometheus.GaugeValue,
+			labels:    []string{"kind"},
+			getValues: byKind(func(data *v2.PSIData) float64 { return data.Avg300 }),
+		},
+		{
+			name:      controller + "_pressure_total",
+			help:      "The " + controller + " pressure total stall time (cgroup v2)",
+			unit:      metrics.Unit("microseconds"),
+			vt:        prometheus.GaugeValue,
+			labels:    []string{"kind"},
+			getValues: byKind(func(data *v2.PSIData) float64 { return float64(data.Total) }),
+		},
+	}
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/containerd/log"
+)
+
+// BlobSource supplies additional registry hosts serving image blobs for a
+// registry host, such as a P2P distribution network or a pull-through
+// proxy. Source hosts are only trusted to pull blobs: manifests are still
+// resolved at the configured hosts, and fetches fall back to them when a
+// source host errors.
+type BlobSource interface {
+	// Hosts returns the hosts blobs for the given registry host may be
+	// fetched from, in priority order. An empty result means the source
+	// does not serve this host.
+	Hosts(host string) ([]RegistryHost, error)
+}
+
+var (
+	blobSourcesMu sync.RWMutex
+	blobSources   []namedBlobSource
+)
+
+type namedBlobSource struct {
+	name   string
+	source BlobSource
+}
+
+// RegisterBlobSource makes a blob source available to WithBlobSources.
+// Sources are consulted in registration order. It is expected to be called
+// from the init function of a backend package and panics on a duplicate
+// name.
+func RegisterBlobSource(name string, source BlobSource) {
+	blobSourcesMu.Lock()
+	defer blobSourcesMu.Unlock()
+	for _, s := range blobSources {
+		if s.name == name {
+			panic(fmt.Sprintf("blob source %q is already registered", name))
+		}
+	}
+	blobSources = append(blobSources, namedBlobSource{name: name, source: source})
+}
+
+// WithBlobSources layers the registered blob sources over base. The source
+// hosts serving a registry host are tried first, restricted to pulling, and
+// the base hosts follow so fetches fall back to the origin on error. A
+// failing source is logged and skipped rather than failing the pull.
+func WithBlobSources(base RegistryHosts) RegistryHosts {
+	return func(host string) ([]RegistryHost, error) {
+		hosts, err := base(host)
+		if err != nil {
+			return nil, err
+		}
+
+		blobSourcesMu.RLock()
+		sources := blobSources
+		blobSourcesMu.RUnlock()
+
+		var extra []RegistryHost
+		for _, s := range sources {
+			sh, err := s.source.Hosts(host)
+			if err != nil {
+				log.L.WithError(err).Warnf("blob source %q failed for host %q", s.name, host)
+				continue
+			}
+			for _, h := range sh {
+				// Source hosts may pull blobs but are never trusted to
+				// resolve manifests or push.
+				h.Capabilities = HostCapabilityPull
+				extra = append(extra, h)
+			}
+		}
+		if len(extra) == 0 {
+			return hosts, nil
+		}
+		return append(extra, hosts...), nil
+	}
+}
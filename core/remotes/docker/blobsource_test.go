@@ -0,0 +1,86 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package docker
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeBlobSource struct {
+	hosts map[string][]RegistryHost
+	err   error
+}
+
+func (s *fakeBlobSource) Hosts(host string) ([]RegistryHost, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.hosts[host], nil
+}
+
+func TestWithBlobSources(t *testing.T) {
+	defer func(prev []namedBlobSource) {
+		blobSourcesMu.Lock()
+		blobSources = prev
+		blobSourcesMu.Unlock()
+	}(blobSources)
+
+	origin := RegistryHost{
+		Host:         "registry.example.com",
+		Capabilities: HostCapabilityResolve | HostCapabilityPull,
+	}
+	base := func(host string) ([]RegistryHost, error) {
+		return []RegistryHost{origin}, nil
+	}
+
+	RegisterBlobSource("p2p", &fakeBlobSource{
+		hosts: map[string][]RegistryHost{
+			"registry.example.com": {{
+				Host:         "p2p.local:65001",
+				Capabilities: HostCapabilityResolve | HostCapabilityPull | HostCapabilityPush,
+			}},
+		},
+	})
+	RegisterBlobSource("broken", &fakeBlobSource{err: errors.New("agent down")})
+
+	hosts, err := WithBlobSources(base)("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(hosts))
+	}
+	if hosts[0].Host != "p2p.local:65001" {
+		t.Errorf("expected the source host first, got %q", hosts[0].Host)
+	}
+	if hosts[0].Capabilities != HostCapabilityPull {
+		t.Errorf("source host capabilities not restricted to pull: %v", hosts[0].Capabilities)
+	}
+	if hosts[1].Host != origin.Host || hosts[1].Capabilities != origin.Capabilities {
+		t.Errorf("origin host not preserved as fallback: %+v", hosts[1])
+	}
+
+	// A host no source serves gets the base hosts untouched.
+	hosts, err = WithBlobSources(base)("other.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Host != origin.Host {
+		t.Errorf("expected only the origin host, got %+v", hosts)
+	}
+}
@@ -53,6 +53,11 @@ type hostConfig struct {
 
 	header http.Header
 
+	policy docker.RequestPolicy
+
+	maxConcurrentDownloads int
+	maxIdleConns           int
+
 	// TODO: Add credential configuration (domain alias, username)
 }
 
@@ -65,6 +70,16 @@ type HostOptions struct {
 	// UpdateClient will be called after creating http.Client object, so clients can provide extra configuration
 	UpdateClient   UpdateClientFunc
 	AuthorizerOpts []docker.AuthorizerOpt
+	// HeaderProvider returns additional headers to send to the given
+	// registry host, merged over any statically configured headers. It is
+	// consulted on every host lookup, so it may return short-lived values
+	// such as auth tokens or trace identifiers.
+	HeaderProvider func(host string) (http.Header, error)
+	// CacheHosts caches the configured hosts per registry host instead of
+	// re-reading the host directory on every lookup. Cached entries are
+	// invalidated by a watcher on the host's configuration directory, so
+	// changes to hosts.toml still take effect without a restart.
+	CacheHosts bool
 }
 
 // ConfigureHosts creates a registry hosts function from the provided
@@ -72,7 +87,7 @@ type HostOptions struct {
 // certificate files laid out in the Docker specific layout.
 // If a `HostDir` function is not required, defaults are used.
 func ConfigureHosts(ctx context.Context, options HostOptions) docker.RegistryHosts {
-	return func(host string) ([]docker.RegistryHost, error) {
+	configure := func(host string) ([]docker.RegistryHost, error) {
 		var hosts []hostConfig
 		if options.HostDir != nil {
 			dir, err := options.HostDir(host)
@@ -178,8 +193,11 @@ func ConfigureHosts(ctx context.Context, options HostOptions) docker.RegistryHos
 			// Allow setting for each host as well
 			explicitTLS := tlsConfigured
 
-			if host.caCerts != nil || host.clientPairs != nil || host.skipVerify != nil {
+			hostTLS := host.caCerts != nil || host.clientPairs != nil || host.skipVerify != nil
+			if hostTLS {
 				explicitTLS = true
+			}
+			if hostTLS || host.maxIdleConns > 0 || host.maxConcurrentDownloads > 0 {
 				tr := defaultTransport.Clone()
 				tlsConfig := tr.TLSClientConfig
 				if host.skipVerify != nil {
@@ -227,8 +245,18 @@ func ConfigureHosts(ctx context.Context, options HostOptions) docker.RegistryHos
 					tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
 				}
 
+				if host.maxIdleConns > 0 {
+					// Give the host its own idle pool so a busy mirror
+					// does not evict other hosts' connections.
+					tr.MaxIdleConns = host.maxIdleConns
+					tr.MaxIdleConnsPerHost = host.maxIdleConns
+				}
+
 				c := *client
 				c.Transport = tr
+				if host.maxConcurrentDownloads > 0 {
+					c.Transport = newLimitTransport(c.Transport, host.maxConcurrentDownloads)
+				}
 				if options.UpdateClient != nil {
 					if err := options.UpdateClient(&c); err != nil {
 						return nil, err
@@ -262,11 +290,50 @@ func ConfigureHosts(ctx context.Context, options HostOptions) docker.RegistryHos
 			rhosts[i].Path = host.path
 			rhosts[i].Capabilities = host.capabilities
 			rhosts[i].Header = host.header
+			rhosts[i].RequestPolicy = host.policy
 		}
 
 		return rhosts, nil
 	}
 
+	lookup := configure
+	if options.CacheHosts && options.HostDir != nil {
+		cache := newHostsCache(configure, options.HostDir)
+		lookup = func(host string) ([]docker.RegistryHost, error) {
+			return cache.get(ctx, host)
+		}
+	}
+	if options.HeaderProvider == nil {
+		return lookup
+	}
+
+	return func(host string) ([]docker.RegistryHost, error) {
+		rhosts, err := lookup(host)
+		if err != nil {
+			return nil, err
+		}
+		header, err := options.HeaderProvider(host)
+		if err != nil {
+			return nil, fmt.Errorf("header provider failed for host %q: %w", host, err)
+		}
+		if len(header) == 0 {
+			return rhosts, nil
+		}
+		// Merge into copies so cached hosts keep only the static headers.
+		merged := make([]docker.RegistryHost, len(rhosts))
+		for i, rhost := range rhosts {
+			h := make(http.Header, len(rhost.Header)+len(header))
+			for k, v := range rhost.Header {
+				h[k] = append([]string(nil), v...)
+			}
+			for k, v := range header {
+				h[k] = append([]string(nil), v...)
+			}
+			rhost.Header = h
+			merged[i] = rhost
+		}
+		return merged, nil
+	}
 }
 
 // HostDirFromRoot returns a function which finds a host directory
@@ -351,6 +418,32 @@ type hostFileConfig struct {
 	// API root endpoint.
 	OverridePath bool `toml:"override_path"`
 
+	// Retries is the number of additional attempts made against this host
+	// after a request fails with a connection error or a 5xx response.
+	Retries int `toml:"retries"`
+
+	// RetryBackoff is the delay before the first retry, doubling on each
+	// subsequent retry, given in golang duration format.
+	RetryBackoff string `toml:"retry_backoff"`
+
+	// RequestTimeout bounds a single request attempt including reading
+	// the response body, given in golang duration format.
+	RequestTimeout string `toml:"request_timeout"`
+
+	// HedgeDelay starts a fetch on the next host when this host has not
+	// answered within the delay, keeping whichever attempt succeeds
+	// first, given in golang duration format.
+	HedgeDelay string `toml:"hedge_delay"`
+
+	// MaxConcurrentDownloads bounds the number of in-flight requests to
+	// this host. A slot is held until the response body is closed, so one
+	// huge pull cannot starve requests to other hosts.
+	MaxConcurrentDownloads int `toml:"max_concurrent_downloads"`
+
+	// MaxIdleConns bounds the idle connections kept in this host's
+	// connection pool.
+	MaxIdleConns int `toml:"max_idle_conns"`
+
 	// TODO: Credentials: helper? name? username? alternate domain? token?
 }
 
@@ -511,6 +604,37 @@ func parseHostConfig(server string, baseDir string, config hostFileConfig) (host
 		result.header = header
 	}
 
+	if config.Retries < 0 {
+		return hostConfig{}, fmt.Errorf("invalid negative value %d for \"retries\"", config.Retries)
+	}
+	result.policy.Retries = config.Retries
+	if config.MaxConcurrentDownloads < 0 {
+		return hostConfig{}, fmt.Errorf("invalid negative value %d for \"max_concurrent_downloads\"", config.MaxConcurrentDownloads)
+	}
+	result.maxConcurrentDownloads = config.MaxConcurrentDownloads
+	if config.MaxIdleConns < 0 {
+		return hostConfig{}, fmt.Errorf("invalid negative value %d for \"max_idle_conns\"", config.MaxIdleConns)
+	}
+	result.maxIdleConns = config.MaxIdleConns
+	for _, d := range []struct {
+		name  string
+		value string
+		field *time.Duration
+	}{
+		{"retry_backoff", config.RetryBackoff, &result.policy.RetryBackoff},
+		{"request_timeout", config.RequestTimeout, &result.policy.RequestTimeout},
+		{"hedge_delay", config.HedgeDelay, &result.policy.HedgeDelay},
+	} {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return hostConfig{}, fmt.Errorf("invalid duration %q for %q: %w", d.value, d.name, err)
+		}
+		*d.field = parsed
+	}
+
 	return result, nil
 }
 
@@ -0,0 +1,128 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// hostsCache caches configured registry hosts per registry host and
+// invalidates entries when the configuration directory they were read from
+// changes, so hosts.toml edits are picked up on the next lookup.
+type hostsCache struct {
+	configure docker.RegistryHosts
+	hostDir   func(string) (string, error)
+
+	mu      sync.Mutex
+	hosts   map[string][]docker.RegistryHost
+	dirs    map[string][]string // watched directory -> hosts configured from it
+	watcher *fsnotify.Watcher
+}
+
+func newHostsCache(configure docker.RegistryHosts, hostDir func(string) (string, error)) *hostsCache {
+	return &hostsCache{
+		configure: configure,
+		hostDir:   hostDir,
+		hosts:     map[string][]docker.RegistryHost{},
+		dirs:      map[string][]string{},
+	}
+}
+
+func (c *hostsCache) get(ctx context.Context, host string) ([]docker.RegistryHost, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rhosts, ok := c.hosts[host]; ok {
+		return rhosts, nil
+	}
+
+	rhosts, err := c.configure(host)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := c.hostDir(host)
+	if err != nil || dir == "" {
+		// No host directory to watch, return uncached so a directory
+		// created later is found on the next lookup.
+		return rhosts, nil
+	}
+	if err := c.watch(ctx, dir); err != nil {
+		log.G(ctx).WithError(err).WithField("dir", dir).Debug("not caching host configuration, directory cannot be watched")
+		return rhosts, nil
+	}
+	c.dirs[dir] = append(c.dirs[dir], host)
+	c.hosts[host] = rhosts
+
+	return rhosts, nil
+}
+
+func (c *hostsCache) watch(ctx context.Context, dir string) error {
+	if c.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		c.watcher = watcher
+		go c.run(ctx)
+	}
+	return c.watcher.Add(dir)
+}
+
+func (c *hostsCache) run(ctx context.Context) {
+	defer c.watcher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			c.invalidate(ctx, ev.Name)
+		case err, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.G(ctx).WithError(err).Warn("host configuration watch error")
+		}
+	}
+}
+
+// invalidate drops cached hosts configured from the directory the event
+// name belongs to. The directory watch is removed as well and re-added on
+// the next lookup, which also handles the directory itself being removed.
+func (c *hostsCache) invalidate(ctx context.Context, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for dir, hosts := range c.dirs {
+		if dir != name && dir != filepath.Dir(name) {
+			continue
+		}
+		log.G(ctx).WithField("dir", dir).Debug("host configuration changed, invalidating cached hosts")
+		for _, host := range hosts {
+			delete(c.hosts, host)
+		}
+		delete(c.dirs, dir)
+		c.watcher.Remove(dir)
+	}
+}
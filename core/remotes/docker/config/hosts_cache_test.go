@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/log/logtest"
+)
+
+func TestHostsCacheInvalidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(logtest.WithT(context.Background(), t))
+	defer cancel()
+	dir := t.TempDir()
+
+	var calls atomic.Int32
+	cache := newHostsCache(func(host string) ([]docker.RegistryHost, error) {
+		calls.Add(1)
+		return []docker.RegistryHost{{Host: host}}, nil
+	}, func(string) (string, error) {
+		return dir, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.get(ctx, "example.registry"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected a single configure call for cached lookups, got %d", n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hosts.toml"), []byte(`server = "https://mirror.registry"`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalidation is asynchronous, poll until the host is reconfigured.
+	for deadline := time.Now().Add(10 * time.Second); calls.Load() == 1; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("cached hosts not invalidated after configuration change")
+		}
+		if _, err := cache.get(ctx, "example.registry"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
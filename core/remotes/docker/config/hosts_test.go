@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/remotes/docker"
 	"github.com/containerd/log/logtest"
@@ -114,6 +115,18 @@ ca = "/etc/path/default"
 
 [host."https://onlyheader.registry".header]
   x-custom-1 = "justaheader"
+
+[host."https://flaky.registry"]
+  capabilities = ["pull"]
+  retries = 3
+  retry_backoff = "500ms"
+  request_timeout = "30s"
+  hedge_delay = "2s"
+
+[host."https://pooled.registry"]
+  capabilities = ["pull"]
+  max_concurrent_downloads = 4
+  max_idle_conns = 8
 `
 	var tb, fb = true, false
 	expected := []hostConfig{
@@ -187,6 +200,26 @@ ca = "/etc/path/default"
 			capabilities: allCaps,
 			header:       http.Header{"x-custom-1": {"justaheader"}},
 		},
+		{
+			scheme:       "https",
+			host:         "flaky.registry",
+			path:         "/v2",
+			capabilities: docker.HostCapabilityPull,
+			policy: docker.RequestPolicy{
+				Retries:        3,
+				RetryBackoff:   500 * time.Millisecond,
+				RequestTimeout: 30 * time.Second,
+				HedgeDelay:     2 * time.Second,
+			},
+		},
+		{
+			scheme:                 "https",
+			host:                   "pooled.registry",
+			path:                   "/v2",
+			capabilities:           docker.HostCapabilityPull,
+			maxConcurrentDownloads: 4,
+			maxIdleConns:           8,
+		},
 		{
 			scheme:       "https",
 			host:         "test-default.registry",
@@ -482,6 +515,25 @@ func TestHTTPFallback(t *testing.T) {
 	}
 }
 
+func TestHeaderProvider(t *testing.T) {
+	ctx := logtest.WithT(context.TODO(), t)
+	hosts := ConfigureHosts(ctx, HostOptions{
+		HeaderProvider: func(host string) (http.Header, error) {
+			return http.Header{"Authorization": {"Bearer token-for-" + host}}, nil
+		},
+	})
+	testHosts, err := hosts("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(testHosts) != 1 {
+		t.Fatalf("expected a single host, got %d hosts", len(testHosts))
+	}
+	if got := testHosts[0].Header.Get("Authorization"); got != "Bearer token-for-example.com" {
+		t.Fatalf("expected provided authorization header, got %q", got)
+	}
+}
+
 func compareRegistryHost(j, k docker.RegistryHost) bool {
 	if j.Scheme != k.Scheme {
 		return false
@@ -554,6 +606,17 @@ func compareHostConfig(j, k hostConfig) bool {
 		}
 	}
 
+	if j.policy != k.policy {
+		return false
+	}
+
+	if j.maxConcurrentDownloads != k.maxConcurrentDownloads {
+		return false
+	}
+	if j.maxIdleConns != k.maxIdleConns {
+		return false
+	}
+
 	return true
 }
 
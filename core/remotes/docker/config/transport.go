@@ -0,0 +1,63 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package config
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// newLimitTransport bounds the number of in-flight requests through the
+// transport. A slot is held from the start of the request until the
+// response body is closed, so it covers the full download rather than just
+// the response headers.
+func newLimitTransport(rt http.RoundTripper, limit int) http.RoundTripper {
+	return &limitTransport{rt: rt, sem: make(chan struct{}, limit)}
+}
+
+type limitTransport struct {
+	rt  http.RoundTripper
+	sem chan struct{}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		<-t.sem
+		return nil, err
+	}
+	resp.Body = &releaseReadCloser{ReadCloser: resp.Body, release: func() { <-t.sem }}
+	return resp, nil
+}
+
+type releaseReadCloser struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/remotes"
@@ -100,57 +101,131 @@ func (r dockerFetcher) Fetch(ctx context.Context, desc ocispec.Descriptor) (io.R
 		if images.IsManifestType(desc.MediaType) || images.IsIndexType(desc.MediaType) ||
 			desc.MediaType == images.MediaTypeDockerSchema1Manifest {
 
-			var firstErr error
-			for _, host := range r.hosts {
+			return r.openHosts(ctx, func(host RegistryHost) (*request, error) {
 				req := r.request(host, http.MethodGet, "manifests", desc.Digest.String())
 				if err := req.addNamespace(r.refspec.Hostname()); err != nil {
 					return nil, err
 				}
-
-				rc, err := r.open(ctx, req, desc.MediaType, offset)
-				if err != nil {
-					// Store the error for referencing later
-					if firstErr == nil {
-						firstErr = err
-					}
-					continue // try another host
-				}
-
-				return rc, nil
-			}
-
-			return nil, firstErr
+				return req, nil
+			}, desc.MediaType, offset)
 		}
 
 		// Finally use blobs endpoints
-		var firstErr error
-		for _, host := range r.hosts {
+		rc, err := r.openHosts(ctx, func(host RegistryHost) (*request, error) {
 			req := r.request(host, http.MethodGet, "blobs", desc.Digest.String())
 			if err := req.addNamespace(r.refspec.Hostname()); err != nil {
 				return nil, err
 			}
+			return req, nil
+		}, desc.MediaType, offset)
+		if err != nil {
+			if errdefs.IsNotFound(err) {
+				err = fmt.Errorf("could not fetch content descriptor %v (%v) from remote: %w",
+					desc.Digest, desc.MediaType, errdefs.ErrNotFound,
+				)
+			}
+			return nil, err
+		}
 
-			rc, err := r.open(ctx, req, desc.MediaType, offset)
-			if err != nil {
-				// Store the error for referencing later
-				if firstErr == nil {
-					firstErr = err
+		return rc, nil
+	})
+}
+
+// openHosts tries the hosts in order, returning the first successfully
+// opened fetch. A host configuring a hedge delay does not get exclusive use
+// of that time: when it has not answered within the delay, the next host is
+// tried concurrently and whichever attempt succeeds first wins. Hosts
+// without a hedge delay are only tried after the previous host has failed.
+func (r dockerFetcher) openHosts(ctx context.Context, newRequest func(host RegistryHost) (*request, error), mediatype string, offset int64) (io.ReadCloser, error) {
+	if len(r.hosts) == 0 {
+		return nil, fmt.Errorf("no pull hosts: %w", errdefs.ErrNotFound)
+	}
+
+	type result struct {
+		rc  io.ReadCloser
+		err error
+	}
+	var (
+		results     = make(chan result, len(r.hosts))
+		outstanding int
+		firstErr    error
+		next        int
+		hedge       *time.Timer
+		hedgeC      <-chan time.Time
+	)
+	defer func() {
+		if hedge != nil {
+			hedge.Stop()
+		}
+	}()
+	// drain closes the bodies of attempts which lost the race.
+	drain := func() {
+		if outstanding == 0 {
+			return
+		}
+		go func(n int) {
+			for i := 0; i < n; i++ {
+				if res := <-results; res.rc != nil {
+					res.rc.Close()
 				}
-				continue // try another host
 			}
-
-			return rc, nil
+		}(outstanding)
+	}
+	start := func() error {
+		host := r.hosts[next]
+		next++
+		req, err := newRequest(host)
+		if err != nil {
+			drain()
+			return err
 		}
-
-		if errdefs.IsNotFound(firstErr) {
-			firstErr = fmt.Errorf("could not fetch content descriptor %v (%v) from remote: %w",
-				desc.Digest, desc.MediaType, errdefs.ErrNotFound,
-			)
+		outstanding++
+		hedgeC = nil
+		if d := host.RequestPolicy.HedgeDelay; d > 0 && next < len(r.hosts) {
+			if hedge != nil {
+				hedge.Stop()
+			}
+			hedge = time.NewTimer(d)
+			hedgeC = hedge.C
 		}
+		go func() {
+			rc, err := r.open(ctx, req, mediatype, offset)
+			results <- result{rc: rc, err: err}
+		}()
+		return nil
+	}
 
-		return nil, firstErr
-
-	})
+	if err := start(); err != nil {
+		return nil, err
+	}
+	for {
+		select {
+		case res := <-results:
+			outstanding--
+			if res.err == nil {
+				drain()
+				return res.rc, nil
+			}
+			// Store the error for referencing later
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if outstanding == 0 {
+				if next == len(r.hosts) {
+					return nil, firstErr
+				}
+				if err := start(); err != nil {
+					return nil, err
+				}
+			}
+		case <-hedgeC:
+			hedgeC = nil
+			log.G(ctx).WithField("host", r.hosts[next].Host).Debug("hedging fetch to next host")
+			if err := start(); err != nil {
+				return nil, err
+			}
+		}
+	}
 }
 
 func (r dockerFetcher) createGetReq(ctx context.Context, host RegistryHost, mediatype string, ps ...string) (*request, int64, error) {
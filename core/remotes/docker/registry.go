@@ -20,6 +20,7 @@ import (
 	"errors"
 	"net"
 	"net/http"
+	"time"
 )
 
 // HostCapabilities represent the capabilities of the registry
@@ -67,13 +68,37 @@ func (c HostCapabilities) Has(t HostCapabilities) bool {
 // host, representing the capabilities, authorizations, connection
 // configuration, and location.
 type RegistryHost struct {
-	Client       *http.Client
-	Authorizer   Authorizer
-	Host         string
-	Scheme       string
-	Path         string
-	Capabilities HostCapabilities
-	Header       http.Header
+	Client        *http.Client
+	Authorizer    Authorizer
+	Host          string
+	Scheme        string
+	Path          string
+	Capabilities  HostCapabilities
+	Header        http.Header
+	RequestPolicy RequestPolicy
+}
+
+// RequestPolicy configures how requests to a registry host are retried and
+// bounded. The zero value applies no timeout and only the retries the
+// resolver always performs, such as following auth challenges and 429s.
+type RequestPolicy struct {
+	// Retries is the number of additional attempts made against the host
+	// after a request fails with a connection error or a 5xx response.
+	Retries int
+
+	// RetryBackoff is the delay before the first retry, doubling on each
+	// subsequent retry. Defaults to 100ms when retries are configured.
+	RetryBackoff time.Duration
+
+	// RequestTimeout bounds a single request attempt, including reading
+	// the response body. Zero applies no timeout.
+	RequestTimeout time.Duration
+
+	// HedgeDelay starts a fetch on the next host in the list when this
+	// host has not answered within the delay, without waiting for it to
+	// fail, and keeps whichever attempt succeeds first. Zero disables
+	// hedging.
+	HedgeDelay time.Duration
 }
 
 func (h RegistryHost) isProxy(refhost string) bool {
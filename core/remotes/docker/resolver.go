@@ -27,6 +27,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/remotes"
@@ -552,6 +553,36 @@ type request struct {
 }
 
 func (r *request) do(ctx context.Context) (*http.Response, error) {
+	if err := throttleFor(r.host.Host).wait(ctx); err != nil {
+		return nil, err
+	}
+	d := r.host.RequestPolicy.RequestTimeout
+	if d == 0 {
+		return r.roundTrip(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	resp, err := r.roundTrip(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout covers reading the body as well, release it on close.
+	resp.Body = &cancelReadCloser{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelReadCloser) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func (r *request) roundTrip(ctx context.Context) (*http.Response, error) {
 	u := r.host.Scheme + "://" + r.host.Host + r.path
 	req, err := http.NewRequestWithContext(ctx, r.method, u, nil)
 	if err != nil {
@@ -607,22 +638,69 @@ func (r *request) do(ctx context.Context) (*http.Response, error) {
 }
 
 func (r *request) doWithRetries(ctx context.Context, responses []*http.Response) (*http.Response, error) {
-	resp, err := r.do(ctx)
-	if err != nil {
-		return nil, err
-	}
+	var attempts int
+	for {
+		resp, err := r.do(ctx)
+		if err != nil {
+			if ctx.Err() == nil && attempts < r.host.RequestPolicy.Retries {
+				attempts++
+				log.G(ctx).WithError(err).Debugf("retrying request, attempt %d", attempts)
+				if werr := r.retryBackoff(ctx, attempts); werr != nil {
+					return nil, werr
+				}
+				continue
+			}
+			return nil, err
+		}
 
-	responses = append(responses, resp)
-	retry, err := r.retryRequest(ctx, responses)
-	if err != nil {
-		resp.Body.Close()
-		return nil, err
+		responses = append(responses, resp)
+		retry, err := r.retryRequest(ctx, responses)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if retry {
+			resp.Body.Close()
+			continue
+		}
+		// Retry 5xx responses when the host policy allows, these are
+		// server side failures a flaky mirror may recover from.
+		if resp.StatusCode >= http.StatusInternalServerError && attempts < r.host.RequestPolicy.Retries {
+			attempts++
+			resp.Body.Close()
+			log.G(ctx).Debugf("retrying request after %q response, attempt %d", resp.Status, attempts)
+			if werr := r.retryBackoff(ctx, attempts); werr != nil {
+				return nil, werr
+			}
+			continue
+		}
+		if resp.StatusCode < http.StatusBadRequest {
+			throttleFor(r.host.Host).succeeded()
+		}
+		return resp, nil
 	}
-	if retry {
-		resp.Body.Close()
-		return r.doWithRetries(ctx, responses)
+}
+
+// defaultRetryBackoff is the delay before the first retry when the host
+// policy configures retries without a backoff.
+const defaultRetryBackoff = 100 * time.Millisecond
+
+// retryBackoff waits before the given retry attempt, doubling the host's
+// configured backoff for each attempt already made.
+func (r *request) retryBackoff(ctx context.Context, attempt int) error {
+	d := r.host.RequestPolicy.RetryBackoff
+	if d <= 0 {
+		d = defaultRetryBackoff
+	}
+	d <<= attempt - 1
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return resp, err
 }
 
 func (r *request) retryRequest(ctx context.Context, responses []*http.Response) (bool, error) {
@@ -649,11 +727,16 @@ func (r *request) retryRequest(ctx context.Context, responses []*http.Response)
 			r.method = http.MethodGet
 			return true, nil
 		}
-	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+	case http.StatusRequestTimeout:
+		return true, nil
+	case http.StatusTooManyRequests:
+		// Record the throttle so the retry below, and any other request to
+		// this host, waits for the registry to recover.
+		throttleFor(r.host.Host).throttled(last)
 		return true, nil
 	}
 
-	// TODO: Handle 50x errors accounting for attempt history
+	// 50x errors are retried by doWithRetries per the host request policy.
 	return false, nil
 }
 
@@ -30,6 +30,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -347,6 +348,46 @@ func TestHostFailureFallbackResolver(t *testing.T) {
 	runBasicTest(t, "testname", sf)
 }
 
+func TestRetryPolicyResolver(t *testing.T) {
+	// The first two requests fail with 503, the host policy retries them.
+	var failures atomic.Int32
+	failures.Store(2)
+
+	sf := func(h http.Handler) (string, ResolverOptions, func()) {
+		wrapped := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if failures.Add(-1) >= 0 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			h.ServeHTTP(rw, r)
+		})
+		s := httptest.NewServer(wrapped)
+		base := s.URL[7:] // strip "http://"
+
+		options := ResolverOptions{}
+		options.Hosts = func(host string) ([]RegistryHost, error) {
+			return []RegistryHost{{
+				Host:         base,
+				Scheme:       "http",
+				Path:         "/v2",
+				Capabilities: HostCapabilityPull | HostCapabilityResolve | HostCapabilityPush,
+				RequestPolicy: RequestPolicy{
+					Retries:      2,
+					RetryBackoff: time.Millisecond,
+				},
+			}}, nil
+		}
+
+		return base, options, s.Close
+	}
+
+	runBasicTest(t, "testname", sf)
+
+	if failures.Load() >= 0 {
+		t.Fatal("expected the failure responses to have been consumed by retries")
+	}
+}
+
 func TestHostTLSFailureFallbackResolver(t *testing.T) {
 	sf := func(h http.Handler) (string, ResolverOptions, func()) {
 		// Start up two servers
@@ -0,0 +1,147 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package docker
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+	metrics "github.com/docker/go-metrics"
+)
+
+const (
+	// throttleInitialBackoff is the backoff applied on the first 429 from a
+	// host without a Retry-After header; it doubles on consecutive throttles.
+	throttleInitialBackoff = 1 * time.Second
+	// throttleMaxBackoff caps the adaptive backoff per host.
+	throttleMaxBackoff = 2 * time.Minute
+	// throttleSpacing is how far apart queued requests to a throttled host
+	// are released, so the backlog does not hit the registry all at once.
+	throttleSpacing = 100 * time.Millisecond
+)
+
+var throttleEvents metrics.LabeledCounter
+
+func init() {
+	ns := metrics.NewNamespace("containerd", "remotes", nil)
+	throttleEvents = ns.NewLabeledCounter("registry_throttle_events", "responses which throttled requests to a registry host", "host")
+	metrics.Register(ns)
+}
+
+var (
+	hostThrottlesMu sync.Mutex
+	hostThrottles   = map[string]*hostThrottle{}
+)
+
+// throttleFor returns the throttle shared by all requests to the given host.
+func throttleFor(host string) *hostThrottle {
+	hostThrottlesMu.Lock()
+	defer hostThrottlesMu.Unlock()
+	t, ok := hostThrottles[host]
+	if !ok {
+		t = &hostThrottle{host: host}
+		hostThrottles[host] = t
+	}
+	return t
+}
+
+// hostThrottle rate limits requests to a registry host which has responded
+// with 429. The backoff adapts: it is taken from Retry-After when the
+// registry provides one, doubles on consecutive throttles otherwise, and
+// decays again as requests succeed. While the host is throttled, new
+// requests queue behind the in-flight ones at a fixed spacing instead of
+// being retried immediately.
+type hostThrottle struct {
+	host string
+
+	mu sync.Mutex
+	// notBefore is the earliest time the next request may be sent.
+	notBefore time.Time
+	// backoff is the delay applied on the next throttled response.
+	backoff time.Duration
+}
+
+// wait blocks until the host accepts another request or the context is done.
+func (t *hostThrottle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	d := time.Until(t.notBefore)
+	if d > 0 {
+		// Claim a slot behind the other queued requests.
+		t.notBefore = t.notBefore.Add(throttleSpacing)
+	}
+	t.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+	log.G(ctx).WithField("host", t.host).Debugf("host is throttled, delaying request %s", d)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttled records a 429 response, honoring its Retry-After header when
+// present.
+func (t *hostThrottle) throttled(resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		t.backoff = d
+	} else if t.backoff *= 2; t.backoff < throttleInitialBackoff {
+		t.backoff = throttleInitialBackoff
+	}
+	if t.backoff > throttleMaxBackoff {
+		t.backoff = throttleMaxBackoff
+	}
+	if nb := time.Now().Add(t.backoff); nb.After(t.notBefore) {
+		t.notBefore = nb
+	}
+	throttleEvents.WithValues(t.host).Inc()
+}
+
+// succeeded decays the backoff after a response which was not throttled.
+func (t *hostThrottle) succeeded() {
+	t.mu.Lock()
+	t.backoff /= 2
+	t.mu.Unlock()
+}
+
+// parseRetryAfter parses a Retry-After header given either as a delay in
+// seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
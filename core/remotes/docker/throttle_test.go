@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package docker
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: "", ok: false},
+		{value: "notanumber", ok: false},
+		{value: "-3", ok: false},
+		{value: "7", expected: 7 * time.Second, ok: true},
+		{value: time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), expected: 0, ok: true},
+	} {
+		d, ok := parseRetryAfter(tc.value)
+		if ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q) ok = %v, expected %v", tc.value, ok, tc.ok)
+			continue
+		}
+		if ok && d != tc.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", tc.value, d, tc.expected)
+		}
+	}
+}
+
+func TestHostThrottleBackoff(t *testing.T) {
+	throttle := &hostThrottle{host: "registry.test"}
+
+	resp := &http.Response{Header: http.Header{}}
+	throttle.throttled(resp)
+	if throttle.backoff != throttleInitialBackoff {
+		t.Fatalf("expected initial backoff %v, got %v", throttleInitialBackoff, throttle.backoff)
+	}
+	throttle.throttled(resp)
+	if throttle.backoff != 2*throttleInitialBackoff {
+		t.Fatalf("expected doubled backoff %v, got %v", 2*throttleInitialBackoff, throttle.backoff)
+	}
+
+	resp.Header.Set("Retry-After", "30")
+	throttle.throttled(resp)
+	if throttle.backoff != 30*time.Second {
+		t.Fatalf("expected Retry-After backoff 30s, got %v", throttle.backoff)
+	}
+
+	throttle.succeeded()
+	if throttle.backoff != 15*time.Second {
+		t.Fatalf("expected decayed backoff 15s, got %v", throttle.backoff)
+	}
+
+	if until := time.Until(throttle.notBefore); until <= 0 || until > 30*time.Second {
+		t.Fatalf("expected notBefore within the next 30s, got %v", until)
+	}
+}
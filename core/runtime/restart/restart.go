@@ -53,6 +53,9 @@ const (
 	CountLabel = "containerd.io/restart.count"
 	// ExplicitlyStoppedLabel sets the restart explicitly stopped label for a container
 	ExplicitlyStoppedLabel = "containerd.io/restart.explicitly-stopped"
+	// LastRestartLabel sets the timestamp of the most recent restart of a container.
+	// It is persisted with the container so restart backoff survives daemon restarts.
+	LastRestartLabel = "containerd.io/restart.last-restart"
 )
 
 // Policy represents the restart policies of a container.
@@ -40,6 +40,7 @@ type shimBinaryConfig struct {
 	address      string
 	ttrpcAddress string
 	schedCore    bool
+	env          []string
 }
 
 func shimBinary(bundle *Bundle, config shimBinaryConfig) *binary {
@@ -49,6 +50,7 @@ func shimBinary(bundle *Bundle, config shimBinaryConfig) *binary {
 		containerdAddress:      config.address,
 		containerdTTRPCAddress: config.ttrpcAddress,
 		schedCore:              config.schedCore,
+		env:                    config.env,
 	}
 }
 
@@ -57,6 +59,7 @@ type binary struct {
 	containerdAddress      string
 	containerdTTRPCAddress string
 	schedCore              bool
+	env                    []string
 	bundle                 *Bundle
 }
 
@@ -78,6 +81,7 @@ func (b *binary) Start(ctx context.Context, opts *types.Any, onClose func()) (_
 			Opts:         opts,
 			Args:         args,
 			SchedCore:    b.schedCore,
+			Env:          b.env,
 		})
 	if err != nil {
 		return nil, err
@@ -104,7 +108,9 @@ func (b *binary) Start(ctx context.Context, opts *types.Any, onClose func()) (_
 	// copy the shim's logs to containerd's output
 	go func() {
 		defer f.Close()
-		_, err := io.Copy(os.Stderr, f)
+		w := shimLogWriter(b.bundle.Path)
+		defer w.Close()
+		_, err := io.Copy(w, f)
 		// To prevent flood of error messages, the expected error
 		// should be reset, like os.ErrClosed or os.ErrNotExist, which
 		// depends on platform.
@@ -19,6 +19,7 @@ package v2
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -45,6 +46,7 @@ import (
 	"github.com/containerd/platforms"
 	"github.com/containerd/plugin"
 	"github.com/containerd/plugin/registry"
+	"github.com/containerd/typeurl/v2"
 )
 
 // Config for the v2 runtime
@@ -53,6 +55,8 @@ type Config struct {
 	Platforms []string `toml:"platforms"`
 	// SchedCore enabled linux core scheduling
 	SchedCore bool `toml:"sched_core"`
+	// ShimLogs configures retention and compression of persisted shim logs.
+	ShimLogs ShimLogConfig `toml:"shim_logs"`
 }
 
 func init() {
@@ -75,6 +79,8 @@ func init() {
 
 			ic.Meta.Platforms = supportedPlatforms
 
+			SetShimLogConfig(config.ShimLogs)
+
 			m, err := ic.GetSingle(plugins.MetadataPlugin)
 			if err != nil {
 				return nil, err
@@ -264,11 +270,17 @@ func (m *ShimManager) startShim(ctx context.Context, bundle *Bundle, id string,
 		return nil, fmt.Errorf("failed to resolve runtime path: %w", err)
 	}
 
+	env, err := shimEnvFromSpec(opts.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shim environment from spec: %w", err)
+	}
+
 	b := shimBinary(bundle, shimBinaryConfig{
 		runtime:      runtimePath,
 		address:      m.containerdAddress,
 		ttrpcAddress: m.containerdTTRPCAddress,
 		schedCore:    m.schedCore,
+		env:          env,
 	})
 	shim, err := b.Start(ctx, protobuf.FromAny(topts), func() {
 		log.G(ctx).WithField("id", id).Info("shim disconnected")
@@ -320,6 +332,29 @@ func restoreBootstrapParams(bundlePath string) (shimbinary.BootstrapParams, erro
 	return params, nil
 }
 
+// shimEnvFromSpec extracts additional shim environment variables requested via
+// the `io.containerd.runtime.v2.shim-env` annotation on the container's OCI spec.
+func shimEnvFromSpec(spec typeurl.Any) ([]string, error) {
+	if spec == nil || spec.GetValue() == nil {
+		return nil, nil
+	}
+	var s struct {
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+	if err := json.Unmarshal(spec.GetValue(), &s); err != nil {
+		return nil, err
+	}
+	v, ok := s.Annotations[shimbinary.EnvAnnotation]
+	if !ok || v == "" {
+		return nil, nil
+	}
+	var env []string
+	if err := json.Unmarshal([]byte(v), &env); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", shimbinary.EnvAnnotation, err)
+	}
+	return env, nil
+}
+
 func (m *ShimManager) resolveRuntimePath(runtime string) (string, error) {
 	if runtime == "" {
 		return "", fmt.Errorf("no runtime name")
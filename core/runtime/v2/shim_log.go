@@ -0,0 +1,375 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+	metrics "github.com/docker/go-metrics"
+)
+
+// ShimLogFileName is the name of the persisted copy of a shim's log within
+// its bundle directory.
+const ShimLogFileName = "shim.log"
+
+// ShimLogFile returns the path of the persisted copy of a shim's log for the
+// given bundle path.
+func ShimLogFile(bundlePath string) string {
+	return filepath.Join(bundlePath, ShimLogFileName)
+}
+
+// ShimLogConfig configures retention and compression of the persisted shim
+// logs.
+type ShimLogConfig struct {
+	// MaxSize is the size in bytes at which a persisted shim log is rotated.
+	// A non-positive value disables rotation.
+	MaxSize int64 `toml:"max_size"`
+	// MaxBackups is the number of rotated shim logs to retain per shim. A
+	// non-positive value retains all rotated logs.
+	MaxBackups int `toml:"max_backups"`
+	// Compress enables asynchronous gzip compression of rotated shim logs.
+	Compress bool `toml:"compress"`
+	// NonBlocking decouples shims from persisted log writes: entries are
+	// flushed by a background goroutine and kept in a bounded in-memory
+	// buffer while the disk is full or writes fail, so a shim never stalls
+	// on its log. The oldest buffered entries are dropped and counted when
+	// the buffer overflows.
+	NonBlocking bool `toml:"non_blocking"`
+	// BufferEntries is the number of log entries buffered in memory in
+	// non-blocking mode. A non-positive value uses the default of 1024.
+	BufferEntries int `toml:"buffer_entries"`
+}
+
+// shimLogConfig holds the active shim log policy. It is set once from the
+// task plugin configuration before any shim is started.
+var shimLogConfig ShimLogConfig
+
+// SetShimLogConfig configures the retention and compression policy applied to
+// persisted shim logs.
+func SetShimLogConfig(config ShimLogConfig) {
+	shimLogConfig = config
+}
+
+// shimLogWriter returns a writer which forwards shim logs to containerd's
+// stderr and persists a copy to the bundle directory so they can be queried
+// later. When the persisted copy cannot be opened, logs are still forwarded
+// to stderr.
+func shimLogWriter(bundlePath string) io.WriteCloser {
+	l := &logWriter{bundlePath: bundlePath, cfg: shimLogConfig}
+	if !l.open() {
+		return &logWriter{w: os.Stderr}
+	}
+	if shimLogConfig.NonBlocking {
+		return newNonBlockingShimLog(l, shimLogConfig.BufferEntries)
+	}
+	return l
+}
+
+type logWriter struct {
+	w          io.Writer
+	f          *os.File
+	bundlePath string
+	cfg        ShimLogConfig
+	size       int64
+}
+
+func (l *logWriter) open() bool {
+	f, err := os.OpenFile(ShimLogFile(l.bundlePath), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return false
+	}
+	size := int64(0)
+	if fi, err := f.Stat(); err == nil {
+		size = fi.Size()
+	}
+	l.w = io.MultiWriter(os.Stderr, f)
+	l.f = f
+	l.size = size
+	return true
+}
+
+func (l *logWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if l.f != nil && l.cfg.MaxSize > 0 {
+		l.size += int64(n)
+		if l.size >= l.cfg.MaxSize {
+			l.rotate()
+		}
+	}
+	return n, err
+}
+
+// rotate moves the current shim log aside and starts a fresh one. Compression
+// and pruning of the rotated logs happen asynchronously so the shim's output
+// is never blocked on them.
+func (l *logWriter) rotate() {
+	l.f.Close()
+	current := ShimLogFile(l.bundlePath)
+	rotated := fmt.Sprintf("%s.%d", current, time.Now().UnixNano())
+	if err := os.Rename(current, rotated); err != nil {
+		rotated = ""
+	}
+	if !l.open() {
+		l.w = os.Stderr
+		l.f = nil
+	}
+	cfg := l.cfg
+	bundlePath := l.bundlePath
+	go func() {
+		if rotated != "" && cfg.Compress {
+			compressShimLog(rotated)
+		}
+		pruneShimLogs(bundlePath, cfg.MaxBackups)
+	}()
+}
+
+func (l *logWriter) Close() error {
+	if l.f == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+const (
+	// defaultShimLogBufferEntries is the in-memory buffer capacity of a
+	// non-blocking shim log.
+	defaultShimLogBufferEntries = 1024
+	// shimLogRetryDelay is how long flushing pauses after a failed write,
+	// e.g. while the disk is full.
+	shimLogRetryDelay = time.Second
+	// shimLogFailureLogInterval rate limits logging of shim log write
+	// failures.
+	shimLogFailureLogInterval = 30 * time.Second
+)
+
+var (
+	shimLogWriteFailures  metrics.Counter
+	shimLogDroppedEntries metrics.Counter
+)
+
+func init() {
+	ns := metrics.NewNamespace("containerd", "shim_logs", nil)
+
+	shimLogWriteFailures = ns.NewCounter("write_failures", "cumulative number of failed writes to persisted shim logs")
+	shimLogDroppedEntries = ns.NewCounter("dropped_entries", "cumulative number of shim log entries dropped because the in-memory buffer was full")
+
+	metrics.Register(ns)
+}
+
+// nonBlockingShimLog keeps shims decoupled from their persisted log: Write
+// only appends to a bounded in-memory buffer, which a background goroutine
+// flushes to the underlying writer. While writes fail the entries stay in
+// memory and flushing is retried, and when the buffer overflows the oldest
+// entries are dropped and counted, so a full log disk can never stall a shim.
+type nonBlockingShimLog struct {
+	inner *logWriter
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   [][]byte
+	max     int
+	closed  bool
+	done    chan struct{}
+	lastLog time.Time
+}
+
+func newNonBlockingShimLog(inner *logWriter, bufferEntries int) *nonBlockingShimLog {
+	if bufferEntries <= 0 {
+		bufferEntries = defaultShimLogBufferEntries
+	}
+	n := &nonBlockingShimLog{
+		inner: inner,
+		max:   bufferEntries,
+		done:  make(chan struct{}),
+	}
+	n.cond = sync.NewCond(&n.mu)
+	go n.run()
+	return n
+}
+
+func (n *nonBlockingShimLog) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	for len(n.queue) >= n.max {
+		n.queue = n.queue[1:]
+		shimLogDroppedEntries.Inc()
+	}
+	n.queue = append(n.queue, b)
+	n.cond.Signal()
+	n.mu.Unlock()
+	return len(p), nil
+}
+
+func (n *nonBlockingShimLog) run() {
+	defer close(n.done)
+	for {
+		n.mu.Lock()
+		for len(n.queue) == 0 && !n.closed {
+			n.cond.Wait()
+		}
+		if len(n.queue) == 0 {
+			n.mu.Unlock()
+			return
+		}
+		b := n.queue[0]
+		n.queue = n.queue[1:]
+		closed := n.closed
+		n.mu.Unlock()
+
+		if _, err := n.inner.Write(b); err != nil {
+			shimLogWriteFailures.Inc()
+			n.logFailure(err)
+			if closed {
+				// Best effort only while draining on close.
+				continue
+			}
+			// Keep the entry in memory and retry once the disk may have
+			// recovered.
+			n.mu.Lock()
+			if len(n.queue) < n.max {
+				n.queue = append([][]byte{b}, n.queue...)
+			} else {
+				shimLogDroppedEntries.Inc()
+			}
+			n.mu.Unlock()
+			time.Sleep(shimLogRetryDelay)
+		}
+	}
+}
+
+// logFailure logs shim log write failures at most once per
+// shimLogFailureLogInterval.
+func (n *nonBlockingShimLog) logFailure(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if time.Since(n.lastLog) < shimLogFailureLogInterval {
+		return
+	}
+	n.lastLog = time.Now()
+	log.L.WithError(err).WithField("path", ShimLogFile(n.inner.bundlePath)).Warn("failed to write shim log")
+}
+
+// Close drains the buffered entries best effort and closes the underlying
+// log.
+func (n *nonBlockingShimLog) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		<-n.done
+		return nil
+	}
+	n.closed = true
+	n.cond.Signal()
+	n.mu.Unlock()
+	<-n.done
+	return n.inner.Close()
+}
+
+// compressShimLog gzips a rotated shim log in place, removing the
+// uncompressed file on success.
+func compressShimLog(path string) {
+	in, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return
+	}
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	if cerr := gz.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return
+	}
+	os.Remove(path)
+}
+
+// pruneShimLogs removes the oldest rotated shim logs of a bundle so at most
+// maxBackups of them are retained.
+func pruneShimLogs(bundlePath string, maxBackups int) {
+	if maxBackups <= 0 {
+		return
+	}
+	backups, err := filepath.Glob(ShimLogFile(bundlePath) + ".*")
+	if err != nil || len(backups) <= maxBackups {
+		return
+	}
+	// Rotated logs are suffixed with a timestamp, so the lexical order is
+	// also the chronological one.
+	sort.Strings(backups)
+	for _, b := range backups[:len(backups)-maxBackups] {
+		os.Remove(b)
+	}
+}
+
+// logTimeRegexp extracts the timestamp of a logrus formatted log line.
+var logTimeRegexp = regexp.MustCompile(`time="([^"]+)"`)
+
+// QueryShimLogs copies the persisted log entries of a shim whose timestamps
+// fall within [since, until] to w. A zero time disables the respective bound.
+// Lines without a parsable timestamp (e.g. raw output of the runtime) inherit
+// the verdict of the preceding line.
+func QueryShimLogs(bundlePath string, since, until time.Time, w io.Writer) error {
+	f, err := os.Open(ShimLogFile(bundlePath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	include := true
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if m := logTimeRegexp.FindSubmatch(line); m != nil {
+			if t, err := time.Parse(time.RFC3339, string(m[1])); err == nil {
+				include = (since.IsZero() || !t.Before(since)) && (until.IsZero() || !t.After(until))
+			}
+		}
+		if !include {
+			continue
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNonBlockingShimLogFlushesOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	l := newNonBlockingShimLog(&logWriter{w: &buf}, 8)
+	for i := 0; i < 4; i++ {
+		_, err := fmt.Fprintf(l, "line%d\n", i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, l.Close())
+	assert.Equal(t, "line0\nline1\nline2\nline3\n", buf.String())
+}
+
+func TestNonBlockingShimLogDropsOldestOnOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	l := &nonBlockingShimLog{
+		inner: &logWriter{w: &buf},
+		max:   2,
+		done:  make(chan struct{}),
+	}
+	l.cond = &sync.Cond{L: &l.mu}
+	// Queue without a running flusher so the buffer overflows.
+	for i := 0; i < 4; i++ {
+		_, err := fmt.Fprintf(l, "line%d\n", i)
+		require.NoError(t, err)
+	}
+	go l.run()
+	require.NoError(t, l.Close())
+	assert.Equal(t, "line2\nline3\n", buf.String())
+}
+
+// failOnceWriter fails its first write and succeeds afterwards, simulating a
+// disk recovering from being full.
+type failOnceWriter struct {
+	bytes.Buffer
+	failed chan struct{}
+}
+
+func (w *failOnceWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.failed:
+	default:
+		close(w.failed)
+		return 0, errors.New("no space left on device")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestNonBlockingShimLogRetainsEntriesAcrossWriteFailure(t *testing.T) {
+	w := &failOnceWriter{failed: make(chan struct{})}
+	l := newNonBlockingShimLog(&logWriter{w: w}, 8)
+	_, err := fmt.Fprintf(l, "line0\n")
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(l, "line1\n")
+	require.NoError(t, err)
+	// Wait for the failed attempt, so the entry is kept in memory before the
+	// close-time drain starts.
+	<-w.failed
+	require.NoError(t, l.Close())
+	assert.Equal(t, "line0\nline1\n", w.Buffer.String())
+}
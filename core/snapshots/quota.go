@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package snapshots
+
+import (
+	"context"
+	"sync"
+)
+
+// LabelWritableLayerPath is an optional label on active snapshots pointing at
+// the directory backing the writable layer. Snapshotters with a quota driver
+// configured attach it so quota operations can be issued through a
+// WritableLayerQuota without knowledge of the snapshotter's on-disk layout.
+const LabelWritableLayerPath = "containerd.io/snapshot/writable-layer.path"
+
+// WritableLayerQuota constrains the disk consumed by the writable layer of
+// active snapshots. Implementations operate on the directory backing the
+// layer (see LabelWritableLayerPath) so callers such as the CRI layer can
+// drive quota handling for any snapshotter through one interface.
+type WritableLayerQuota interface {
+	// Setup applies a size limit to the still empty writable layer
+	// directory of an active snapshot before it is first used.
+	Setup(ctx context.Context, dir string, size int64) error
+	// Resize changes the size limit of an already constrained directory.
+	Resize(ctx context.Context, dir string, size int64) error
+	// Usage reports the current consumption of a constrained directory.
+	Usage(ctx context.Context, dir string) (Usage, error)
+	// Remove releases the quota of a directory before it is deleted.
+	Remove(ctx context.Context, dir string) error
+}
+
+var (
+	quotasMu sync.RWMutex
+	quotas   = map[string]WritableLayerQuota{}
+)
+
+// RegisterWritableLayerQuota makes q the quota handler of the named
+// snapshotter. It is typically called by a snapshotter plugin when a quota
+// driver is configured.
+func RegisterWritableLayerQuota(snapshotter string, q WritableLayerQuota) {
+	quotasMu.Lock()
+	quotas[snapshotter] = q
+	quotasMu.Unlock()
+}
+
+// WritableLayerQuotaFor returns the quota handler registered for the named
+// snapshotter, or nil when the snapshotter has none configured.
+func WritableLayerQuotaFor(snapshotter string) WritableLayerQuota {
+	quotasMu.RLock()
+	defer quotasMu.RUnlock()
+	return quotas[snapshotter]
+}
@@ -63,6 +63,7 @@ type unpackerConfig struct {
 	content content.Store
 
 	limiter               *semaphore.Weighted
+	applyLimiter          *semaphore.Weighted
 	duplicationSuppressor kmutex.KeyedLocker
 }
 
@@ -77,6 +78,15 @@ type Platform struct {
 
 	Applier   diff.Applier
 	ApplyOpts []diff.ApplyOpt
+
+	// Comparer is used to recompute the digest of applied layer content when
+	// VerifyDiffID is set. Required when VerifyDiffID is set.
+	Comparer diff.Comparer
+	// VerifyDiffID enables recomputing the diffID of the extracted snapshot
+	// content and comparing it with the expected diffID from the image config
+	// before the snapshot is committed, catching silent corruption in the
+	// apply path at the cost of an extra walk of the layer.
+	VerifyDiffID bool
 }
 
 type UnpackerOpt func(*unpackerConfig) error
@@ -99,6 +109,9 @@ func WithUnpackPlatform(u Platform) UnpackerOpt {
 		if u.Applier == nil {
 			return fmt.Errorf("applier must be provided to unpack")
 		}
+		if u.VerifyDiffID && u.Comparer == nil {
+			return fmt.Errorf("comparer must be provided to verify diff ids on unpack")
+		}
 
 		c.platforms = append(c.platforms, &u)
 
@@ -113,6 +126,17 @@ func WithLimiter(l *semaphore.Weighted) UnpackerOpt {
 	})
 }
 
+// WithApplyLimiter sets a semaphore bounding the layer applications running
+// at once. Applies within one image stay ordered by the layer chain; the
+// limiter caps applies across concurrently unpacked images. It may be shared
+// between unpackers to enforce a global limit.
+func WithApplyLimiter(l *semaphore.Weighted) UnpackerOpt {
+	return UnpackerOpt(func(c *unpackerConfig) error {
+		c.applyLimiter = l
+		return nil
+	})
+}
+
 func WithDuplicationSuppressor(d kmutex.KeyedLocker) UnpackerOpt {
 	return UnpackerOpt(func(c *unpackerConfig) error {
 		c.duplicationSuppressor = d
@@ -378,7 +402,12 @@ func (u *Unpacker) unpack(
 		case <-fetchC[i-fetchOffset]:
 		}
 
+		if err := u.acquireApply(ctx); err != nil {
+			cleanup.Do(ctx, abort)
+			return err
+		}
 		diff, err := a.Apply(ctx, desc, mounts, unpack.ApplyOpts...)
+		u.releaseApply()
 		if err != nil {
 			cleanup.Do(ctx, abort)
 			return fmt.Errorf("failed to extract layer %s: %w", diffIDs[i], err)
@@ -388,6 +417,13 @@ func (u *Unpacker) unpack(
 			return fmt.Errorf("wrong diff id calculated on extraction %q", diffIDs[i])
 		}
 
+		if unpack.VerifyDiffID {
+			if err := u.verifyDiffID(ctx, unpack, parent.String(), mounts, diffIDs[i]); err != nil {
+				cleanup.Do(ctx, abort)
+				return fmt.Errorf("failed to verify applied layer %q: %w", diffIDs[i], err)
+			}
+		}
+
 		if err = sn.Commit(ctx, chainID, key, opts...); err != nil {
 			cleanup.Do(ctx, abort)
 			if errdefs.IsAlreadyExists(err) {
@@ -450,6 +486,42 @@ func (u *Unpacker) unpack(
 	return nil
 }
 
+// verifyDiffID recomputes the uncompressed digest of an extracted snapshot by
+// diffing it against a view of its parent and compares the result with the
+// expected diffID. The temporary diff blob is deleted again after the
+// comparison.
+func (u *Unpacker) verifyDiffID(ctx context.Context, p *Platform, parent string, upper []mount.Mount, expected digest.Digest) error {
+	var lower []mount.Mount
+	if parent != "" {
+		viewKey := fmt.Sprintf(snapshots.UnpackKeyFormat, uniquePart(), "verify-"+parent)
+		m, err := p.Snapshotter.View(ctx, viewKey, parent)
+		if err != nil {
+			return fmt.Errorf("failed to view parent snapshot %s: %w", parent, err)
+		}
+		defer func() {
+			if err := p.Snapshotter.Remove(ctx, viewKey); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove verification view %q", viewKey)
+			}
+		}()
+		lower = m
+	}
+	desc, err := p.Comparer.Compare(ctx, lower, upper,
+		diff.WithMediaType(ocispec.MediaTypeImageLayer),
+		diff.WithReference("verify-"+expected.String()))
+	if err != nil {
+		return fmt.Errorf("failed to compute diff of applied layer: %w", err)
+	}
+	defer func() {
+		if err := u.content.Delete(ctx, desc.Digest); err != nil && !errdefs.IsNotFound(err) {
+			log.G(ctx).WithError(err).Warnf("failed to delete verification diff %q", desc.Digest)
+		}
+	}()
+	if desc.Digest != expected {
+		return fmt.Errorf("diff id %q computed on commit does not match expected %q", desc.Digest, expected)
+	}
+	return nil
+}
+
 func (u *Unpacker) fetch(ctx context.Context, h images.Handler, layers []ocispec.Descriptor, done []chan struct{}) error {
 	eg, ctx2 := errgroup.WithContext(ctx)
 	for i, desc := range layers {
@@ -504,6 +576,20 @@ func (u *Unpacker) release() {
 	u.limiter.Release(1)
 }
 
+func (u *Unpacker) acquireApply(ctx context.Context) error {
+	if u.applyLimiter == nil {
+		return nil
+	}
+	return u.applyLimiter.Acquire(ctx, 1)
+}
+
+func (u *Unpacker) releaseApply() {
+	if u.applyLimiter == nil {
+		return
+	}
+	u.applyLimiter.Release(1)
+}
+
 func (u *Unpacker) lockSnChainID(ctx context.Context, chainID, snapshotter string) (func(), error) {
 	key := u.makeChainIDKeyWithSnapshotter(chainID, snapshotter)
 
@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package cputune sizes the daemon's parallelism to the CPU actually
+// available to it. When containerd runs in a cgroup slice with a CPU quota
+// well below the machine size, the Go runtime still defaults GOMAXPROCS to
+// the machine's CPU count, oversubscribing the quota. This package detects
+// the daemon's own quota, tunes GOMAXPROCS accordingly and exposes the
+// effective CPU count for sizing worker pools.
+package cputune
+
+import (
+	"os"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/containerd/log"
+)
+
+// effective is the CPU count parallelism should be sized to.
+var effective atomic.Int32
+
+func init() {
+	effective.Store(int32(runtime.GOMAXPROCS(0)))
+}
+
+// Apply tunes GOMAXPROCS to the daemon's cgroup CPU quota, or to maxProcs
+// when positive. It is a no-op when GOMAXPROCS is set through the
+// environment, the override is zero and no quota below the machine size is
+// found, or the platform has no CPU quotas.
+func Apply(maxProcs int) {
+	procs := maxProcs
+	if procs <= 0 {
+		if os.Getenv("GOMAXPROCS") != "" {
+			return
+		}
+		quota, ok := quotaCPUs()
+		if !ok || quota >= runtime.NumCPU() {
+			return
+		}
+		procs = quota
+	}
+	if procs < 1 {
+		procs = 1
+	}
+	if procs != runtime.GOMAXPROCS(0) {
+		log.L.Infof("Setting GOMAXPROCS to %d", procs)
+		runtime.GOMAXPROCS(procs)
+	}
+	effective.Store(int32(procs))
+}
+
+// Effective returns the CPU count the daemon is tuned to, which is the
+// applied quota or override, and GOMAXPROCS otherwise.
+func Effective() int {
+	return int(effective.Load())
+}
+
+// Workers returns the size of a worker pool: the configured value when
+// positive, and the effective CPU count otherwise, always at least one.
+func Workers(configured int) int {
+	if configured > 0 {
+		return configured
+	}
+	if n := Effective(); n > 1 {
+		return n
+	}
+	return 1
+}
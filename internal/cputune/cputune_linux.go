@@ -0,0 +1,125 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cputune
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups/v3"
+)
+
+// quotaCPUs returns the CPU count equivalent of the daemon's own cgroup CPU
+// quota, rounded up. It reports false when the daemon is not limited or the
+// quota cannot be determined.
+func quotaCPUs() (int, bool) {
+	quota, period, ok := readCPUQuota("/proc/self/cgroup", "/sys/fs/cgroup")
+	if !ok || quota <= 0 || period <= 0 {
+		return 0, false
+	}
+	cpus := int((quota + period - 1) / period)
+	if cpus < 1 {
+		cpus = 1
+	}
+	return cpus, true
+}
+
+// readCPUQuota reads the quota and period of the cgroup the process given by
+// cgroupFile is in, walking up the hierarchy to the tightest limit on cgroup
+// v2. A quota of zero means unlimited.
+func readCPUQuota(cgroupFile, mountpoint string) (quota, period int64, ok bool) {
+	f, err := os.Open(cgroupFile)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	var unifiedPath, v1Path string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Entries are of the form "id:controllers:path".
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		switch {
+		case parts[0] == "0" && parts[1] == "":
+			unifiedPath = parts[2]
+		case strings.Contains(","+parts[1]+",", ",cpu,"):
+			v1Path = parts[2]
+		}
+	}
+
+	if cgroups.Mode() == cgroups.Unified {
+		if unifiedPath == "" {
+			return 0, 0, false
+		}
+		return readCPUMax(mountpoint, unifiedPath)
+	}
+	if v1Path == "" {
+		return 0, 0, false
+	}
+	dir := filepath.Join(mountpoint, "cpu", v1Path)
+	quota = readInt(filepath.Join(dir, "cpu.cfs_quota_us"))
+	period = readInt(filepath.Join(dir, "cpu.cfs_period_us"))
+	if quota <= 0 || period <= 0 {
+		return 0, 0, false
+	}
+	return quota, period, true
+}
+
+// readCPUMax walks the cgroup v2 hierarchy from the process's cgroup to the
+// root, returning the tightest cpu.max limit.
+func readCPUMax(mountpoint, path string) (quota, period int64, ok bool) {
+	for {
+		data, err := os.ReadFile(filepath.Join(mountpoint, path, "cpu.max"))
+		if err == nil {
+			fields := strings.Fields(string(data))
+			if len(fields) == 2 && fields[0] != "max" {
+				q, qerr := strconv.ParseInt(fields[0], 10, 64)
+				p, perr := strconv.ParseInt(fields[1], 10, 64)
+				if qerr == nil && perr == nil && q > 0 && p > 0 {
+					// Limits tighten towards the leaves, the first one found
+					// walking up is not necessarily the tightest, so keep the
+					// smallest ratio.
+					if !ok || q*period < quota*p {
+						quota, period, ok = q, p, true
+					}
+				}
+			}
+		}
+		if path == "/" || path == "" {
+			return quota, period, ok
+		}
+		path = filepath.Dir(path)
+	}
+}
+
+func readInt(path string) int64 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -93,6 +93,92 @@ const (
 
 	// WindowsHostProcess is used by hcsshim to identify windows pods that are running HostProcesses
 	WindowsHostProcess = "microsoft.com/hostprocess-container"
+
+	// SandboxShmSize is an optional pod annotation overriding the default 64MB
+	// size of the sandbox /dev/shm tmpfs. The value is parsed as a RAM size in
+	// bytes and accepts human readable suffixes (e.g. "256m", "1g").
+	SandboxShmSize = "io.kubernetes.cri.sandbox-shm-size"
+
+	// Ulimits is an optional pod annotation overriding the runtime's default
+	// rlimits for the pod's containers. The value is a comma separated list of
+	// "type=soft:hard" entries using the short resource names, e.g.
+	// "nofile=4096:8192,memlock=65536:65536". Overrides are only honored up to
+	// the runtime's configured `max_ulimits`.
+	Ulimits = "io.containerd.cri.ulimits"
+
+	// StartAfter is an optional container annotation listing the names of
+	// containers in the same pod which must be running before this container
+	// is started, e.g. "envoy" or "envoy,vault-agent". It provides
+	// sidecar-first start ordering for clusters whose Kubernetes version has
+	// no native sidecar support.
+	StartAfter = "io.containerd.cri.start-after"
+
+	// StartReadinessFile is an optional container annotation naming a file
+	// whose existence is awaited before the container is started, for
+	// sidecars which signal readiness by touching a file on a shared volume.
+	StartReadinessFile = "io.containerd.cri.start-readiness-file"
+
+	// StartWaitTimeout is an optional container annotation bounding how long
+	// StartContainer waits for "io.containerd.cri.start-after" and
+	// "io.containerd.cri.start-readiness-file" conditions. The value is in
+	// the golang duration format and defaults to 30s.
+	StartWaitTimeout = "io.containerd.cri.start-wait-timeout"
+
+	// UnpackSnapshotters is an optional pod annotation listing extra
+	// snapshotters, comma separated, to unpack pulled images into in the
+	// same pull, overriding the configured unpack_snapshotters list.
+	UnpackSnapshotters = "io.containerd.cri.unpack-snapshotters"
+
+	// RestoreCheckpoint is an optional container annotation holding the
+	// path of a checkpoint archive written by CheckpointContainer. When
+	// present, the container is restored from the archive on start rather
+	// than run fresh. Only supported with the runc runtime.
+	RestoreCheckpoint = "io.containerd.cri.restore-checkpoint"
+
+	// AdditionalNetworks is an optional pod annotation listing CNI network
+	// names, comma separated, to attach to the sandbox in addition to the
+	// default network. The named configurations must exist in the CNI conf
+	// dir of the sandbox's runtime class.
+	AdditionalNetworks = "io.containerd.cri.additional-networks"
+
+	// MountSubPaths is an optional container annotation mounting only a
+	// subdirectory of a volume into the container. The value is a comma
+	// separated list of "containerPath=subPath" entries, e.g.
+	// "/etc/config=prod". Each subPath is resolved under the mount's host
+	// path with symlink traversal confined to it, so a symlink inside the
+	// volume cannot redirect the mount onto an arbitrary host path.
+	MountSubPaths = "io.containerd.cri.mount-subpaths"
+
+	// MountPropagations is an optional container annotation overriding the
+	// propagation of individual mounts. The value is a comma separated list
+	// of "containerPath=policy" entries with policy one of "rprivate",
+	// "rslave" or "rshared", e.g. "/var/lib/kubelet=rslave".
+	MountPropagations = "io.containerd.cri.mount-propagations"
+
+	// MountRecursiveReadOnly is an optional container annotation making
+	// individual read-only mounts recursively read-only, listing their
+	// container paths comma separated. It requires a runtime handler and
+	// kernel with recursive read-only mount support.
+	MountRecursiveReadOnly = "io.containerd.cri.mount-rro"
+
+	// NUMAPin is an optional pod annotation requesting that the pod's
+	// containers are constrained to the CPUs and memory of a single NUMA
+	// node. The node is chosen by the runtime's configured "numa_policy" and
+	// the value must be "true". Mainly useful for VM-based runtimes, where
+	// crossing NUMA nodes costs guest memory locality.
+	NUMAPin = "io.containerd.cri.numa-pin"
+
+	// ExclusiveCPUCount is an optional container annotation requesting the
+	// given number of exclusive CPUs from the node's exclusive cpuset pool,
+	// overriding detection from the container's CPU resources.
+	ExclusiveCPUCount = "io.containerd.cri.exclusive-cpu-count"
+
+	// SandboxDevShm is an optional pod annotation selecting where the sandbox
+	// /dev/shm comes from. Supported values are "pod" (a pod private tmpfs, the
+	// default) and "node" (bind the host /dev/shm while keeping the pod IPC
+	// namespace), which allows shared memory IPC with processes outside of the
+	// pod.
+	SandboxDevShm = "io.kubernetes.cri.sandbox-dev-shm"
 )
 
 // DefaultCRIAnnotations are the default set of CRI annotations to
@@ -21,9 +21,14 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/containerd/log"
+	"github.com/containerd/platforms"
 	"github.com/pelletier/go-toml/v2"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/kubelet/pkg/cri/streaming"
@@ -73,6 +78,14 @@ const (
 	DefaultSandboxImage = "registry.k8s.io/pause:3.9"
 )
 
+// Ulimit is a process resource limit (rlimit) entry of a runtime. Type uses
+// the short resource names, e.g. "nofile", "nproc" or "memlock".
+type Ulimit struct {
+	Type string `toml:"type" json:"type"`
+	Hard uint64 `toml:"hard" json:"hard"`
+	Soft uint64 `toml:"soft" json:"soft"`
+}
+
 // Runtime struct to contain the type(ID), engine, and root variables for a default runtime
 // and a runtime for untrusted workload.
 type Runtime struct {
@@ -82,6 +95,25 @@ type Runtime struct {
 	// When specified, containerd will ignore runtime name field when resolving shim location.
 	// Path must be abs.
 	Path string `toml:"runtime_path" json:"runtimePath"`
+	// ShimEnv is a list of additional `KEY=VALUE` environment variables passed to the
+	// shim process on bootstrap. Entries are validated at config load.
+	ShimEnv []string `toml:"shim_env" json:"shimEnv"`
+	// CoreDumpPath is the base directory core dumps of containers using this runtime
+	// are redirected to by the containerd-coredump-helper. When set, a per-pod
+	// directory is created below it at container creation and the dumps are removed
+	// together with their container or pod. Empty disables core dump management.
+	CoreDumpPath string `toml:"core_dump_path" json:"coreDumpPath"`
+	// NUMAPolicy selects how pods of this runtime which request NUMA pinning
+	// via the "io.containerd.cri.numa-pin" pod annotation are placed on the
+	// node's NUMA topology. Supported values are "packed" (fill one node
+	// before using the next) and "spread" (balance pods across nodes). Empty
+	// disables NUMA pinning for this runtime.
+	NUMAPolicy string `toml:"numa_policy" json:"numaPolicy"`
+	// SnapshotMaxRetries is the number of times a transient snapshot
+	// preparation error (unavailable) is retried with exponential
+	// backoff while creating a container, before the error is returned to the
+	// kubelet. Zero disables retrying.
+	SnapshotMaxRetries int `toml:"snapshot_max_retries" json:"snapshotMaxRetries"`
 	// PodAnnotations is a list of pod annotations passed to both pod sandbox as well as
 	// container OCI annotations.
 	PodAnnotations []string `toml:"pod_annotations" json:"PodAnnotations"`
@@ -89,6 +121,12 @@ type Runtime struct {
 	// Container annotations in CRI are usually generated by other Kubernetes node components (i.e., not users).
 	// Currently, only device plugins populate the annotations.
 	ContainerAnnotations []string `toml:"container_annotations" json:"ContainerAnnotations"`
+	// SandboxAnnotationPrefixes is a list of pod annotation key prefixes forwarded to
+	// the sandbox OCI spec for the runtime to consume as runtime options, e.g.
+	// "io.katacontainers.config." for Kata memory hotplug or "dev.gvisor.flag." for
+	// gVisor platform flags. Prefixes must fall within the allowlisted runtime
+	// annotation namespaces and are validated at config load.
+	SandboxAnnotationPrefixes []string `toml:"sandbox_annotation_prefixes" json:"sandboxAnnotationPrefixes"`
 	// Options are config options for the runtime.
 	Options map[string]interface{} `toml:"options" json:"options"`
 	// PrivilegedWithoutHostDevices overloads the default behaviour for adding host devices to the
@@ -100,6 +138,12 @@ type Runtime struct {
 	PrivilegedWithoutHostDevicesAllDevicesAllowed bool `toml:"privileged_without_host_devices_all_devices_allowed" json:"privileged_without_host_devices_all_devices_allowed"`
 	// BaseRuntimeSpec is a json file with OCI spec to use as base spec that all container's will be created from.
 	BaseRuntimeSpec string `toml:"base_runtime_spec" json:"baseRuntimeSpec"`
+	// BaseRuntimeSpecs maps OCI platform strings (e.g. "linux/arm64", "linux/arm/v7") to
+	// json files with OCI specs to use as base specs for containers of that platform.
+	// When a container's image platform matches an entry here, it takes precedence over
+	// BaseRuntimeSpec. This allows heterogeneous nodes running emulated architectures to
+	// use per-architecture defaults.
+	BaseRuntimeSpecs map[string]string `toml:"base_runtime_specs" json:"baseRuntimeSpecs"`
 	// NetworkPluginConfDir is a directory containing the CNI network information for the runtime class.
 	NetworkPluginConfDir string `toml:"cni_conf_dir" json:"cniConfDir"`
 	// NetworkPluginMaxConfNum is the max number of plugin config files that will
@@ -111,6 +155,18 @@ type Runtime struct {
 	// while using default snapshotters for operational simplicity.
 	// See https://github.com/containerd/containerd/issues/6657 for details.
 	Snapshotter string `toml:"snapshotter" json:"snapshotter"`
+	// DefaultUlimits are rlimits applied to all containers using this runtime,
+	// replacing the corresponding limits of the base runtime spec.
+	DefaultUlimits []Ulimit `toml:"default_ulimits" json:"defaultUlimits"`
+	// MaxUlimits bound the rlimit overrides pods may request through the
+	// "io.containerd.cri.ulimits" annotation. Overrides of types not listed
+	// here are rejected.
+	MaxUlimits []Ulimit `toml:"max_ulimits" json:"maxUlimits"`
+	// Wasm marks the runtime as a WebAssembly shim. Containers using a wasm
+	// runtime skip Linux-only spec handling (capabilities, devices, seccomp,
+	// apparmor, cgroups) and their sandboxes are run without a dedicated
+	// network namespace, neither of which apply to wasm workloads.
+	Wasm bool `toml:"wasm" json:"wasm"`
 	// Sandboxer defines which sandbox runtime to use when scheduling pods
 	// This features requires the new CRI server implementation (enabled by default in 2.0)
 	// shim - means use whatever Controller implementation provided by shim (e.g. use RemoteController).
@@ -169,6 +225,21 @@ type CniConfig struct {
 	// NetworkPluginConfTemplate was once deprecated in containerd v1.7.0,
 	// but its deprecation was cancelled in v1.7.3.
 	NetworkPluginConfTemplate string `toml:"conf_template" json:"confTemplate"`
+	// NetworkReconcilePeriod enables periodic reconciliation of CNI state
+	// with live sandboxes: cached CNI attachments and network namespaces left
+	// behind by plugin crashes are torn down, releasing their IPAM leases
+	// before leaked allocations exhaust the pod CIDR. The reconciliation runs
+	// at startup and then at the given period. The string is in the golang
+	// duration format; empty disables reconciliation.
+	NetworkReconcilePeriod string `toml:"network_reconcile_period" json:"networkReconcilePeriod"`
+	// NetworkProbePeriod enables periodic validation of the network of
+	// ready sandboxes: the default interface must exist, carry an address
+	// and have a default route. When a sandbox network is found broken,
+	// for example after a CNI plugin upgrade flushed its state, the CNI
+	// setup is re-invoked instead of leaving the pod without networking
+	// until it is restarted. The string is in the golang duration format;
+	// empty disables probing.
+	NetworkProbePeriod string `toml:"network_probe_period" json:"networkProbePeriod"`
 	// IPPreference specifies the strategy to use when selecting the main IP address for a pod.
 	//
 	// Options include:
@@ -178,6 +249,22 @@ type CniConfig struct {
 	IPPreference string `toml:"ip_pref" json:"ipPref"`
 }
 
+// DensityConfig contains node-level density guardrails. Creation requests
+// beyond a limit fail with a ResourceExhausted error instead of exhausting
+// kernel or snapshotter resources. A zero value disables the respective
+// limit.
+type DensityConfig struct {
+	// MaxContainers caps the number of CRI containers on the node.
+	MaxContainers int `toml:"max_containers" json:"maxContainers"`
+	// MaxSandboxes caps the number of pod sandboxes on the node.
+	MaxSandboxes int `toml:"max_sandboxes" json:"maxSandboxes"`
+	// MaxLoopDevices caps the number of attached loop devices on the node.
+	MaxLoopDevices int `toml:"max_loop_devices" json:"maxLoopDevices"`
+	// MaxOverlaybdDevices caps the number of TCMU-backed overlaybd devices
+	// on the node.
+	MaxOverlaybdDevices int `toml:"max_overlaybd_devices" json:"maxOverlaybdDevices"`
+}
+
 // Mirror contains the config related to the registry mirror
 type Mirror struct {
 	// Endpoints are endpoints for a namespace. CRI plugin will try the endpoints
@@ -229,6 +316,17 @@ type RegistryConfig struct {
 	Auth *AuthConfig `toml:"auth" json:"auth"`
 }
 
+// ImageRewrite maps image references matching a prefix pattern to an internal
+// mirror, so air-gapped clusters can redirect pulls without rewriting pod specs.
+type ImageRewrite struct {
+	// Pattern is a normalized image reference prefix, with an optional trailing
+	// `*` wildcard, e.g. "docker.io/library/*" or "registry.k8s.io/pause:3.9".
+	Pattern string `toml:"pattern" json:"pattern"`
+	// Replacement replaces the part of the reference matched by Pattern,
+	// e.g. "mirror.internal:5000/library/".
+	Replacement string `toml:"replacement" json:"replacement"`
+}
+
 // ImageDecryption contains configuration to handling decryption of encrypted container images.
 type ImageDecryption struct {
 	// KeyModel specifies the trust model of where keys should reside.
@@ -254,10 +352,31 @@ type ImagePlatform struct {
 	Snapshotter string `toml:"snapshotter" json:"snapshotter"`
 }
 
+// ImagePullPolicy configures per-runtime-class constraints on image pulls,
+// enforced before image resolution.
+type ImagePullPolicy struct {
+	// Platform forces images pulled for the runtime to this platform
+	// instead of the default platform matcher.
+	Platform string `toml:"platform" json:"platform"`
+	// RequireDigest rejects tag-only image references, so only digest
+	// pinned images can be pulled for the runtime.
+	RequireDigest bool `toml:"require_digest" json:"requireDigest"`
+	// DisallowHTTP rejects pulls resolved through plaintext HTTP registry
+	// endpoints.
+	DisallowHTTP bool `toml:"disallow_http" json:"disallowHTTP"`
+}
+
 type ImageConfig struct {
 	// Snapshotter is the snapshotter used by containerd.
 	Snapshotter string `toml:"snapshotter" json:"snapshotter"`
 
+	// UnpackSnapshotters is an additional list of snapshotters pulled images
+	// are unpacked into besides the snapshotter containers run on, e.g. plain
+	// "overlayfs" while containers run on a lazy-pulling snapshotter (or the
+	// other way around). It enables gradual snapshotter rollouts on existing
+	// nodes: images are ready under both before the runtime is switched.
+	UnpackSnapshotters []string `toml:"unpack_snapshotters" json:"unpackSnapshotters"`
+
 	// DisableSnapshotAnnotations disables to pass additional annotations (image
 	// related information) to snapshotters. These annotations are required by
 	// stargz snapshotter (https://github.com/containerd/stargz-snapshotter).
@@ -285,15 +404,44 @@ type ImageConfig struct {
 	// snapshotter for unpacking.
 	RuntimePlatforms map[string]ImagePlatform `toml:"runtime_platforms" json:"runtimePlatforms"`
 
+	// PullPolicies maps runtime handler names to pull constraints, so
+	// stricter runtime classes can require digest references, refuse
+	// plaintext HTTP registries or force a platform.
+	PullPolicies map[string]ImagePullPolicy `toml:"pull_policies" json:"pullPolicies"`
+
 	// Registry contains config related to the registry
 	Registry Registry `toml:"registry" json:"registry"`
 
+	// DynamicRegistryConfigPath names an optional TOML file with a registry
+	// section overriding Registry at runtime. The file is watched and valid
+	// changes take effect on the next pull; removing it restores the static
+	// configuration.
+	DynamicRegistryConfigPath string `toml:"dynamic_registry_config_path" json:"dynamicRegistryConfigPath"`
+
+	// ImageRewrites rewrites image references to internal mirrors before they
+	// are resolved. The first matching rule wins. Only resolution is affected;
+	// the image store keeps the original reference so image status and pod
+	// specs are unchanged cluster-wide.
+	ImageRewrites []ImageRewrite `toml:"image_rewrites" json:"imageRewrites"`
+
 	// ImageDecryption contains config related to handling decryption of encrypted container images
 	ImageDecryption `toml:"image_decryption" json:"imageDecryption"`
 
 	// MaxConcurrentDownloads restricts the number of concurrent downloads for each image.
 	// TODO: Migrate to transfer service
 	MaxConcurrentDownloads int `toml:"max_concurrent_downloads" json:"maxConcurrentDownloads"`
+	// MaxConcurrentUnpacks bounds the layer applications running at once
+	// across all image pulls, so concurrent unpacks don't thrash the disk.
+	// Applies within one image stay ordered by the layer chain. Zero means
+	// unlimited.
+	MaxConcurrentUnpacks int `toml:"max_concurrent_unpacks" json:"maxConcurrentUnpacks"`
+	// ImagePullBandwidthLimit caps the registry download rate of a single
+	// image pull, in bytes per second. Zero means unlimited.
+	ImagePullBandwidthLimit int64 `toml:"image_pull_bandwidth_limit" json:"imagePullBandwidthLimit"`
+	// ImagePullGlobalBandwidthLimit caps the aggregate registry download
+	// rate across all image pulls, in bytes per second, so pulls cannot
+	// saturate the node NIC. Zero means unlimited.
+	ImagePullGlobalBandwidthLimit int64 `toml:"image_pull_global_bandwidth_limit" json:"imagePullGlobalBandwidthLimit"`
 
 	// ImagePullProgressTimeout is the maximum duration that there is no
 	// image data read from image registry in the open connection. It will
@@ -311,6 +459,53 @@ type ImageConfig struct {
 
 	// StatsCollectPeriod is the period (in seconds) of snapshots stats collection.
 	StatsCollectPeriod int `toml:"stats_collect_period" json:"statsCollectPeriod"`
+	// StatsCollectWorkers bounds the concurrent snapshot usage queries per
+	// snapshotter during stats collection. Zero sizes the pool to the
+	// effective CPU count.
+	StatsCollectWorkers int `toml:"stats_collect_workers" json:"statsCollectWorkers"`
+
+	// AsyncImageGC makes RemoveImage return without waiting for content garbage
+	// collection. Reclamation of the removed image's blobs is handed to a
+	// background scheduler which rate limits GC triggers and defers them while
+	// image pulls are in flight.
+	AsyncImageGC bool `toml:"async_image_gc" json:"asyncImageGC"`
+
+	// ImageGCMinInterval is the minimum duration between content garbage
+	// collections triggered by the asynchronous image GC scheduler. Only used
+	// when AsyncImageGC is enabled.
+	//
+	// The string is in the golang duration format, see:
+	//   https://golang.org/pkg/time/#ParseDuration
+	ImageGCMinInterval string `toml:"image_gc_min_interval" json:"imageGCMinInterval"`
+
+	// ImageGCDryRun makes the asynchronous image GC scheduler only log and
+	// report what it would reclaim instead of deleting it; image removals
+	// fall back to the synchronous delete path. This allows tuning the GC
+	// settings in production before enabling destructive reclamation. Only
+	// used when AsyncImageGC is enabled.
+	ImageGCDryRun bool `toml:"image_gc_dry_run" json:"imageGCDryRun"`
+
+	// ImageGCWhitelistLabels protects images from garbage collection by OCI
+	// image config labels or CRI pull annotations instead of by name. Each
+	// entry is a label key, optionally with a "key=value" constraint, e.g.
+	// "gc-protect=true". Matching images are reported as pinned so the
+	// kubelet excludes them when reclaiming disk space.
+	ImageGCWhitelistLabels []string `toml:"image_gc_whitelist_labels" json:"imageGCWhitelistLabels"`
+	// ImageGCExitedGracePeriod keeps the image of a container protected from
+	// garbage collection for this long after the container exits, so
+	// crash-looping pods don't have their image evicted between restarts.
+	// Empty disables the protection.
+	//
+	// The string is in the golang duration format, see:
+	//   https://golang.org/pkg/time/#ParseDuration
+	ImageGCExitedGracePeriod string `toml:"image_gc_exited_grace_period" json:"imageGCExitedGracePeriod"`
+
+	// StorageIOClasses lowers the Linux I/O scheduling class of background
+	// storage work per activity ("pull_unpack", "conversion", "gc",
+	// "cleanup") so it doesn't starve workload I/O. Values are "idle" or
+	// "best-effort[:level]" with level 0-7, e.g. "gc" = "idle". Only
+	// supported on Linux.
+	StorageIOClasses map[string]string `toml:"storage_io_classes" json:"storageIOClasses"`
 }
 
 // RuntimeConfig contains toml config related to CRI plugin,
@@ -320,6 +515,31 @@ type RuntimeConfig struct {
 	ContainerdConfig `toml:"containerd" json:"containerd"`
 	// CniConfig contains config related to cni
 	CniConfig `toml:"cni" json:"cni"`
+	// PrometheusSDAddress is an optional address serving a Prometheus HTTP
+	// service discovery endpoint which lists running containers of pods
+	// annotated with "prometheus.io/scrape" as scrape targets. Empty disables
+	// the endpoint.
+	PrometheusSDAddress string `toml:"prometheus_sd_address" json:"prometheusSDAddress"`
+	// ExclusiveCPUSetPool is an optional list of CPUs in cpuset list format
+	// (e.g. "4-15,20") reserved for exclusive assignment to containers of
+	// Guaranteed pods with integer CPU requests, in the style of the
+	// kubelet's static CPU manager policy. Empty disables exclusive CPU
+	// assignment. Only works on Linux.
+	ExclusiveCPUSetPool string `toml:"exclusive_cpuset_pool" json:"exclusiveCpusetPool"`
+	// ManagePodCgroups makes containerd create and validate the pod-level
+	// cgroup named by the sandbox's cgroup parent, reconcile its limits to
+	// the pod's resources (or the sum of its containers plus overhead), and
+	// remove it on pod removal. For clusters where the kubelet delegates pod
+	// cgroup management. Only works on Linux.
+	ManagePodCgroups bool `toml:"manage_pod_cgroups" json:"managePodCgroups"`
+	// Density holds node-level container density guardrails enforced when
+	// sandboxes and containers are created.
+	Density DensityConfig `toml:"density" json:"density"`
+	// DebugConfigPath is the path of an optional TOML file with debug
+	// facility toggles (log level override, pprof endpoint, per-subsystem
+	// verbose logging) watched and applied at runtime, so incidents can be
+	// debugged without restarting containerd. Empty disables the watcher.
+	DebugConfigPath string `toml:"debug_config_path" json:"debugConfigPath"`
 	// EnableSelinux indicates to enable the selinux support.
 	EnableSelinux bool `toml:"enable_selinux" json:"enableSelinux"`
 	// SelinuxCategoryRange allows the upper bound on the category range to be set.
@@ -342,6 +562,23 @@ type RuntimeConfig struct {
 	// DisableProcMount disables Kubernetes ProcMount support. This MUST be set to `true`
 	// when using containerd with Kubernetes <=1.11.
 	DisableProcMount bool `toml:"disable_proc_mount" json:"disableProcMount"`
+	// MetricSet restricts the metrics served by the ListMetricDescriptors and
+	// ListPodSandboxMetrics RPCs to the named subset. When empty, all supported
+	// metrics are served.
+	MetricSet []string `toml:"metric_set" json:"metricSet"`
+	// MetricsCollectPeriod is the period (in seconds) pod sandbox metrics are cached
+	// between collections. It should be aligned with the snapshots stats collection
+	// period of the image service.
+	MetricsCollectPeriod int `toml:"metrics_collect_period" json:"metricsCollectPeriod"`
+	// StatsFreshnessWindow is how long collected container and pod sandbox
+	// stats may be served from cache before cgroups are read again. It
+	// bounds how stale the stats returned by ContainerStats and
+	// PodSandboxStats can be. An empty or zero value disables caching and
+	// every stats request reads cgroups synchronously.
+	//
+	// The string is in the golang duration format, see:
+	//   https://golang.org/pkg/time/#ParseDuration
+	StatsFreshnessWindow string `toml:"stats_freshness_window" json:"statsFreshnessWindow"`
 	// UnsetSeccompProfile is the profile containerd/cri will use If the provided seccomp profile is
 	// unset (`""`) for a container (default is `unconfined`)
 	UnsetSeccompProfile string `toml:"unset_seccomp_profile" json:"unsetSeccompProfile"`
@@ -395,6 +632,17 @@ type RuntimeConfig struct {
 	// For example, the value can be '5h', '2h30m', '10s'.
 	DrainExecSyncIOTimeout string `toml:"drain_exec_sync_io_timeout" json:"drainExecSyncIOTimeout"`
 
+	// ExecAuditLogPath is the path of a file recording every Exec, ExecSync
+	// and Attach call as a JSON line, including the redacted command, the
+	// user identity and the pod and container the session targeted. Empty
+	// disables audit logging.
+	ExecAuditLogPath string `toml:"exec_audit_log_path" json:"execAuditLogPath"`
+	// ExecAuditRedactPatterns is a list of additional regular expressions
+	// matched against exec command arguments; matching arguments have their
+	// values redacted in the audit log. Arguments carrying credentials, such
+	// as passwords and tokens, are redacted regardless of this setting.
+	ExecAuditRedactPatterns []string `toml:"exec_audit_redact_patterns" json:"execAuditRedactPatterns"`
+
 	// IgnoreDeprecationWarnings is the list of the deprecation IDs (such as "io.containerd.deprecation/pull-schema-1-image")
 	// that should be ignored for checking "ContainerdHasNoDeprecationWarnings" condition.
 	IgnoreDeprecationWarnings []string `toml:"ignore_deprecation_warnings" json:"ignoreDeprecationWarnings"`
@@ -470,6 +718,23 @@ func ValidateImageConfig(ctx context.Context, c *ImageConfig) ([]deprecation.War
 		log.G(ctx).Warning("`configs` is deprecated, please use `config_path` instead")
 	}
 
+	for _, r := range c.ImageRewrites {
+		if r.Pattern == "" || r.Replacement == "" {
+			return warnings, errors.New("`image_rewrites` entries require both `pattern` and `replacement`")
+		}
+		if i := strings.Index(r.Pattern, "*"); i >= 0 && i != len(r.Pattern)-1 {
+			return warnings, fmt.Errorf("`image_rewrites` pattern %q may only use `*` as a trailing wildcard", r.Pattern)
+		}
+	}
+
+	for name, p := range c.PullPolicies {
+		if p.Platform != "" {
+			if _, err := platforms.Parse(p.Platform); err != nil {
+				return warnings, fmt.Errorf("invalid `pull_policies` platform for runtime %q: %w", name, err)
+			}
+		}
+	}
+
 	// Validation for deprecated auths options and mapping it to configs.
 	if len(c.Registry.Auths) != 0 {
 		if c.Registry.Configs == nil {
@@ -503,6 +768,50 @@ func ValidateImageConfig(ctx context.Context, c *ImageConfig) ([]deprecation.War
 	return warnings, nil
 }
 
+// validUlimitTypes are the short rlimit resource names accepted in ulimit
+// configuration and pod annotation overrides.
+var validUlimitTypes = map[string]bool{
+	"as":         true,
+	"core":       true,
+	"cpu":        true,
+	"data":       true,
+	"fsize":      true,
+	"locks":      true,
+	"memlock":    true,
+	"msgqueue":   true,
+	"nice":       true,
+	"nofile":     true,
+	"nproc":      true,
+	"rss":        true,
+	"rtprio":     true,
+	"rttime":     true,
+	"sigpending": true,
+	"stack":      true,
+}
+
+// ValidateUlimits checks rlimit entries for unknown resource types and
+// inverted soft/hard values.
+func ValidateUlimits(ulimits []Ulimit) error {
+	for _, u := range ulimits {
+		if !validUlimitTypes[u.Type] {
+			return fmt.Errorf("unknown ulimit type %q", u.Type)
+		}
+		if u.Soft > u.Hard {
+			return fmt.Errorf("ulimit %q soft limit %d exceeds hard limit %d", u.Type, u.Soft, u.Hard)
+		}
+	}
+	return nil
+}
+
+// allowedSandboxAnnotationNamespaces are the annotation namespaces which may be
+// forwarded to a sandbox runtime via `sandbox_annotation_prefixes`. Restricting
+// the prefixes to well known VM runtime namespaces keeps arbitrary pod
+// annotations from leaking into runtime options.
+var allowedSandboxAnnotationNamespaces = []string{
+	"io.katacontainers.",
+	"dev.gvisor.",
+}
+
 // ValidateRuntimeConfig validates the given runtime configuration.
 func ValidateRuntimeConfig(ctx context.Context, c *RuntimeConfig) ([]deprecation.Warning, error) {
 	var warnings []deprecation.Warning
@@ -522,6 +831,49 @@ func ValidateRuntimeConfig(ctx context.Context, c *RuntimeConfig) ([]deprecation
 		if !r.PrivilegedWithoutHostDevices && r.PrivilegedWithoutHostDevicesAllDevicesAllowed {
 			return warnings, errors.New("`privileged_without_host_devices_all_devices_allowed` requires `privileged_without_host_devices` to be enabled")
 		}
+		if r.Path != "" {
+			if !filepath.IsAbs(r.Path) {
+				return warnings, fmt.Errorf("`runtime_path` %q for runtime %q is not an absolute path", r.Path, k)
+			}
+			fi, err := os.Stat(r.Path)
+			if err != nil {
+				return warnings, fmt.Errorf("invalid `runtime_path` %q for runtime %q: %w", r.Path, k, err)
+			}
+			if fi.Mode().Perm()&0o002 != 0 {
+				return warnings, fmt.Errorf("`runtime_path` %q for runtime %q must not be world-writable", r.Path, k)
+			}
+		}
+		for _, e := range r.ShimEnv {
+			key, _, ok := strings.Cut(e, "=")
+			if !ok || key == "" {
+				return warnings, fmt.Errorf("invalid `shim_env` entry %q for runtime %q, expected `KEY=VALUE`", e, k)
+			}
+		}
+		for _, p := range r.SandboxAnnotationPrefixes {
+			allowed := false
+			for _, ns := range allowedSandboxAnnotationNamespaces {
+				if strings.HasPrefix(p, ns) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return warnings, fmt.Errorf("`sandbox_annotation_prefixes` entry %q for runtime %q is outside the allowed namespaces %v", p, k, allowedSandboxAnnotationNamespaces)
+			}
+		}
+		if err := ValidateUlimits(r.DefaultUlimits); err != nil {
+			return warnings, fmt.Errorf("invalid `default_ulimits` for runtime %q: %w", k, err)
+		}
+		if err := ValidateUlimits(r.MaxUlimits); err != nil {
+			return warnings, fmt.Errorf("invalid `max_ulimits` for runtime %q: %w", k, err)
+		}
+		for _, d := range r.DefaultUlimits {
+			for _, m := range r.MaxUlimits {
+				if d.Type == m.Type && (d.Hard > m.Hard || d.Soft > m.Soft) {
+					return warnings, fmt.Errorf("`default_ulimits` entry %q for runtime %q exceeds `max_ulimits`", d.Type, k)
+				}
+			}
+		}
 		// If empty, use default podSandbox mode
 		if len(r.Sandboxer) == 0 {
 			r.Sandboxer = string(ModePodSandbox)
@@ -535,6 +887,21 @@ func ValidateRuntimeConfig(ctx context.Context, c *RuntimeConfig) ([]deprecation
 			return warnings, fmt.Errorf("invalid `drain_exec_sync_io_timeout`: %w", err)
 		}
 	}
+	if c.NetworkReconcilePeriod != "" {
+		if _, err := time.ParseDuration(c.NetworkReconcilePeriod); err != nil {
+			return warnings, fmt.Errorf("invalid `network_reconcile_period`: %w", err)
+		}
+	}
+	if c.NetworkProbePeriod != "" {
+		if _, err := time.ParseDuration(c.NetworkProbePeriod); err != nil {
+			return warnings, fmt.Errorf("invalid `network_probe_period`: %w", err)
+		}
+	}
+	for _, p := range c.ExecAuditRedactPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return warnings, fmt.Errorf("invalid `exec_audit_redact_patterns` entry %q: %w", p, err)
+		}
+	}
 	if err := ValidateEnableUnprivileged(ctx, c); err != nil {
 		return warnings, err
 	}
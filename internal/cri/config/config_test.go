@@ -111,6 +111,16 @@ func TestValidateConfig(t *testing.T) {
 			},
 			imageExpectedErr: "`mirrors` cannot be set when `config_path` is provided",
 		},
+		"invalid pull policy platform": {
+			imageConfig: &ImageConfig{
+				PullPolicies: map[string]ImagePullPolicy{
+					"kata": {
+						Platform: "linux/amd64/extra/invalid",
+					},
+				},
+			},
+			imageExpectedErr: "invalid `pull_policies` platform for runtime \"kata\"",
+		},
 		"deprecated mirrors": {
 			runtimeConfig: &RuntimeConfig{
 				ContainerdConfig: ContainerdConfig{
@@ -204,6 +214,35 @@ func TestValidateConfig(t *testing.T) {
 			},
 			runtimeExpectedErr: "`privileged_without_host_devices_all_devices_allowed` requires `privileged_without_host_devices` to be enabled",
 		},
+		"sandbox_annotation_prefixes outside allowed namespaces": {
+			runtimeConfig: &RuntimeConfig{
+				ContainerdConfig: ContainerdConfig{
+					DefaultRuntimeName: RuntimeDefault,
+					Runtimes: map[string]Runtime{
+						RuntimeDefault: {
+							Type:                      "default",
+							SandboxAnnotationPrefixes: []string{"example.com/"},
+						},
+					},
+				},
+			},
+			runtimeExpectedErr: "`sandbox_annotation_prefixes` entry \"example.com/\" for runtime",
+		},
+		"default_ulimits exceeding max_ulimits": {
+			runtimeConfig: &RuntimeConfig{
+				ContainerdConfig: ContainerdConfig{
+					DefaultRuntimeName: RuntimeDefault,
+					Runtimes: map[string]Runtime{
+						RuntimeDefault: {
+							Type:           "default",
+							DefaultUlimits: []Ulimit{{Type: "nofile", Soft: 1024, Hard: 65536}},
+							MaxUlimits:     []Ulimit{{Type: "nofile", Soft: 1024, Hard: 4096}},
+						},
+					},
+				},
+			},
+			runtimeExpectedErr: "`default_ulimits` entry \"nofile\" for runtime",
+		},
 		"invalid drain_exec_sync_io_timeout input": {
 			runtimeConfig: &RuntimeConfig{
 				ContainerdConfig: ContainerdConfig{
@@ -37,6 +37,7 @@ func DefaultImageConfig() ImageConfig {
 		ImagePullProgressTimeout: defaultImagePullProgressTimeoutDuration.String(),
 		ImagePullWithSyncFs:      false,
 		StatsCollectPeriod:       10,
+		ImageGCMinInterval:       "10s",
 	}
 }
 
@@ -100,5 +101,6 @@ func DefaultRuntimeConfig() RuntimeConfig {
 		DrainExecSyncIOTimeout:           "0s",
 		EnableUnprivilegedPorts:          true,
 		EnableUnprivilegedICMP:           true,
+		MetricsCollectPeriod:             10,
 	}
 }
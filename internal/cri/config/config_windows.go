@@ -35,6 +35,7 @@ func DefaultImageConfig() ImageConfig {
 			"sandbox": DefaultSandboxImage,
 		},
 		ImagePullProgressTimeout: defaultImagePullProgressTimeoutDuration.String(),
+		ImageGCMinInterval:       "10s",
 	}
 }
 
@@ -82,5 +83,6 @@ func DefaultRuntimeConfig() RuntimeConfig {
 		// TODO(windows): Add platform specific config, so that most common defaults can be shared.
 
 		DrainExecSyncIOTimeout: "0s",
+		MetricsCollectPeriod:   10,
 	}
 }
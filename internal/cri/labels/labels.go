@@ -37,4 +37,7 @@ const (
 	ContainerMetadataExtension = criContainerdPrefix + ".container.metadata"
 	// SandboxMetadataExtension is an extension name that identify metadata of sandbox in CreateContainerRequest
 	SandboxMetadataExtension = criContainerdPrefix + ".sandbox.metadata"
+	// SnapshotImageRefLabel is the snapshot label recording the reference of
+	// the image backing a container's rootfs snapshot.
+	SnapshotImageRefLabel = "containerd.io/snapshot/cri.image-ref"
 )
@@ -395,8 +395,61 @@ func WithResources(resources *runtime.LinuxContainerResources, tolerateMissingHu
 				s.Linux.Resources.Unified[k] = v
 			}
 		}
+		return translateUnifiedResources(s)
+	}
+}
+
+// translateUnifiedResources moves extended resources which have no
+// first-class CRI field, such as CFS burst and block IO weight, from the
+// cgroup v2 style Unified map into their typed OCI fields. The runtime can
+// then apply them on both cgroup v1 and v2, rather than the update failing
+// on v1 which has no unified support.
+func translateUnifiedResources(s *runtimespec.Spec) error {
+	unified := s.Linux.Resources.Unified
+	if len(unified) == 0 {
 		return nil
 	}
+	for _, key := range []string{"cpu.cfs_burst_us", "cpu.max.burst"} {
+		v, ok := unified[key]
+		if !ok {
+			continue
+		}
+		burst, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %q: %w", v, key, err)
+		}
+		if s.Linux.Resources.CPU == nil {
+			s.Linux.Resources.CPU = &runtimespec.LinuxCPU{}
+		}
+		s.Linux.Resources.CPU.Burst = &burst
+		delete(unified, key)
+	}
+	for _, key := range []string{"blkio.weight", "io.weight"} {
+		v, ok := unified[key]
+		if !ok {
+			continue
+		}
+		w, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value %q for %q: %w", v, key, err)
+		}
+		if key == "io.weight" {
+			// The OCI field takes the cgroup v1 range. Invert the
+			// v1 to v2 weight conversion the runtime applies, so the
+			// requested io.weight round-trips on cgroup v2.
+			w = (w-1)*990/9999 + 10
+		}
+		if w > 1000 {
+			return fmt.Errorf("invalid value %q for %q: out of range", v, key)
+		}
+		weight := uint16(w)
+		if s.Linux.Resources.BlockIO == nil {
+			s.Linux.Resources.BlockIO = &runtimespec.LinuxBlockIO{}
+		}
+		s.Linux.Resources.BlockIO.Weight = &weight
+		delete(unified, key)
+	}
+	return nil
 }
 
 // WithOOMScoreAdj sets the oom score
@@ -246,6 +246,27 @@ func WithSelinuxLabels(process, mount string) oci.SpecOpts {
 	}
 }
 
+// WithRlimits sets the provided rlimits on the container process, replacing
+// existing limits of the same type.
+func WithRlimits(rlimits []runtimespec.POSIXRlimit) oci.SpecOpts {
+	return func(ctx context.Context, client oci.Client, c *containers.Container, s *runtimespec.Spec) error {
+		for _, rl := range rlimits {
+			replaced := false
+			for i, existing := range s.Process.Rlimits {
+				if existing.Type == rl.Type {
+					s.Process.Rlimits[i] = rl
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				s.Process.Rlimits = append(s.Process.Rlimits, rl)
+			}
+		}
+		return nil
+	}
+}
+
 // WithSysctls sets the provided sysctls onto the spec
 func WithSysctls(sysctls map[string]string) oci.SpecOpts {
 	return func(ctx context.Context, client oci.Client, c *containers.Container, s *runtimespec.Spec) error {
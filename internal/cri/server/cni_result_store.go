@@ -0,0 +1,100 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+// cniResultsDir is the directory under the CRI root dir holding the
+// persisted CNI result of each sandbox.
+const cniResultsDir = "cni-results"
+
+// persistedCNIResult is the on-disk form of a sandbox's CNI results. It is
+// written right after CNI ADD succeeds, before the sandbox metadata is
+// stored, so that network teardown still knows about the attachment when
+// containerd crashed in between. The file is removed once teardown
+// succeeds, making network cleanup idempotent across restarts.
+type persistedCNIResult struct {
+	CNIResult           *cni.Result `json:"cniResult,omitempty"`
+	AdditionalCNIResult *cni.Result `json:"additionalCNIResult,omitempty"`
+}
+
+func (c *criService) cniResultPath(id string) string {
+	return filepath.Join(c.config.RootDir, cniResultsDir, id+".json")
+}
+
+// storeCNIResult persists the CNI results of the sandbox on disk.
+func (c *criService) storeCNIResult(sandbox *sandboxstore.Sandbox) error {
+	data, err := json.Marshal(&persistedCNIResult{
+		CNIResult:           sandbox.CNIResult,
+		AdditionalCNIResult: sandbox.AdditionalCNIResult,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal CNI result: %w", err)
+	}
+	path := c.cniResultPath(sandbox.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// deleteCNIResult removes the persisted CNI results of the sandbox after a
+// successful network teardown.
+func (c *criService) deleteCNIResult(ctx context.Context, id string) {
+	if err := os.Remove(c.cniResultPath(id)); err != nil && !os.IsNotExist(err) {
+		log.G(ctx).WithError(err).Warnf("failed to remove persisted CNI result of sandbox %q", id)
+	}
+}
+
+// restoreCNIResult fills in the CNI results of a recovered sandbox from the
+// persisted copy when they did not make it into the sandbox metadata before
+// a restart.
+func (c *criService) restoreCNIResult(ctx context.Context, sandbox *sandboxstore.Sandbox) {
+	if sandbox.CNIResult != nil || sandbox.NetNSPath == "" {
+		return
+	}
+	data, err := os.ReadFile(c.cniResultPath(sandbox.ID))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.G(ctx).WithError(err).Warnf("failed to read persisted CNI result of sandbox %q", sandbox.ID)
+		}
+		return
+	}
+	persisted := &persistedCNIResult{}
+	if err := json.Unmarshal(data, persisted); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to unmarshal persisted CNI result of sandbox %q", sandbox.ID)
+		return
+	}
+	sandbox.CNIResult = persisted.CNIResult
+	sandbox.AdditionalCNIResult = persisted.AdditionalCNIResult
+	log.G(ctx).WithField("podsandboxid", sandbox.ID).Info("Restored CNI result from persisted copy")
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/containerd/go-cni"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+func TestCNIResultRoundTrip(t *testing.T) {
+	c := newTestCRIService()
+	c.config.RootDir = t.TempDir()
+	ctx := context.Background()
+
+	sandbox := &sandboxstore.Sandbox{
+		Metadata: sandboxstore.Metadata{
+			ID:        "sandbox-id",
+			NetNSPath: "/var/run/netns/test",
+			CNIResult: &cni.Result{
+				Interfaces: map[string]*cni.Config{
+					defaultIfName: {},
+				},
+			},
+		},
+	}
+	require.NoError(t, c.storeCNIResult(sandbox))
+
+	// A recovered sandbox without CNI result in its metadata picks up the
+	// persisted copy.
+	recovered := &sandboxstore.Sandbox{
+		Metadata: sandboxstore.Metadata{
+			ID:        "sandbox-id",
+			NetNSPath: "/var/run/netns/test",
+		},
+	}
+	c.restoreCNIResult(ctx, recovered)
+	require.NotNil(t, recovered.CNIResult)
+	assert.Contains(t, recovered.CNIResult.Interfaces, defaultIfName)
+	assert.Nil(t, recovered.AdditionalCNIResult)
+
+	// A sandbox which already has a CNI result is left alone.
+	existing := &sandboxstore.Sandbox{
+		Metadata: sandboxstore.Metadata{
+			ID:        "sandbox-id",
+			NetNSPath: "/var/run/netns/test",
+			CNIResult: &cni.Result{},
+		},
+	}
+	c.restoreCNIResult(ctx, existing)
+	assert.Empty(t, existing.CNIResult.Interfaces)
+
+	c.deleteCNIResult(ctx, "sandbox-id")
+	_, err := os.Stat(c.cniResultPath("sandbox-id"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Deleting again and restoring without a persisted copy are no-ops.
+	c.deleteCNIResult(ctx, "sandbox-id")
+	missing := &sandboxstore.Sandbox{
+		Metadata: sandboxstore.Metadata{
+			ID:        "sandbox-id",
+			NetNSPath: "/var/run/netns/test",
+		},
+	}
+	c.restoreCNIResult(ctx, missing)
+	assert.Nil(t, missing.CNIResult)
+}
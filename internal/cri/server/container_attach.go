@@ -39,6 +39,7 @@ func (c *criService) Attach(ctx context.Context, r *runtime.AttachRequest) (*run
 	if state != runtime.ContainerState_CONTAINER_RUNNING {
 		return nil, fmt.Errorf("container is in %s state", criContainerStateToString(state))
 	}
+	c.auditExec("attach", cntr, nil, r.GetTty(), 0, nil)
 	return c.streamServer.GetAttach(r)
 }
 
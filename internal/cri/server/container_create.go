@@ -18,6 +18,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -36,6 +37,7 @@ import (
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/containerd/v2/core/snapshots"
 	"github.com/containerd/containerd/v2/internal/cri/annotations"
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
 	cio "github.com/containerd/containerd/v2/internal/cri/io"
@@ -43,8 +45,11 @@ import (
 	customopts "github.com/containerd/containerd/v2/internal/cri/opts"
 	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
 	"github.com/containerd/containerd/v2/internal/cri/util"
+	"github.com/containerd/containerd/v2/internal/rwlayer"
 	"github.com/containerd/containerd/v2/pkg/blockio"
+	"github.com/containerd/containerd/v2/pkg/coredump"
 	"github.com/containerd/containerd/v2/pkg/oci"
+	"github.com/containerd/containerd/v2/pkg/shim"
 	"github.com/containerd/platforms"
 )
 
@@ -57,6 +62,9 @@ func init() {
 func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateContainerRequest) (_ *runtime.CreateContainerResponse, retErr error) {
 	config := r.GetConfig()
 	log.G(ctx).Debugf("Container config %+v", config)
+	if err := c.checkContainerDensity(); err != nil {
+		return nil, err
+	}
 	sandboxConfig := r.GetSandboxConfig()
 	sandbox, err := c.sandboxStore.Get(r.GetPodSandboxId())
 	if err != nil {
@@ -93,6 +101,13 @@ func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateConta
 			c.containerNameIndex.ReleaseByName(name)
 		}
 	}()
+	defer func() {
+		// Return exclusive CPUs assigned during spec building if the
+		// container wasn't created.
+		if retErr != nil && c.cpusetAllocator != nil {
+			c.cpusetAllocator.Release(id)
+		}
+	}()
 
 	// Create initial internal container metadata.
 	meta := containerstore.Metadata{
@@ -172,6 +187,12 @@ func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateConta
 	}
 	log.G(ctx).Debugf("Use OCI runtime %+v for sandbox %q and container %q", ociRuntime, sandboxID, id)
 
+	if ociRuntime.CoreDumpPath != "" {
+		if _, err := coredump.EnsureDir(ociRuntime.CoreDumpPath, sandboxID); err != nil {
+			return nil, fmt.Errorf("failed to create core dump directory for sandbox %q: %w", sandboxID, err)
+		}
+	}
+
 	spec, err := c.buildContainerSpec(
 		platform,
 		id,
@@ -216,16 +237,29 @@ func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateConta
 	if err != nil {
 		return nil, err
 	}
+	// Label the writable layer with the pod sandbox ID so snapshotters with a
+	// rw-layer driver configured can encrypt it with the pod's key, and with
+	// the backing image reference so the snapshot store can report which
+	// image backs the container's rootfs.
+	sOpts = append(sOpts, snapshots.WithLabels(map[string]string{
+		rwlayer.PodLabel:                sandboxID,
+		crilabels.SnapshotImageRefLabel: image.ID,
+	}))
+
+	// Prepare container rootfs. This is always writeable even if
+	// the container wants a readonly rootfs since we want to give
+	// the runtime (runc) a chance to modify (e.g. to create mount
+	// points corresponding to spec.Mounts) before making the
+	// rootfs readonly (requested by spec.Root.Readonly).
+	snapshotOpt := customopts.WithNewSnapshot(id, containerdImage, sOpts...)
+	if ociRuntime.SnapshotMaxRetries > 0 {
+		snapshotOpt = withSnapshotRetry(snapshotOpt, ociRuntime.SnapshotMaxRetries)
+	}
 
 	// Set snapshotter before any other options.
 	opts := []containerd.NewContainerOpts{
 		containerd.WithSnapshotter(c.RuntimeSnapshotter(ctx, ociRuntime)),
-		// Prepare container rootfs. This is always writeable even if
-		// the container wants a readonly rootfs since we want to give
-		// the runtime (runc) a chance to modify (e.g. to create mount
-		// points corresponding to spec.Mounts) before making the
-		// rootfs readonly (requested by spec.Root.Readonly).
-		customopts.WithNewSnapshot(id, containerdImage, sOpts...),
+		snapshotOpt,
 	}
 	if len(volumeMounts) > 0 {
 		mountMap := make(map[string]string)
@@ -260,7 +294,7 @@ func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateConta
 		}
 	}()
 
-	specOpts, err := c.platformSpecOpts(platform, config, &image.ImageSpec.Config)
+	specOpts, err := c.platformSpecOpts(platform, config, &image.ImageSpec.Config, ociRuntime)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get container spec opts: %w", err)
 	}
@@ -329,6 +363,10 @@ func (c *criService) CreateContainer(ctx context.Context, r *runtime.CreateConta
 		return nil, fmt.Errorf("failed to add container %q into store: %w", id, err)
 	}
 
+	// Grow the pod-level cgroup to fit the new container when containerd
+	// manages pod cgroups.
+	c.reconcilePodCgroup(sandboxID)
+
 	c.generateAndSendContainerEvent(ctx, id, sandboxID, runtime.ContainerEventType_CONTAINER_CREATED_EVENT)
 
 	err = c.nri.PostCreateContainer(ctx, &sandbox, &container)
@@ -478,6 +516,7 @@ func (c *criService) platformSpecOpts(
 	platform platforms.Platform,
 	config *runtime.ContainerConfig,
 	imageConfig *imagespec.ImageConfig,
+	ociRuntime criconfig.Runtime,
 ) ([]oci.SpecOpts, error) {
 	var specOpts []oci.SpecOpts
 
@@ -511,7 +550,7 @@ func (c *criService) platformSpecOpts(
 
 	// Now grab the truly platform specific options (seccomp, apparmor etc. for linux
 	// for example).
-	ctrSpecOpts, err := c.containerSpecOpts(config, imageConfig)
+	ctrSpecOpts, err := c.containerSpecOpts(config, imageConfig, ociRuntime)
 	if err != nil {
 		return nil, err
 	}
@@ -596,7 +635,32 @@ func (c *criService) buildContainerSpec(
 		return nil, fmt.Errorf("failed to generate spec opts: %w", err)
 	}
 
-	return c.runtimeSpec(id, platform, ociRuntime.BaseRuntimeSpec, specOpts...)
+	if len(ociRuntime.ShimEnv) > 0 {
+		v, err := json.Marshal(ociRuntime.ShimEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal shim env: %w", err)
+		}
+		specOpts = append(specOpts, customopts.WithAnnotation(shim.EnvAnnotation, string(v)))
+	}
+
+	return c.runtimeSpec(id, platform, baseRuntimeSpecFile(ociRuntime, platform), specOpts...)
+}
+
+// baseRuntimeSpecFile returns the base OCI spec file to use for a container of the
+// given platform. A platform specific entry in `base_runtime_specs` takes precedence
+// over the runtime wide `base_runtime_spec`.
+func baseRuntimeSpecFile(ociRuntime criconfig.Runtime, platform platforms.Platform) string {
+	for p, file := range ociRuntime.BaseRuntimeSpecs {
+		parsed, err := platforms.Parse(p)
+		if err != nil {
+			// Invalid entries are rejected when the config is loaded.
+			continue
+		}
+		if platforms.Only(parsed).Match(platform) {
+			return file
+		}
+	}
+	return ociRuntime.BaseRuntimeSpec
 }
 
 func (c *criService) buildLinuxSpec(
@@ -618,7 +682,7 @@ func (c *criService) buildLinuxSpec(
 	// only clear the default security settings if the runtime does not have a custom
 	// base runtime spec spec.  Admins can use this functionality to define
 	// default ulimits, seccomp, or other default settings.
-	if ociRuntime.BaseRuntimeSpec == "" {
+	if ociRuntime.BaseRuntimeSpec == "" && len(ociRuntime.BaseRuntimeSpecs) == 0 {
 		specOpts = append(specOpts, customopts.WithoutDefaultSecuritySettings)
 	}
 
@@ -652,6 +716,16 @@ func (c *criService) buildLinuxSpec(
 	}
 	specOpts = append(specOpts, oci.WithEnv([]string{hostnameEnv + "=" + hostname}))
 
+	// Apply the runtime's default ulimits and any pod annotation overrides on
+	// top of the base runtime spec.
+	ulimits, err := containerUlimits(ociRuntime, sandboxConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(ulimits) > 0 {
+		specOpts = append(specOpts, customopts.WithRlimits(ulimits))
+	}
+
 	// Apply envs from image config first, so that envs from container config
 	// can override them.
 	env := append([]string{}, imageConfig.Env...)
@@ -685,6 +759,12 @@ func (c *criService) buildLinuxSpec(
 		}
 	}()
 
+	// Apply per-mount annotation overrides (subpaths, propagation, recursive
+	// read-only) before the CRI mounts are turned into spec mounts.
+	if err := c.applyMountOverrides(config); err != nil {
+		return nil, err
+	}
+
 	specOpts = append(specOpts, customopts.WithMounts(c.os, config, extraMounts, mountLabel, runtimeHandler))
 
 	if !c.config.DisableProcMount {
@@ -706,8 +786,10 @@ func (c *criService) buildLinuxSpec(
 		}
 	}
 
-	specOpts = append(specOpts, customopts.WithDevices(c.os, config, c.config.DeviceOwnershipFromSecurityContext),
-		customopts.WithCapabilities(securityContext, c.allCaps))
+	if !ociRuntime.Wasm {
+		specOpts = append(specOpts, customopts.WithDevices(c.os, config, c.config.DeviceOwnershipFromSecurityContext),
+			customopts.WithCapabilities(securityContext, c.allCaps))
+	}
 
 	if securityContext.GetPrivileged() {
 		if !sandboxConfig.GetLinux().GetSecurityContext().GetPrivileged() {
@@ -740,7 +822,7 @@ func (c *criService) buildLinuxSpec(
 		specOpts = append(specOpts, oci.WithRootFSReadonly())
 	}
 
-	if c.config.DisableCgroup {
+	if c.config.DisableCgroup || ociRuntime.Wasm {
 		specOpts = append(specOpts, customopts.WithDisabledCgroups)
 	} else {
 		specOpts = append(specOpts, customopts.WithResources(config.GetLinux().GetResources(), c.config.TolerateMissingHugetlbController, c.config.DisableHugetlbController))
@@ -748,6 +830,47 @@ func (c *criService) buildLinuxSpec(
 			cgroupsPath := getCgroupsPath(sandboxConfig.GetLinux().GetCgroupParent(), id)
 			specOpts = append(specOpts, oci.WithCgroup(cgroupsPath))
 		}
+		// Pin the container to exclusive CPUs when it qualifies for the
+		// configured exclusive cpuset pool.
+		var exclusiveCPUs string
+		if c.cpusetAllocator != nil {
+			count, err := exclusiveCPUCount(config)
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				exclusiveCPUs, err = c.cpusetAllocator.Allocate(id, count)
+				if err != nil {
+					return nil, fmt.Errorf("failed to allocate exclusive CPUs: %w", err)
+				}
+				specOpts = append(specOpts, oci.WithCPUs(exclusiveCPUs))
+			}
+		}
+		// Constrain the container to the pod's NUMA node when the pod asks
+		// for NUMA pinning and the runtime has a placement policy.
+		if ociRuntime.NUMAPolicy != "" && sandboxConfig.GetAnnotations()[annotations.NUMAPin] == "true" {
+			if c.numa == nil {
+				// Single NUMA node or no topology, nothing to pin to.
+				numaCrossPlacements.Inc()
+			} else {
+				cpus, mems, err := c.numa.place(sandboxID, ociRuntime.NUMAPolicy)
+				if err != nil {
+					return nil, fmt.Errorf("failed to place pod on a NUMA node: %w", err)
+				}
+				if exclusiveCPUs != "" {
+					// Exclusive CPUs win over the node's CPU list, only pin
+					// the memory. Count the placement when they are not part
+					// of the chosen node.
+					if !cpusWithin(exclusiveCPUs, cpus) {
+						log.L.Warnf("Exclusive CPUs %q of container %q are not within NUMA node cpus %q", exclusiveCPUs, id, cpus)
+						numaCrossPlacements.Inc()
+					}
+					specOpts = append(specOpts, oci.WithCPUsMems(mems))
+				} else {
+					specOpts = append(specOpts, oci.WithCPUs(cpus), oci.WithCPUsMems(mems))
+				}
+			}
+		}
 	}
 
 	supplementalGroups := securityContext.GetSupplementalGroups()
@@ -1046,6 +1169,10 @@ func (c *criService) linuxContainerMounts(sandboxID string, config *runtime.Cont
 		sandboxDevShm := c.getSandboxDevShm(sandboxID)
 		if securityContext.GetNamespaceOptions().GetIpc() == runtime.NamespaceMode_NODE {
 			sandboxDevShm = devShm
+		} else if sb, err := c.sandboxStore.Get(sandboxID); err == nil &&
+			sb.Config.GetAnnotations()[annotations.SandboxDevShm] == "node" {
+			// The sandbox was set up with the host /dev/shm, follow it.
+			sandboxDevShm = devShm
 		}
 		// The ownership of /dev/shm could be delegated to remote sandbox controller. That file isn't required
 		// to be existed in host side for some sandbox runtimes. Skip it if we don't need it.
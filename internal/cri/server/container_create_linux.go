@@ -33,6 +33,7 @@ import (
 	"github.com/containerd/containerd/v2/core/snapshots"
 	"github.com/containerd/containerd/v2/pkg/oci"
 
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
 	customopts "github.com/containerd/containerd/v2/internal/cri/opts"
 )
 
@@ -51,7 +52,7 @@ const (
 	seccompDefaultProfile = dockerDefault
 )
 
-func (c *criService) containerSpecOpts(config *runtime.ContainerConfig, imageConfig *imagespec.ImageConfig) ([]oci.SpecOpts, error) {
+func (c *criService) containerSpecOpts(config *runtime.ContainerConfig, imageConfig *imagespec.ImageConfig, ociRuntime criconfig.Runtime) ([]oci.SpecOpts, error) {
 	var (
 		specOpts []oci.SpecOpts
 		err      error
@@ -68,6 +69,13 @@ func (c *criService) containerSpecOpts(config *runtime.ContainerConfig, imageCon
 	specOpts = append(specOpts, customopts.WithAdditionalGIDs(userstr),
 		customopts.WithSupplementalGroups(securityContext.GetSupplementalGroups()))
 
+	if ociRuntime.Wasm {
+		// Seccomp and apparmor confine Linux syscalls and file access of
+		// native processes; wasm shims do not run the workload as a native
+		// process, so skip them.
+		return specOpts, nil
+	}
+
 	asp := securityContext.GetApparmor()
 	if asp == nil {
 		asp, err = generateApparmorSecurityProfile(securityContext.GetApparmorProfile()) //nolint:staticcheck // Deprecated but we don't want to remove yet
@@ -33,5 +33,6 @@ func (c *criService) Exec(ctx context.Context, r *runtime.ExecRequest) (*runtime
 	if state != runtime.ContainerState_CONTAINER_RUNNING {
 		return nil, fmt.Errorf("container is in %s state", criContainerStateToString(state))
 	}
+	c.auditExec("exec", cntr, r.GetCmd(), r.GetTty(), 0, nil)
 	return c.streamServer.GetExec(r)
 }
@@ -82,6 +82,7 @@ func (c *criService) ExecSync(ctx context.Context, r *runtime.ExecSyncRequest) (
 	cout := &cappedWriter{w: cioutil.NewNopWriteCloser(&stdout), remain: maxStreamSize}
 	cerr := &cappedWriter{w: cioutil.NewNopWriteCloser(&stderr), remain: maxStreamSize}
 
+	start := time.Now()
 	exitCode, err := c.execInContainer(ctx, r.GetContainerId(), execOptions{
 		cmd:     r.GetCmd(),
 		stdout:  cout,
@@ -92,6 +93,11 @@ func (c *criService) ExecSync(ctx context.Context, r *runtime.ExecSyncRequest) (
 		return nil, fmt.Errorf("failed to exec in container: %w", err)
 	}
 
+	if cntr, err := c.containerStore.Get(r.GetContainerId()); err == nil {
+		code := int32(*exitCode)
+		c.auditExec("exec-sync", cntr, r.GetCmd(), false, time.Since(start), &code)
+	}
+
 	return &runtime.ExecSyncResponse{
 		Stdout:   stdout.Bytes(),
 		Stderr:   stderr.Bytes(),
@@ -0,0 +1,163 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+)
+
+// applyMountOverrides applies the per-mount annotation overrides to the
+// container's CRI mounts: subpath mounting, propagation policy and recursive
+// read-only. Overrides naming a container path without a matching mount are
+// rejected, so typos don't silently change nothing. Capability validation of
+// the resulting mounts (e.g. recursive read-only support of the runtime
+// handler and kernel) happens when the mounts are turned into spec mounts.
+func (c *criService) applyMountOverrides(config *runtime.ContainerConfig) error {
+	anns := config.GetAnnotations()
+	subPaths, err := parseMountOverrides(anns[annotations.MountSubPaths])
+	if err != nil {
+		return fmt.Errorf("invalid %q annotation: %w", annotations.MountSubPaths, err)
+	}
+	propagations, err := parseMountOverrides(anns[annotations.MountPropagations])
+	if err != nil {
+		return fmt.Errorf("invalid %q annotation: %w", annotations.MountPropagations, err)
+	}
+	rroPaths := parseMountPathList(anns[annotations.MountRecursiveReadOnly])
+	if len(subPaths) == 0 && len(propagations) == 0 && len(rroPaths) == 0 {
+		return nil
+	}
+
+	for _, m := range config.GetMounts() {
+		dst := filepath.Clean(m.GetContainerPath())
+		if subPath, ok := subPaths[dst]; ok {
+			src, err := c.resolveMountSubPath(m.GetHostPath(), subPath)
+			if err != nil {
+				return fmt.Errorf("invalid %q annotation: %w", annotations.MountSubPaths, err)
+			}
+			m.HostPath = src
+			delete(subPaths, dst)
+		}
+		if policy, ok := propagations[dst]; ok {
+			propagation, err := parseMountPropagation(policy)
+			if err != nil {
+				return fmt.Errorf("invalid %q annotation: %w", annotations.MountPropagations, err)
+			}
+			m.Propagation = propagation
+			delete(propagations, dst)
+		}
+		if _, ok := rroPaths[dst]; ok {
+			m.RecursiveReadOnly = true
+			delete(rroPaths, dst)
+		}
+	}
+
+	for dst := range subPaths {
+		return fmt.Errorf("invalid %q annotation: no mount with container path %q", annotations.MountSubPaths, dst)
+	}
+	for dst := range propagations {
+		return fmt.Errorf("invalid %q annotation: no mount with container path %q", annotations.MountPropagations, dst)
+	}
+	for dst := range rroPaths {
+		return fmt.Errorf("invalid %q annotation: no mount with container path %q", annotations.MountRecursiveReadOnly, dst)
+	}
+	return nil
+}
+
+// resolveMountSubPath resolves subPath under the mount's host path. Symlink
+// traversal is confined to the host path, so a symlink inside the volume
+// cannot redirect the mount onto an arbitrary host path.
+func (c *criService) resolveMountSubPath(hostPath, subPath string) (string, error) {
+	if filepath.IsAbs(subPath) {
+		return "", fmt.Errorf("subpath %q must be relative", subPath)
+	}
+	for _, part := range strings.Split(filepath.Clean(subPath), string(filepath.Separator)) {
+		if part == ".." {
+			return "", fmt.Errorf("subpath %q must not contain %q", subPath, "..")
+		}
+	}
+	// Create the host path if it doesn't exist, like the mount construction
+	// does, so the subpath can be resolved under the real host path.
+	if _, err := c.os.Stat(hostPath); err != nil {
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to stat %q: %w", hostPath, err)
+		}
+		if err := c.os.MkdirAll(hostPath, 0755); err != nil {
+			return "", fmt.Errorf("failed to mkdir %q: %w", hostPath, err)
+		}
+	}
+	resolved, err := c.os.ResolveSymbolicLink(hostPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlink %q: %w", hostPath, err)
+	}
+	src, err := c.os.FollowSymlinkInScope(filepath.Join(resolved, subPath), resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve subpath %q under %q: %w", subPath, resolved, err)
+	}
+	return src, nil
+}
+
+// parseMountOverrides parses a comma separated list of "containerPath=value"
+// annotation entries into a map keyed by the cleaned container path.
+func parseMountOverrides(ann string) (map[string]string, error) {
+	if ann == "" {
+		return nil, nil
+	}
+	overrides := make(map[string]string)
+	for _, entry := range strings.Split(ann, ",") {
+		path, value, ok := strings.Cut(entry, "=")
+		if !ok || path == "" || value == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected \"containerPath=value\"", entry)
+		}
+		overrides[filepath.Clean(path)] = value
+	}
+	return overrides, nil
+}
+
+// parseMountPathList parses a comma separated list of container paths into a
+// set keyed by the cleaned path.
+func parseMountPathList(ann string) map[string]struct{} {
+	if ann == "" {
+		return nil
+	}
+	paths := make(map[string]struct{})
+	for _, path := range strings.Split(ann, ",") {
+		paths[filepath.Clean(path)] = struct{}{}
+	}
+	return paths
+}
+
+// parseMountPropagation maps a propagation policy name to the CRI mount
+// propagation mode.
+func parseMountPropagation(policy string) (runtime.MountPropagation, error) {
+	switch policy {
+	case "rprivate":
+		return runtime.MountPropagation_PROPAGATION_PRIVATE, nil
+	case "rslave":
+		return runtime.MountPropagation_PROPAGATION_HOST_TO_CONTAINER, nil
+	case "rshared":
+		return runtime.MountPropagation_PROPAGATION_BIDIRECTIONAL, nil
+	default:
+		return 0, fmt.Errorf("unknown propagation policy %q, expected \"rprivate\", \"rslave\" or \"rshared\"", policy)
+	}
+}
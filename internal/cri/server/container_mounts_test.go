@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+)
+
+func TestApplyMountOverrides(t *testing.T) {
+	newConfig := func(anns map[string]string) *runtime.ContainerConfig {
+		return &runtime.ContainerConfig{
+			Mounts: []*runtime.Mount{
+				{
+					ContainerPath: "/etc/config",
+					HostPath:      "/var/lib/kubelet/volume",
+					Readonly:      true,
+				},
+				{
+					ContainerPath: "/var/lib/kubelet",
+					HostPath:      "/var/lib/kubelet",
+				},
+			},
+			Annotations: anns,
+		}
+	}
+
+	t.Run("no overrides", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(nil)
+		require.NoError(t, c.applyMountOverrides(config))
+		assert.Equal(t, "/var/lib/kubelet/volume", config.Mounts[0].HostPath)
+		assert.Equal(t, runtime.MountPropagation_PROPAGATION_PRIVATE, config.Mounts[1].Propagation)
+	})
+
+	t.Run("subpath is resolved under the host path", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountSubPaths: "/etc/config=prod",
+		})
+		require.NoError(t, c.applyMountOverrides(config))
+		assert.Equal(t, "/var/lib/kubelet/volume/prod", config.Mounts[0].HostPath)
+	})
+
+	t.Run("subpath must not escape", func(t *testing.T) {
+		c := newTestCRIService()
+		for _, subPath := range []string{"../outside", "prod/../../outside", "/abs"} {
+			config := newConfig(map[string]string{
+				annotations.MountSubPaths: "/etc/config=" + subPath,
+			})
+			assert.Error(t, c.applyMountOverrides(config), subPath)
+		}
+	})
+
+	t.Run("propagation override", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountPropagations: "/var/lib/kubelet=rslave",
+		})
+		require.NoError(t, c.applyMountOverrides(config))
+		assert.Equal(t, runtime.MountPropagation_PROPAGATION_HOST_TO_CONTAINER, config.Mounts[1].Propagation)
+	})
+
+	t.Run("unknown propagation policy", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountPropagations: "/var/lib/kubelet=shared",
+		})
+		assert.ErrorContains(t, c.applyMountOverrides(config), "unknown propagation policy")
+	})
+
+	t.Run("recursive read-only override", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountRecursiveReadOnly: "/etc/config",
+		})
+		require.NoError(t, c.applyMountOverrides(config))
+		assert.True(t, config.Mounts[0].RecursiveReadOnly)
+		assert.False(t, config.Mounts[1].RecursiveReadOnly)
+	})
+
+	t.Run("override without matching mount", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountSubPaths: "/etc/confg=prod",
+		})
+		assert.ErrorContains(t, c.applyMountOverrides(config), "no mount with container path")
+	})
+
+	t.Run("malformed entry", func(t *testing.T) {
+		c := newTestCRIService()
+		config := newConfig(map[string]string{
+			annotations.MountPropagations: "/var/lib/kubelet",
+		})
+		assert.ErrorContains(t, c.applyMountOverrides(config), "expected")
+	})
+}
@@ -24,6 +24,7 @@ import (
 
 	containerd "github.com/containerd/containerd/v2/client"
 	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+	"github.com/containerd/containerd/v2/pkg/coredump"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
@@ -82,8 +83,8 @@ func (c *criService) RemoveContainer(ctx context.Context, r *runtime.RemoveConta
 		}
 	}()
 
-	sandbox, err := c.sandboxStore.Get(container.SandboxID)
-	if err != nil {
+	sandbox, sandboxErr := c.sandboxStore.Get(container.SandboxID)
+	if sandboxErr != nil {
 		err = c.nri.RemoveContainer(ctx, nil, &container)
 	} else {
 		err = c.nri.RemoveContainer(ctx, &sandbox, &container)
@@ -121,10 +122,28 @@ func (c *criService) RemoveContainer(ctx context.Context, r *runtime.RemoveConta
 			volatileContainerRootDir, err)
 	}
 
+	if sandboxErr == nil {
+		if ociRuntime, err := c.config.GetSandboxRuntime(sandbox.Config, sandbox.Metadata.RuntimeHandler); err == nil && ociRuntime.CoreDumpPath != "" {
+			if err := coredump.CleanupContainer(ociRuntime.CoreDumpPath, sandbox.ID, id); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to remove core dumps of container %q", id)
+			}
+		}
+	}
+
+	// Return the container's exclusive CPUs, if any, to the pool.
+	if c.cpusetAllocator != nil {
+		c.cpusetAllocator.Release(id)
+	}
+
 	c.containerStore.Delete(id)
+	c.statsCache.evictContainer(id)
 
 	c.containerNameIndex.ReleaseByKey(id)
 
+	// Shrink the pod-level cgroup to the remaining containers when containerd
+	// manages pod cgroups.
+	c.reconcilePodCgroup(container.SandboxID)
+
 	c.generateAndSendContainerEvent(ctx, id, container.SandboxID, runtime.ContainerEventType_CONTAINER_DELETED_EVENT)
 
 	containerRemoveTimer.WithValues(i.Runtime.Name).UpdateSince(start)
@@ -0,0 +1,94 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/pkg/archive"
+	"github.com/containerd/containerd/v2/plugins"
+	"github.com/containerd/log"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+)
+
+// prepareContainerRestore unpacks the checkpoint archive written by
+// CheckpointContainer, applies the checkpointed rw layer on top of the
+// container's snapshot, and returns the task options restoring the task
+// from the CRIU image data instead of running the container fresh.
+func (c *criService) prepareContainerRestore(ctx context.Context, cntr containerstore.Container, ociRuntime criconfig.Runtime, checkpointPath string) ([]containerd.NewTaskOpts, error) {
+	if err := checkForCriu(podCriuVersion); err != nil {
+		return nil, err
+	}
+	if ociRuntime.Type != plugins.RuntimeRuncV2 {
+		return nil, fmt.Errorf("container restore requires the %q runtime, got %q", plugins.RuntimeRuncV2, ociRuntime.Type)
+	}
+
+	f, err := os.Open(checkpointPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint archive: %w", err)
+	}
+	defer f.Close()
+
+	restoreDir := filepath.Join(c.getContainerRootDir(cntr.ID), "restore")
+	if err := os.MkdirAll(restoreDir, 0o700); err != nil {
+		return nil, err
+	}
+	if _, err := archive.Apply(ctx, restoreDir, f); err != nil {
+		return nil, fmt.Errorf("failed to unpack checkpoint archive %q: %w", checkpointPath, err)
+	}
+
+	imageDir := filepath.Join(restoreDir, crmetadata.CheckpointDirectory)
+	if _, err := os.Stat(imageDir); err != nil {
+		return nil, fmt.Errorf("checkpoint archive %q contains no CRIU image data: %w", checkpointPath, err)
+	}
+
+	// Apply the rw layer dumped at checkpoint time on top of the
+	// container's snapshot so the restored process finds its files.
+	diff, err := os.Open(filepath.Join(restoreDir, crmetadata.RootFsDiffTar))
+	if err == nil {
+		defer diff.Close()
+		sn := c.client.SnapshotService(c.RuntimeSnapshotter(ctx, ociRuntime))
+		mounts, err := sn.Mounts(ctx, cntr.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get container snapshot mounts: %w", err)
+		}
+		if err := mount.WithTempMount(ctx, mounts, func(root string) error {
+			_, err := archive.Apply(ctx, root, diff)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to apply checkpointed rw layer: %w", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	log.G(ctx).Infof("Restoring container %q from checkpoint %q", cntr.ID, checkpointPath)
+	return []containerd.NewTaskOpts{
+		containerd.WithRestoreImagePath(imageDir),
+		containerd.WithRestoreWorkPath(c.getContainerRootDir(cntr.ID)),
+	}, nil
+}
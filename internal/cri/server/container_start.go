@@ -28,6 +28,7 @@ import (
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
 	cio "github.com/containerd/containerd/v2/internal/cri/io"
 	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
 	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
@@ -88,6 +89,12 @@ func (c *criService) StartContainer(ctx context.Context, r *runtime.StartContain
 		return nil, fmt.Errorf("sandbox container %q is not running", sandboxID)
 	}
 
+	// Honor sidecar-first start ordering declared through annotations before
+	// creating the task.
+	if err := c.waitForStartDependencies(ctx, cntr); err != nil {
+		return nil, err
+	}
+
 	// Recheck target container validity in Linux namespace options.
 	if linux := config.GetLinux(); linux != nil {
 		nsOpts := linux.GetSecurityContext().GetNamespaceOptions()
@@ -118,6 +125,13 @@ func (c *criService) StartContainer(ctx context.Context, r *runtime.StartContain
 	if ociRuntime.Path != "" {
 		taskOpts = append(taskOpts, containerd.WithRuntimePath(ociRuntime.Path))
 	}
+	if checkpoint := config.GetAnnotations()[annotations.RestoreCheckpoint]; checkpoint != "" {
+		restoreOpts, err := c.prepareContainerRestore(ctx, cntr, ociRuntime, checkpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare restore of container %q: %w", id, err)
+		}
+		taskOpts = append(taskOpts, restoreOpts...)
+	}
 	task, err := container.NewTask(ctx, ioCreation, taskOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create containerd task: %w", err)
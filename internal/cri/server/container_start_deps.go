@@ -0,0 +1,144 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/containerd/log"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+)
+
+const (
+	// defaultStartWaitTimeout bounds how long StartContainer waits for start
+	// dependencies unless overridden by the StartWaitTimeout annotation.
+	defaultStartWaitTimeout = 30 * time.Second
+	// startWaitPollInterval is how often start dependencies are re-checked.
+	startWaitPollInterval = 100 * time.Millisecond
+)
+
+// startDependencies are the conditions a container awaits before starting,
+// parsed from its annotations.
+type startDependencies struct {
+	// containers are names of containers in the same pod which must be
+	// running first.
+	containers []string
+	// readinessFile, when non-empty, is a file whose existence is awaited.
+	readinessFile string
+	// timeout bounds the overall wait.
+	timeout time.Duration
+}
+
+// parseStartDependencies extracts start ordering annotations of a container.
+// It returns nil when the container declares no dependencies.
+func parseStartDependencies(config *runtime.ContainerConfig) (*startDependencies, error) {
+	var (
+		anno = config.GetAnnotations()
+		deps = startDependencies{
+			readinessFile: anno[annotations.StartReadinessFile],
+			timeout:       defaultStartWaitTimeout,
+		}
+	)
+	for _, name := range strings.Split(anno[annotations.StartAfter], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			deps.containers = append(deps.containers, name)
+		}
+	}
+	if len(deps.containers) == 0 && deps.readinessFile == "" {
+		return nil, nil
+	}
+	if t := anno[annotations.StartWaitTimeout]; t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse annotation %q: %w", annotations.StartWaitTimeout, err)
+		}
+		deps.timeout = timeout
+	}
+	return &deps, nil
+}
+
+// waitForStartDependencies delays the start of a container until the sidecar
+// containers named in its StartAfter annotation are running and its readiness
+// file, if any, exists. It returns an error when the dependencies are not
+// satisfied within the configured timeout, failing the start.
+func (c *criService) waitForStartDependencies(ctx context.Context, cntr containerstore.Container) error {
+	deps, err := parseStartDependencies(cntr.Config)
+	if err != nil {
+		return err
+	}
+	if deps == nil {
+		return nil
+	}
+	log.G(ctx).Infof("Delaying start of container %q until dependencies are ready: containers %v, readiness file %q",
+		cntr.ID, deps.containers, deps.readinessFile)
+
+	ctx, cancel := context.WithTimeout(ctx, deps.timeout)
+	defer cancel()
+	ticker := time.NewTicker(startWaitPollInterval)
+	defer ticker.Stop()
+	for {
+		if pending := c.pendingStartDependencies(cntr.SandboxID, deps); len(pending) == 0 {
+			log.G(ctx).Debugf("Start dependencies of container %q are ready", cntr.ID)
+			return nil
+		} else {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return fmt.Errorf("waiting for start dependencies of container %q (pending: %s): %w",
+					cntr.ID, strings.Join(pending, ", "), ctx.Err())
+			}
+		}
+	}
+}
+
+// pendingStartDependencies returns a description of each unsatisfied start
+// dependency, or nil when all are satisfied.
+func (c *criService) pendingStartDependencies(sandboxID string, deps *startDependencies) []string {
+	var pending []string
+	for _, name := range deps.containers {
+		if !c.containerRunningInSandbox(sandboxID, name) {
+			pending = append(pending, fmt.Sprintf("container %q not running", name))
+		}
+	}
+	if deps.readinessFile != "" {
+		if _, err := os.Stat(deps.readinessFile); err != nil {
+			pending = append(pending, fmt.Sprintf("readiness file %q not present", deps.readinessFile))
+		}
+	}
+	return pending
+}
+
+// containerRunningInSandbox reports whether a container with the given name
+// exists in the sandbox and is running.
+func (c *criService) containerRunningInSandbox(sandboxID, name string) bool {
+	for _, cntr := range c.containerStore.List() {
+		if cntr.SandboxID != sandboxID || cntr.Config.GetMetadata().GetName() != name {
+			continue
+		}
+		if cntr.Status.Get().State() == runtime.ContainerState_CONTAINER_RUNNING {
+			return true
+		}
+	}
+	return false
+}
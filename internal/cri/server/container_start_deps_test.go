@@ -0,0 +1,182 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+)
+
+func TestParseStartDependencies(t *testing.T) {
+	for _, test := range []struct {
+		desc        string
+		annotations map[string]string
+		expected    *startDependencies
+		expectErr   bool
+	}{
+		{
+			desc: "no annotations",
+		},
+		{
+			desc: "start-after list",
+			annotations: map[string]string{
+				annotations.StartAfter: "envoy, vault-agent",
+			},
+			expected: &startDependencies{
+				containers: []string{"envoy", "vault-agent"},
+				timeout:    defaultStartWaitTimeout,
+			},
+		},
+		{
+			desc: "readiness file with custom timeout",
+			annotations: map[string]string{
+				annotations.StartReadinessFile: "/run/ready",
+				annotations.StartWaitTimeout:   "5s",
+			},
+			expected: &startDependencies{
+				readinessFile: "/run/ready",
+				timeout:       5 * time.Second,
+			},
+		},
+		{
+			desc: "invalid timeout",
+			annotations: map[string]string{
+				annotations.StartAfter:       "envoy",
+				annotations.StartWaitTimeout: "5",
+			},
+			expectErr: true,
+		},
+		{
+			desc: "timeout alone does not declare a dependency",
+			annotations: map[string]string{
+				annotations.StartWaitTimeout: "5s",
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			deps, err := parseStartDependencies(&runtime.ContainerConfig{
+				Annotations: test.annotations,
+			})
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, deps)
+		})
+	}
+}
+
+func TestWaitForStartDependencies(t *testing.T) {
+	const sandboxID = "test-sandbox-id"
+	readyFile := filepath.Join(t.TempDir(), "ready")
+	require.NoError(t, os.WriteFile(readyFile, nil, 0600))
+
+	newContainer := func(t *testing.T, id, name string, annotations map[string]string, status containerstore.Status) containerstore.Container {
+		cntr, err := containerstore.NewContainer(
+			containerstore.Metadata{
+				ID:        id,
+				SandboxID: sandboxID,
+				Config: &runtime.ContainerConfig{
+					Metadata:    &runtime.ContainerMetadata{Name: name},
+					Annotations: annotations,
+				},
+			},
+			containerstore.WithFakeStatus(status),
+		)
+		require.NoError(t, err)
+		return cntr
+	}
+	running := containerstore.Status{
+		CreatedAt: time.Now().UnixNano(),
+		StartedAt: time.Now().UnixNano(),
+		Pid:       1,
+	}
+	created := containerstore.Status{
+		CreatedAt: time.Now().UnixNano(),
+	}
+
+	for _, test := range []struct {
+		desc          string
+		annotations   map[string]string
+		sidecarStatus *containerstore.Status
+		expectErr     bool
+	}{
+		{
+			desc: "no dependencies",
+		},
+		{
+			desc: "sidecar running",
+			annotations: map[string]string{
+				annotations.StartAfter: "sidecar",
+			},
+			sidecarStatus: &running,
+		},
+		{
+			desc: "sidecar not started before timeout",
+			annotations: map[string]string{
+				annotations.StartAfter:       "sidecar",
+				annotations.StartWaitTimeout: "200ms",
+			},
+			sidecarStatus: &created,
+			expectErr:     true,
+		},
+		{
+			desc: "sidecar missing before timeout",
+			annotations: map[string]string{
+				annotations.StartAfter:       "sidecar",
+				annotations.StartWaitTimeout: "200ms",
+			},
+			expectErr: true,
+		},
+		{
+			desc: "readiness file present",
+			annotations: map[string]string{
+				annotations.StartReadinessFile: readyFile,
+			},
+		},
+		{
+			desc: "readiness file missing before timeout",
+			annotations: map[string]string{
+				annotations.StartReadinessFile: readyFile + ".missing",
+				annotations.StartWaitTimeout:   "200ms",
+			},
+			expectErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			c := newTestCRIService()
+			if test.sidecarStatus != nil {
+				require.NoError(t, c.containerStore.Add(
+					newContainer(t, "sidecar-id", "sidecar", nil, *test.sidecarStatus)))
+			}
+			cntr := newContainer(t, "app-id", "app", test.annotations, created)
+			err := c.waitForStartDependencies(context.Background(), cntr)
+			assert.Equal(t, test.expectErr, err != nil)
+		})
+	}
+}
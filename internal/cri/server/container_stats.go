@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/containerd/containerd/v2/api/services/tasks/v1"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
@@ -31,13 +30,12 @@ func (c *criService) ContainerStats(ctx context.Context, in *runtime.ContainerSt
 	if err != nil {
 		return nil, fmt.Errorf("failed to find container: %w", err)
 	}
-	request := &tasks.MetricsRequest{Filters: []string{"id==" + cntr.ID}}
-	resp, err := c.client.TaskService().Metrics(ctx, request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch metrics for task: %w", err)
+	if cs, ok := c.statsCache.getContainer(cntr.ID); ok {
+		return &runtime.ContainerStatsResponse{Stats: cs}, nil
 	}
-	if len(resp.Metrics) != 1 {
-		return nil, fmt.Errorf("unexpected metrics response: %+v", resp.Metrics)
+	metric, err := c.taskMetric(ctx, cntr.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	handler, err := c.getMetricsHandler(ctx, cntr.SandboxID)
@@ -45,9 +43,10 @@ func (c *criService) ContainerStats(ctx context.Context, in *runtime.ContainerSt
 		return nil, err
 	}
 
-	cs, err := handler(cntr.Metadata, resp.Metrics[0])
+	cs, err := handler(cntr.Metadata, metric)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode container metrics: %w", err)
 	}
+	c.statsCache.putContainer(cs)
 	return &runtime.ContainerStatsResponse{Stats: cs}, nil
 }
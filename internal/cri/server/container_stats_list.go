@@ -47,6 +47,31 @@ func (c *criService) ListContainerStats(
 	if err != nil {
 		return nil, fmt.Errorf("failed to build metrics request: %w", err)
 	}
+
+	// Serve containers with stats within the freshness window from cache
+	// and only query the tasks service for the rest.
+	var cached []*runtime.ContainerStats
+	if c.statsCache != nil {
+		var stale []containerstore.Container
+		for _, cntr := range containers {
+			if cs, ok := c.statsCache.getContainer(cntr.ID); ok {
+				cached = append(cached, cs)
+			} else {
+				stale = append(stale, cntr)
+			}
+		}
+		if len(stale) == 0 {
+			return &runtime.ListContainerStatsResponse{Stats: cached}, nil
+		}
+		if len(cached) > 0 {
+			containers = stale
+			request = &tasks.MetricsRequest{}
+			for _, cntr := range stale {
+				request.Filters = append(request.Filters, "id=="+cntr.ID)
+			}
+		}
+	}
+
 	resp, err := c.client.TaskService().Metrics(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch metrics for tasks: %w", err)
@@ -55,6 +80,10 @@ func (c *criService) ListContainerStats(
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert to cri containerd stats format: %w", err)
 	}
+	for _, cs := range criStats.Stats {
+		c.statsCache.putContainer(cs)
+	}
+	criStats.Stats = append(criStats.Stats, cached...)
 	return criStats, nil
 }
 
@@ -70,7 +70,11 @@ func (c *criService) ContainerStatus(ctx context.Context, r *runtime.ContainerSt
 		status.CreatedAt = info.CreatedAt.UnixNano()
 	}
 
-	info, err := toCRIContainerInfo(ctx, container, r.GetVerbose())
+	var exclusiveCPUs string
+	if c.cpusetAllocator != nil {
+		exclusiveCPUs, _ = c.cpusetAllocator.Get(container.ID)
+	}
+	info, err := toCRIContainerInfo(ctx, container, exclusiveCPUs, r.GetVerbose())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get verbose container info: %w", err)
 	}
@@ -136,10 +140,13 @@ type ContainerInfo struct {
 	RuntimeOptions interface{}              `json:"runtimeOptions"`
 	Config         *runtime.ContainerConfig `json:"config"`
 	RuntimeSpec    *runtimespec.Spec        `json:"runtimeSpec"`
+	// ExclusiveCPUs is the container's exclusive CPU assignment in cpuset
+	// list format, empty when the container has none.
+	ExclusiveCPUs string `json:"exclusiveCPUs,omitempty"`
 }
 
 // toCRIContainerInfo converts internal container object information to CRI container status response info map.
-func toCRIContainerInfo(ctx context.Context, container containerstore.Container, verbose bool) (map[string]string, error) {
+func toCRIContainerInfo(ctx context.Context, container containerstore.Container, exclusiveCPUs string, verbose bool) (map[string]string, error) {
 	if !verbose {
 		return nil, nil
 	}
@@ -149,10 +156,11 @@ func toCRIContainerInfo(ctx context.Context, container containerstore.Container,
 
 	// TODO(random-liu): Change CRI status info to use array instead of map.
 	ci := &ContainerInfo{
-		SandboxID: container.SandboxID,
-		Pid:       status.Pid,
-		Removing:  status.Removing,
-		Config:    meta.Config,
+		SandboxID:     container.SandboxID,
+		Pid:           status.Pid,
+		Removing:      status.Removing,
+		Config:        meta.Config,
+		ExclusiveCPUs: exclusiveCPUs,
 	}
 
 	var err error
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	imagesservice "github.com/containerd/containerd/v2/internal/cri/server/images"
 	snapshotstore "github.com/containerd/containerd/v2/internal/cri/store/snapshot"
 	"github.com/stretchr/testify/assert"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
@@ -177,6 +178,7 @@ func TestToCRIContainerInfo(t *testing.T) {
 
 	info, err := toCRIContainerInfo(context.Background(),
 		container,
+		"",
 		false)
 	assert.NoError(t, err)
 	assert.Nil(t, info)
@@ -289,6 +291,18 @@ func (s *fakeImageService) LocalResolve(refOrID string) (imagestore.Image, error
 
 func (s *fakeImageService) ImageFSPaths() map[string]string { return make(map[string]string) }
 
+func (s *fakeImageService) RegistryReloadStatus() ([]string, bool, error) { return nil, false, nil }
+
+func (s *fakeImageService) ImageGCStatus() (imagesservice.ImageGCStatus, bool) {
+	return imagesservice.ImageGCStatus{}, false
+}
+
+func (s *fakeImageService) TriggerImageGC() {}
+
+func (s *fakeImageService) RecordImageExit(imageID string) {}
+
+func (s *fakeImageService) ActivePullProgress() []imagesservice.PullProgress { return nil }
+
 func (s *fakeImageService) PullImage(context.Context, string, func(string) (string, string, error), *runtime.PodSandboxConfig, string) (string, error) {
 	return "", errors.New("not implemented")
 }
@@ -0,0 +1,113 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	runtimespec "github.com/opencontainers/runtime-spec/specs-go"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+)
+
+// containerUlimits merges the runtime's default ulimits with pod annotation
+// overrides. Overrides are only honored for types listed in the runtime's
+// `max_ulimits` and must stay within those maxima.
+func containerUlimits(ociRuntime criconfig.Runtime, sandboxConfig *runtime.PodSandboxConfig) ([]runtimespec.POSIXRlimit, error) {
+	merged := make(map[string]criconfig.Ulimit)
+	var order []string
+	for _, u := range ociRuntime.DefaultUlimits {
+		if _, ok := merged[u.Type]; !ok {
+			order = append(order, u.Type)
+		}
+		merged[u.Type] = u
+	}
+
+	if ann := sandboxConfig.GetAnnotations()[annotations.Ulimits]; ann != "" {
+		overrides, err := parseUlimitAnnotation(ann)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q annotation: %w", annotations.Ulimits, err)
+		}
+		for _, u := range overrides {
+			max, ok := maxUlimit(ociRuntime.MaxUlimits, u.Type)
+			if !ok {
+				return nil, fmt.Errorf("ulimit override %q is not permitted by the runtime's `max_ulimits`", u.Type)
+			}
+			if u.Hard > max.Hard || u.Soft > max.Soft {
+				return nil, fmt.Errorf("ulimit override %q (%d:%d) exceeds the runtime's maximum (%d:%d)", u.Type, u.Soft, u.Hard, max.Soft, max.Hard)
+			}
+			if _, ok := merged[u.Type]; !ok {
+				order = append(order, u.Type)
+			}
+			merged[u.Type] = u
+		}
+	}
+
+	var rlimits []runtimespec.POSIXRlimit
+	for _, t := range order {
+		u := merged[t]
+		rlimits = append(rlimits, runtimespec.POSIXRlimit{
+			Type: "RLIMIT_" + strings.ToUpper(u.Type),
+			Hard: u.Hard,
+			Soft: u.Soft,
+		})
+	}
+	return rlimits, nil
+}
+
+// parseUlimitAnnotation parses a comma separated list of "type=soft:hard"
+// ulimit entries.
+func parseUlimitAnnotation(value string) ([]criconfig.Ulimit, error) {
+	var ulimits []criconfig.Ulimit
+	for _, entry := range strings.Split(value, ",") {
+		typ, limits, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not of the form `type=soft:hard`", entry)
+		}
+		softStr, hardStr, ok := strings.Cut(limits, ":")
+		if !ok {
+			return nil, fmt.Errorf("entry %q is not of the form `type=soft:hard`", entry)
+		}
+		soft, err := strconv.ParseUint(softStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid soft limit in entry %q: %w", entry, err)
+		}
+		hard, err := strconv.ParseUint(hardStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hard limit in entry %q: %w", entry, err)
+		}
+		u := criconfig.Ulimit{Type: typ, Hard: hard, Soft: soft}
+		if err := criconfig.ValidateUlimits([]criconfig.Ulimit{u}); err != nil {
+			return nil, err
+		}
+		ulimits = append(ulimits, u)
+	}
+	return ulimits, nil
+}
+
+func maxUlimit(maxima []criconfig.Ulimit, typ string) (criconfig.Ulimit, bool) {
+	for _, m := range maxima {
+		if m.Type == typ {
+			return m, true
+		}
+	}
+	return criconfig.Ulimit{}, false
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+)
+
+func TestContainerUlimits(t *testing.T) {
+	ociRuntime := criconfig.Runtime{
+		DefaultUlimits: []criconfig.Ulimit{
+			{Type: "nofile", Soft: 1024, Hard: 4096},
+		},
+		MaxUlimits: []criconfig.Ulimit{
+			{Type: "nofile", Soft: 8192, Hard: 16384},
+		},
+	}
+	for _, test := range []struct {
+		desc        string
+		annotations map[string]string
+		expected    map[string][2]uint64 // type -> {soft, hard}
+		expectErr   bool
+	}{
+		{
+			desc:     "defaults without overrides",
+			expected: map[string][2]uint64{"RLIMIT_NOFILE": {1024, 4096}},
+		},
+		{
+			desc:        "override within maxima",
+			annotations: map[string]string{annotations.Ulimits: "nofile=2048:8192"},
+			expected:    map[string][2]uint64{"RLIMIT_NOFILE": {2048, 8192}},
+		},
+		{
+			desc:        "override exceeding maxima",
+			annotations: map[string]string{annotations.Ulimits: "nofile=8192:65536"},
+			expectErr:   true,
+		},
+		{
+			desc:        "override of type without maximum",
+			annotations: map[string]string{annotations.Ulimits: "memlock=1024:1024"},
+			expectErr:   true,
+		},
+		{
+			desc:        "unknown ulimit type",
+			annotations: map[string]string{annotations.Ulimits: "notalimit=1:1"},
+			expectErr:   true,
+		},
+		{
+			desc:        "malformed entry",
+			annotations: map[string]string{annotations.Ulimits: "nofile=1024"},
+			expectErr:   true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			sandboxConfig := &runtime.PodSandboxConfig{Annotations: test.annotations}
+			rlimits, err := containerUlimits(ociRuntime, sandboxConfig)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rlimits, len(test.expected))
+			for _, rl := range rlimits {
+				expected, ok := test.expected[rl.Type]
+				require.True(t, ok, "unexpected rlimit %q", rl.Type)
+				assert.Equal(t, expected[0], rl.Soft)
+				assert.Equal(t, expected[1], rl.Hard)
+			}
+		})
+	}
+}
@@ -29,6 +29,10 @@ import (
 	criopts "github.com/containerd/containerd/v2/internal/cri/opts"
 )
 
+func blkioWeight(w uint16) *uint16 {
+	return &w
+}
+
 func TestUpdateOCILinuxResource(t *testing.T) {
 	oomscoreadj := new(int)
 	*oomscoreadj = -500
@@ -235,6 +239,49 @@ func TestUpdateOCILinuxResource(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "should translate extended resources from the unified map",
+			spec: &runtimespec.Spec{
+				Process: &runtimespec.Process{OOMScoreAdj: oomscoreadj},
+				Linux: &runtimespec.Linux{
+					Resources: &runtimespec.LinuxResources{
+						Memory: &runtimespec.LinuxMemory{Limit: proto.Int64(12345)},
+						CPU: &runtimespec.LinuxCPU{
+							Shares: proto.Uint64(1111),
+							Quota:  proto.Int64(2222),
+							Period: proto.Uint64(3333),
+						},
+					},
+				},
+			},
+			request: &runtime.UpdateContainerResourcesRequest{
+				Linux: &runtime.LinuxContainerResources{
+					Unified: map[string]string{
+						"cpu.max.burst": "20000",
+						"blkio.weight":  "300",
+						"memory.min":    "65536",
+					},
+				},
+			},
+			expected: &runtimespec.Spec{
+				Process: &runtimespec.Process{OOMScoreAdj: oomscoreadj},
+				Linux: &runtimespec.Linux{
+					Resources: &runtimespec.LinuxResources{
+						Memory: &runtimespec.LinuxMemory{Limit: proto.Int64(12345)},
+						CPU: &runtimespec.LinuxCPU{
+							Shares: proto.Uint64(1111),
+							Quota:  proto.Int64(2222),
+							Period: proto.Uint64(3333),
+							Burst:  proto.Uint64(20000),
+						},
+						BlockIO: &runtimespec.LinuxBlockIO{
+							Weight: blkioWeight(300),
+						},
+						Unified: map[string]string{"memory.min": "65536"},
+					},
+				},
+			},
+		},
 	} {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
@@ -0,0 +1,300 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containerd/log"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+)
+
+// cpusetStateFile is the file under the CRI root directory persisting
+// exclusive CPU assignments across containerd restarts.
+const cpusetStateFile = "cpuset_state.json"
+
+// cpusetAllocator hands out exclusive CPUs from a configured pool to
+// containers of Guaranteed pods with integer CPU requests, in the style of
+// the kubelet's static CPU manager policy. Assignments are persisted to a
+// state file so they survive containerd restarts.
+type cpusetAllocator struct {
+	mu          sync.Mutex
+	pool        []int
+	assignments map[string][]int
+	stateFile   string
+}
+
+// cpusetState is the serialized form of the allocator's assignments, keyed by
+// container id with cpuset list format values.
+type cpusetState struct {
+	Assignments map[string]string `json:"assignments"`
+}
+
+// newCPUSetAllocator creates an allocator handing out exclusive CPUs from
+// pool (in cpuset list format, e.g. "4-15,20"), restoring previous
+// assignments from the state file in stateDir.
+func newCPUSetAllocator(pool, stateDir string) (*cpusetAllocator, error) {
+	cpus, err := parseCPUSet(pool)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclusive cpuset pool %q: %w", pool, err)
+	}
+	a := &cpusetAllocator{
+		pool:        cpus,
+		assignments: make(map[string][]int),
+		stateFile:   filepath.Join(stateDir, cpusetStateFile),
+	}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *cpusetAllocator) load() error {
+	data, err := os.ReadFile(a.stateFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cpuset state: %w", err)
+	}
+	var state cpusetState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse cpuset state %q: %w", a.stateFile, err)
+	}
+	for id, set := range state.Assignments {
+		cpus, err := parseCPUSet(set)
+		if err != nil {
+			return fmt.Errorf("failed to parse cpuset state %q: %w", a.stateFile, err)
+		}
+		a.assignments[id] = cpus
+	}
+	return nil
+}
+
+// save persists the assignments, it must be called with the lock held.
+func (a *cpusetAllocator) save() error {
+	state := cpusetState{Assignments: make(map[string]string, len(a.assignments))}
+	for id, cpus := range a.assignments {
+		state.Assignments[id] = formatCPUSet(cpus)
+	}
+	data, err := json.Marshal(&state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cpuset state: %w", err)
+	}
+	tmp := a.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cpuset state: %w", err)
+	}
+	if err := os.Rename(tmp, a.stateFile); err != nil {
+		return fmt.Errorf("failed to commit cpuset state: %w", err)
+	}
+	return nil
+}
+
+// Allocate assigns count exclusive CPUs to the container and returns them in
+// cpuset list format. Allocating again for the same container returns its
+// existing assignment.
+func (a *cpusetAllocator) Allocate(id string, count int) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if cpus, ok := a.assignments[id]; ok {
+		return formatCPUSet(cpus), nil
+	}
+
+	used := make(map[int]struct{})
+	for _, cpus := range a.assignments {
+		for _, cpu := range cpus {
+			used[cpu] = struct{}{}
+		}
+	}
+	var free []int
+	for _, cpu := range a.pool {
+		if _, ok := used[cpu]; !ok {
+			free = append(free, cpu)
+		}
+	}
+	if len(free) < count {
+		return "", fmt.Errorf("not enough exclusive CPUs: requested %d, %d available in pool %q", count, len(free), formatCPUSet(a.pool))
+	}
+
+	cpus := free[:count]
+	a.assignments[id] = cpus
+	if err := a.save(); err != nil {
+		delete(a.assignments, id)
+		return "", err
+	}
+	return formatCPUSet(cpus), nil
+}
+
+// Release returns the container's exclusive CPUs to the pool. Releasing a
+// container without an assignment is a no-op.
+func (a *cpusetAllocator) Release(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.assignments[id]; !ok {
+		return
+	}
+	delete(a.assignments, id)
+	if err := a.save(); err != nil {
+		log.L.WithError(err).WithField("container", id).Error("Failed to persist cpuset state")
+	}
+}
+
+// Get returns the container's exclusive CPUs in cpuset list format.
+func (a *cpusetAllocator) Get(id string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cpus, ok := a.assignments[id]
+	if !ok {
+		return "", false
+	}
+	return formatCPUSet(cpus), true
+}
+
+// prune drops assignments of containers which no longer exist, e.g. removed
+// while containerd was down.
+func (a *cpusetAllocator) prune(exists func(id string) bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	changed := false
+	for id := range a.assignments {
+		if !exists(id) {
+			delete(a.assignments, id)
+			changed = true
+		}
+	}
+	if changed {
+		if err := a.save(); err != nil {
+			log.L.WithError(err).Error("Failed to persist cpuset state")
+		}
+	}
+}
+
+// exclusiveCPUCount returns the number of exclusive CPUs the container asks
+// for: the explicit annotation when present, and otherwise the container's
+// integer CPU limit when request and limit pin the same whole CPUs, which is
+// how the kubelet expresses Guaranteed pods with integer CPU requests.
+func exclusiveCPUCount(config *runtime.ContainerConfig) (int, error) {
+	if v := config.GetAnnotations()[annotations.ExclusiveCPUCount]; v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil || count <= 0 {
+			return 0, fmt.Errorf("invalid %q annotation %q: expected a positive integer", annotations.ExclusiveCPUCount, v)
+		}
+		return count, nil
+	}
+	res := config.GetLinux().GetResources()
+	quota, period, shares := res.GetCpuQuota(), res.GetCpuPeriod(), res.GetCpuShares()
+	if quota <= 0 || period <= 0 || quota%period != 0 {
+		return 0, nil
+	}
+	count := quota / period
+	// The kubelet maps a CPU request to 1024 shares per CPU, so a Guaranteed
+	// pod requesting its integer limit carries exactly limit*1024 shares.
+	if shares != count*1024 {
+		return 0, nil
+	}
+	return int(count), nil
+}
+
+// cpusWithin reports whether every CPU of set is part of superset, both in
+// cpuset list format. Unparsable sets are not within anything.
+func cpusWithin(set, superset string) bool {
+	cpus, err := parseCPUSet(set)
+	if err != nil {
+		return false
+	}
+	super, err := parseCPUSet(superset)
+	if err != nil {
+		return false
+	}
+	available := make(map[int]struct{}, len(super))
+	for _, cpu := range super {
+		available[cpu] = struct{}{}
+	}
+	for _, cpu := range cpus {
+		if _, ok := available[cpu]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCPUSet parses a cpuset list format string, e.g. "0-3,8,10-11".
+func parseCPUSet(set string) ([]int, error) {
+	var cpus []int
+	seen := make(map[int]struct{})
+	for _, part := range strings.Split(set, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		low, high, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(low)
+		if err != nil || first < 0 {
+			return nil, fmt.Errorf("invalid cpu %q", part)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(high)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid cpu range %q", part)
+			}
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			if _, ok := seen[cpu]; ok {
+				return nil, fmt.Errorf("duplicate cpu %d", cpu)
+			}
+			seen[cpu] = struct{}{}
+			cpus = append(cpus, cpu)
+		}
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("empty cpuset")
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// formatCPUSet formats CPUs in cpuset list format, collapsing consecutive
+// CPUs into ranges.
+func formatCPUSet(cpus []int) string {
+	sorted := append([]int{}, cpus...)
+	sort.Ints(sorted)
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
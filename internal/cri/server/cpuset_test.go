@@ -0,0 +1,165 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+)
+
+func TestParseFormatCPUSet(t *testing.T) {
+	for _, test := range []struct {
+		set  string
+		cpus []int
+	}{
+		{set: "0", cpus: []int{0}},
+		{set: "0-3", cpus: []int{0, 1, 2, 3}},
+		{set: "0-2,8,10-11", cpus: []int{0, 1, 2, 8, 10, 11}},
+	} {
+		cpus, err := parseCPUSet(test.set)
+		require.NoError(t, err, test.set)
+		assert.Equal(t, test.cpus, cpus, test.set)
+		assert.Equal(t, test.set, formatCPUSet(cpus), test.set)
+	}
+	for _, set := range []string{"", "a", "-1", "3-1", "0,0"} {
+		_, err := parseCPUSet(set)
+		assert.Error(t, err, set)
+	}
+}
+
+func TestCPUSetAllocator(t *testing.T) {
+	stateDir := t.TempDir()
+	a, err := newCPUSetAllocator("4-7", stateDir)
+	require.NoError(t, err)
+
+	cpus, err := a.Allocate("c1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "4-5", cpus)
+
+	// Allocating again for the same container is idempotent.
+	cpus, err = a.Allocate("c1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "4-5", cpus)
+
+	cpus, err = a.Allocate("c2", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "6-7", cpus)
+
+	// The pool is exhausted.
+	_, err = a.Allocate("c3", 1)
+	assert.ErrorContains(t, err, "not enough exclusive CPUs")
+
+	// Releasing returns the CPUs to the pool.
+	a.Release("c1")
+	cpus, err = a.Allocate("c3", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "4", cpus)
+
+	// Assignments survive a reload from the state file.
+	reloaded, err := newCPUSetAllocator("4-7", stateDir)
+	require.NoError(t, err)
+	cpus, ok := reloaded.Get("c2")
+	assert.True(t, ok)
+	assert.Equal(t, "6-7", cpus)
+	cpus, ok = reloaded.Get("c3")
+	assert.True(t, ok)
+	assert.Equal(t, "4", cpus)
+
+	// Pruning drops assignments of containers which no longer exist.
+	reloaded.prune(func(id string) bool { return id == "c2" })
+	_, ok = reloaded.Get("c3")
+	assert.False(t, ok)
+	_, ok = reloaded.Get("c2")
+	assert.True(t, ok)
+}
+
+func TestExclusiveCPUCount(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		config *runtime.ContainerConfig
+		count  int
+	}{
+		{
+			desc:   "no resources",
+			config: &runtime.ContainerConfig{},
+		},
+		{
+			desc: "guaranteed integer request",
+			config: &runtime.ContainerConfig{
+				Linux: &runtime.LinuxContainerConfig{
+					Resources: &runtime.LinuxContainerResources{
+						CpuQuota:  200000,
+						CpuPeriod: 100000,
+						CpuShares: 2048,
+					},
+				},
+			},
+			count: 2,
+		},
+		{
+			desc: "fractional limit",
+			config: &runtime.ContainerConfig{
+				Linux: &runtime.LinuxContainerConfig{
+					Resources: &runtime.LinuxContainerResources{
+						CpuQuota:  150000,
+						CpuPeriod: 100000,
+						CpuShares: 1536,
+					},
+				},
+			},
+		},
+		{
+			desc: "burstable request below limit",
+			config: &runtime.ContainerConfig{
+				Linux: &runtime.LinuxContainerConfig{
+					Resources: &runtime.LinuxContainerResources{
+						CpuQuota:  200000,
+						CpuPeriod: 100000,
+						CpuShares: 1024,
+					},
+				},
+			},
+		},
+		{
+			desc: "annotation override",
+			config: &runtime.ContainerConfig{
+				Annotations: map[string]string{
+					annotations.ExclusiveCPUCount: "3",
+				},
+			},
+			count: 3,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			count, err := exclusiveCPUCount(test.config)
+			require.NoError(t, err)
+			assert.Equal(t, test.count, count)
+		})
+	}
+
+	_, err := exclusiveCPUCount(&runtime.ContainerConfig{
+		Annotations: map[string]string{
+			annotations.ExclusiveCPUCount: "two",
+		},
+	})
+	assert.Error(t, err)
+}
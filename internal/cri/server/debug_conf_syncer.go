@@ -0,0 +1,219 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/internal/debuglog"
+	"github.com/containerd/log"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// debugConfig is the content of the debug config file watched by
+// debugConfSyncer. All fields are optional; removing the file or a field
+// restores the default behavior.
+type debugConfig struct {
+	// LogLevel overrides the daemon log level while set, e.g. "debug".
+	LogLevel string `toml:"log_level" json:"logLevel"`
+	// PprofAddress serves the Go pprof endpoints on the given address while
+	// set, e.g. "127.0.0.1:6060".
+	PprofAddress string `toml:"pprof_address" json:"pprofAddress"`
+	// VerboseSubsystems lists subsystems whose debug logging is emitted
+	// regardless of the global log level, e.g. "snapshotter", "imagegc".
+	VerboseSubsystems []string `toml:"verbose_subsystems" json:"verboseSubsystems"`
+}
+
+// debugConfSyncer applies debug facility toggles at runtime, triggered by fs
+// change events on the debug config file, so an incident can be debugged
+// without restarting containerd and losing the state being debugged.
+type debugConfSyncer struct {
+	sync.RWMutex
+	lastSyncStatus error
+	applied        debugConfig
+	pprofServer    *http.Server
+
+	watcher  *fsnotify.Watcher
+	confPath string
+	// baseLevel is the log level from the daemon config, restored when the
+	// debug config stops overriding it.
+	baseLevel log.Level
+}
+
+// newDebugConfSyncer creates a debug conf syncer watching confPath.
+func newDebugConfSyncer(confPath string) (*debugConfSyncer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	confDir := filepath.Dir(confPath)
+	if err := os.MkdirAll(confDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create debug conf dir=%s for watch: %w", confDir, err)
+	}
+
+	if err := watcher.Add(confDir); err != nil {
+		return nil, fmt.Errorf("failed to watch debug conf dir %s: %w", confDir, err)
+	}
+
+	syncer := &debugConfSyncer{
+		watcher:   watcher,
+		confPath:  confPath,
+		baseLevel: log.GetLevel(),
+	}
+
+	if err := syncer.load(); err != nil {
+		log.L.WithError(err).Error("failed to apply debug config during init")
+		syncer.updateLastStatus(err)
+	}
+	return syncer, nil
+}
+
+// syncLoop monitors fs change events on the debug config file and reapplies
+// it.
+func (syncer *debugConfSyncer) syncLoop() error {
+	for {
+		select {
+		case event, ok := <-syncer.watcher.Events:
+			if !ok {
+				log.L.Debugf("debug conf watcher channel is closed")
+				return nil
+			}
+			if filepath.Base(event.Name) != filepath.Base(syncer.confPath) || event.Has(fsnotify.Chmod) {
+				continue
+			}
+			log.L.Debugf("receiving change event from debug conf file: %s", event)
+
+			lerr := syncer.load()
+			if lerr != nil {
+				log.L.WithError(lerr).
+					Errorf("failed to reload debug configuration after receiving fs change event(%s)", event)
+			}
+			syncer.updateLastStatus(lerr)
+
+		case err := <-syncer.watcher.Errors:
+			if err != nil {
+				log.L.WithError(err).Error("failed to continue sync debug conf change")
+				return err
+			}
+		}
+	}
+}
+
+// load reads the debug config file and applies it. A missing file applies the
+// zero config, restoring the defaults.
+func (syncer *debugConfSyncer) load() error {
+	var cfg debugConfig
+	b, err := os.ReadFile(syncer.confPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := toml.Unmarshal(b, &cfg); err != nil {
+			return fmt.Errorf("failed to parse debug config %s: %w", syncer.confPath, err)
+		}
+	}
+	return syncer.apply(cfg)
+}
+
+func (syncer *debugConfSyncer) apply(cfg debugConfig) error {
+	if cfg.LogLevel != "" {
+		if err := log.SetLevel(cfg.LogLevel); err != nil {
+			return err
+		}
+		log.L.Infof("debug config set log level to %q", cfg.LogLevel)
+	} else {
+		log.L.Logger.SetLevel(syncer.baseLevel)
+	}
+
+	debuglog.SetSubsystems(cfg.VerboseSubsystems)
+
+	syncer.Lock()
+	defer syncer.Unlock()
+	if cfg.PprofAddress != syncer.applied.PprofAddress {
+		if syncer.pprofServer != nil {
+			syncer.pprofServer.Close()
+			syncer.pprofServer = nil
+		}
+		if cfg.PprofAddress != "" {
+			syncer.pprofServer = newPprofServer(cfg.PprofAddress)
+			log.L.Infof("debug config enabled pprof on %q", cfg.PprofAddress)
+		}
+	}
+	syncer.applied = cfg
+	return nil
+}
+
+// current returns the debug config currently in effect.
+func (syncer *debugConfSyncer) current() debugConfig {
+	syncer.RLock()
+	defer syncer.RUnlock()
+	return syncer.applied
+}
+
+// lastStatus retrieves last sync status.
+func (syncer *debugConfSyncer) lastStatus() error {
+	syncer.RLock()
+	defer syncer.RUnlock()
+	return syncer.lastSyncStatus
+}
+
+// updateLastStatus will be called after every single debug config load.
+func (syncer *debugConfSyncer) updateLastStatus(err error) {
+	syncer.Lock()
+	defer syncer.Unlock()
+	syncer.lastSyncStatus = err
+}
+
+// stop stops the watcher in the syncLoop and the pprof server, if running.
+func (syncer *debugConfSyncer) stop() error {
+	syncer.Lock()
+	if syncer.pprofServer != nil {
+		syncer.pprofServer.Close()
+		syncer.pprofServer = nil
+	}
+	syncer.Unlock()
+	return syncer.watcher.Close()
+}
+
+// newPprofServer starts serving the Go pprof endpoints on addr.
+func newPprofServer(addr string) *http.Server {
+	m := http.NewServeMux()
+	m.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	m.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	m.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	m.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	m.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           m,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.L.WithError(err).Errorf("failed to serve pprof on %s", addr)
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,66 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containerd/containerd/v2/internal/debuglog"
+	"github.com/containerd/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugConfSyncerLoad(t *testing.T) {
+	baseLevel := log.GetLevel()
+	defer func() {
+		log.L.Logger.SetLevel(baseLevel)
+		debuglog.SetSubsystems(nil)
+	}()
+
+	confPath := filepath.Join(t.TempDir(), "debug.toml")
+	syncer, err := newDebugConfSyncer(confPath)
+	require.NoError(t, err)
+	defer syncer.stop()
+
+	// A missing file applies the zero config.
+	assert.NoError(t, syncer.lastStatus())
+	assert.Equal(t, baseLevel, log.GetLevel())
+
+	require.NoError(t, os.WriteFile(confPath, []byte(`
+log_level = "debug"
+verbose_subsystems = ["snapshotter"]
+`), 0600))
+	require.NoError(t, syncer.load())
+	assert.Equal(t, log.DebugLevel, log.GetLevel())
+	assert.True(t, debuglog.Enabled("snapshotter"))
+	assert.False(t, debuglog.Enabled("imagegc"))
+	assert.Equal(t, []string{"snapshotter"}, debuglog.Subsystems())
+	assert.Equal(t, "debug", syncer.current().LogLevel)
+
+	// Removing the file restores the defaults.
+	require.NoError(t, os.Remove(confPath))
+	require.NoError(t, syncer.load())
+	assert.Equal(t, baseLevel, log.GetLevel())
+	assert.False(t, debuglog.Enabled("snapshotter"))
+
+	// A bad level is rejected and reported.
+	require.NoError(t, os.WriteFile(confPath, []byte(`log_level = "shout"`), 0600))
+	assert.Error(t, syncer.load())
+}
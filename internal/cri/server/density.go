@@ -0,0 +1,67 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkSandboxDensity fails a RunPodSandbox request with ResourceExhausted
+// when the node already hosts the configured maximum of pod sandboxes.
+func (c *criService) checkSandboxDensity() error {
+	if max := c.config.Density.MaxSandboxes; max > 0 {
+		if n := len(c.sandboxStore.List()); n >= max {
+			return status.Errorf(codes.ResourceExhausted, "node density limit reached: %d of %d sandboxes in use", n, max)
+		}
+	}
+	return nil
+}
+
+// checkContainerDensity fails a CreateContainer request with ResourceExhausted
+// when the node already hosts the configured maximum of containers, loop
+// devices or overlaybd devices. The device limits guard kernel resources
+// shared with other consumers, so they count the node-wide totals.
+func (c *criService) checkContainerDensity() error {
+	if max := c.config.Density.MaxContainers; max > 0 {
+		if n := len(c.containerStore.List()); n >= max {
+			return status.Errorf(codes.ResourceExhausted, "node density limit reached: %d of %d containers in use", n, max)
+		}
+	}
+	if max := c.config.Density.MaxLoopDevices; max > 0 {
+		if n := countLoopDevices(); n >= max {
+			return status.Errorf(codes.ResourceExhausted, "node density limit reached: %d of %d loop devices attached", n, max)
+		}
+	}
+	if max := c.config.Density.MaxOverlaybdDevices; max > 0 {
+		if n := countOverlaybdDevices(); n >= max {
+			return status.Errorf(codes.ResourceExhausted, "node density limit reached: %d of %d overlaybd devices in use", n, max)
+		}
+	}
+	return nil
+}
+
+// densityUsage reports the current usage of each guarded resource, surfaced
+// through the verbose runtime status info.
+func (c *criService) densityUsage() map[string]int {
+	return map[string]int{
+		"containers":       len(c.containerStore.List()),
+		"sandboxes":        len(c.sandboxStore.List()),
+		"loopDevices":      countLoopDevices(),
+		"overlaybdDevices": countOverlaybdDevices(),
+	}
+}
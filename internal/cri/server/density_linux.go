@@ -0,0 +1,49 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// countLoopDevices counts the loop devices attached on the node. Devices
+// bound to a backing file expose a loop directory under /sys/block.
+func countLoopDevices() int {
+	matches, _ := filepath.Glob("/sys/block/loop*/loop")
+	return len(matches)
+}
+
+// countOverlaybdDevices counts the TCMU backstores on the node, which is how
+// the overlaybd snapshotter exposes its devices. Each backstore is a
+// directory below a user_* HBA in the target configfs.
+func countOverlaybdDevices() int {
+	hbas, _ := filepath.Glob("/sys/kernel/config/target/core/user_*")
+	var n int
+	for _, hba := range hbas {
+		entries, err := os.ReadDir(hba)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,80 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+func TestCheckSandboxDensity(t *testing.T) {
+	c := newTestCRIService()
+	require.NoError(t, c.sandboxStore.Add(sandboxstore.NewSandbox(
+		sandboxstore.Metadata{
+			ID:     "s-1abcdef1234",
+			Name:   "sandboxname-1",
+			Config: &runtime.PodSandboxConfig{Metadata: &runtime.PodSandboxMetadata{Name: "podname-1"}},
+		},
+		sandboxstore.Status{State: sandboxstore.StateReady},
+	)))
+
+	// No limit configured.
+	assert.NoError(t, c.checkSandboxDensity())
+
+	c.config.Density.MaxSandboxes = 2
+	assert.NoError(t, c.checkSandboxDensity())
+
+	c.config.Density.MaxSandboxes = 1
+	err := c.checkSandboxDensity()
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestCheckContainerDensity(t *testing.T) {
+	c := newTestCRIService()
+	container, err := containerstore.NewContainer(containerstore.Metadata{
+		ID:        "c-1container",
+		Name:      "name-1",
+		SandboxID: "s-1abcdef1234",
+		Config:    &runtime.ContainerConfig{Metadata: &runtime.ContainerMetadata{Name: "name-1"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.containerStore.Add(container))
+
+	// No limit configured.
+	assert.NoError(t, c.checkContainerDensity())
+
+	c.config.Density.MaxContainers = 2
+	assert.NoError(t, c.checkContainerDensity())
+
+	c.config.Density.MaxContainers = 1
+	err = c.checkContainerDensity()
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	usage := c.densityUsage()
+	assert.Equal(t, 1, usage["containers"])
+	assert.Equal(t, 0, usage["sandboxes"])
+}
@@ -257,6 +257,9 @@ func (c *criService) handleContainerExit(ctx context.Context, e *eventtypes.Task
 	}
 	// Using channel to propagate the information of container stop
 	cntr.Stop()
+	// Keep the container's image protected from image GC for the configured
+	// grace window, so a crash-looping pod can restart from it.
+	c.RecordImageExit(cntr.ImageRef)
 	c.generateAndSendContainerEvent(ctx, cntr.ID, sandboxID, runtime.ContainerEventType_CONTAINER_STOPPED_EVENT)
 	return nil
 }
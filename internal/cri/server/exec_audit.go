@@ -0,0 +1,167 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containerd/log"
+
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
+)
+
+// defaultAuditRedactPattern masks command arguments which look like they
+// carry credentials. Operators can extend the set through
+// `exec_audit_redact_patterns`.
+var defaultAuditRedactPattern = regexp.MustCompile(`(?i)(password|passwd|token|secret|credential|apikey|api[-_]key)`)
+
+// auditRedacted replaces the matched argument value in audit records.
+const auditRedacted = "REDACTED"
+
+// execAuditEntry is one JSON line of the exec audit log, recording
+// interactive access to a container for security review.
+type execAuditEntry struct {
+	Time          string   `json:"time"`
+	Event         string   `json:"event"`
+	ContainerID   string   `json:"containerID"`
+	ContainerName string   `json:"containerName,omitempty"`
+	SandboxID     string   `json:"sandboxID"`
+	PodName       string   `json:"podName,omitempty"`
+	PodNamespace  string   `json:"podNamespace,omitempty"`
+	User          string   `json:"user,omitempty"`
+	Command       []string `json:"command,omitempty"`
+	TTY           bool     `json:"tty,omitempty"`
+	Duration      string   `json:"duration,omitempty"`
+	ExitCode      *int32   `json:"exitCode,omitempty"`
+}
+
+// execAuditLogger appends audit entries for Exec, ExecSync and Attach calls
+// to a node-local file.
+type execAuditLogger struct {
+	mu       sync.Mutex
+	f        *os.File
+	patterns []*regexp.Regexp
+}
+
+// newExecAuditLogger opens the audit log for appending and compiles the
+// configured redaction patterns.
+func newExecAuditLogger(path string, redactPatterns []string) (*execAuditLogger, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	patterns := []*regexp.Regexp{defaultAuditRedactPattern}
+	for _, p := range redactPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		patterns = append(patterns, re)
+	}
+	return &execAuditLogger{f: f, patterns: patterns}, nil
+}
+
+func (l *execAuditLogger) write(entry execAuditEntry) {
+	entry.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.L.WithError(err).Warn("failed to encode exec audit entry")
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		log.L.WithError(err).Warn("failed to write exec audit entry")
+	}
+}
+
+// redactCommand masks arguments matching a redaction pattern. For arguments
+// of the form "flag=value" where only the flag matches, the value is masked
+// and the flag kept; an argument following a matching flag is masked as well.
+func (l *execAuditLogger) redactCommand(cmd []string) []string {
+	redacted := make([]string, len(cmd))
+	maskNext := false
+	for i, arg := range cmd {
+		if maskNext {
+			redacted[i] = auditRedacted
+			maskNext = false
+			continue
+		}
+		flag, _, hasValue := strings.Cut(arg, "=")
+		if !l.matches(flag) {
+			redacted[i] = arg
+			continue
+		}
+		if hasValue {
+			redacted[i] = flag + "=" + auditRedacted
+			continue
+		}
+		redacted[i] = arg
+		maskNext = true
+	}
+	return redacted
+}
+
+func (l *execAuditLogger) matches(arg string) bool {
+	for _, re := range l.patterns {
+		if re.MatchString(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditExec records an Exec, ExecSync or Attach call against a container when
+// audit logging is enabled.
+func (c *criService) auditExec(event string, cntr containerstore.Container, cmd []string, tty bool, duration time.Duration, exitCode *int32) {
+	if c.execAudit == nil {
+		return
+	}
+	entry := execAuditEntry{
+		Event:         event,
+		ContainerID:   cntr.ID,
+		ContainerName: cntr.Config.GetMetadata().GetName(),
+		SandboxID:     cntr.SandboxID,
+		TTY:           tty,
+		ExitCode:      exitCode,
+	}
+	if len(cmd) > 0 {
+		entry.Command = c.execAudit.redactCommand(cmd)
+	}
+	if duration > 0 {
+		entry.Duration = duration.String()
+	}
+	if sandbox, err := c.sandboxStore.Get(cntr.SandboxID); err == nil {
+		entry.PodName = sandbox.Config.GetMetadata().GetName()
+		entry.PodNamespace = sandbox.Config.GetMetadata().GetNamespace()
+	}
+	if sc := cntr.Config.GetLinux().GetSecurityContext(); sc != nil {
+		if sc.GetRunAsUsername() != "" {
+			entry.User = sc.GetRunAsUsername()
+		} else if sc.GetRunAsUser() != nil {
+			entry.User = strconv.FormatInt(sc.GetRunAsUser().GetValue(), 10)
+		}
+	}
+	c.execAudit.write(entry)
+}
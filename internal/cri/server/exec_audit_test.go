@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecAuditRedactCommand(t *testing.T) {
+	for _, test := range []struct {
+		desc     string
+		patterns []string
+		cmd      []string
+		expected []string
+	}{
+		{
+			desc:     "plain command untouched",
+			cmd:      []string{"ls", "-al", "/var/log"},
+			expected: []string{"ls", "-al", "/var/log"},
+		},
+		{
+			desc:     "flag=value form keeps flag and masks value",
+			cmd:      []string{"mysql", "--password=hunter2"},
+			expected: []string{"mysql", "--password=" + auditRedacted},
+		},
+		{
+			desc:     "bare flag masks the following argument",
+			cmd:      []string{"curl", "--token", "abc123", "http://example.com"},
+			expected: []string{"curl", "--token", auditRedacted, "http://example.com"},
+		},
+		{
+			desc:     "default patterns are case insensitive",
+			cmd:      []string{"tool", "--API-Key=deadbeef"},
+			expected: []string{"tool", "--API-Key=" + auditRedacted},
+		},
+		{
+			desc:     "custom pattern",
+			patterns: []string{`^--pin$`},
+			cmd:      []string{"unlock", "--pin", "1234"},
+			expected: []string{"unlock", "--pin", auditRedacted},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			l, err := newExecAuditLogger(filepath.Join(t.TempDir(), "audit.log"), test.patterns)
+			require.NoError(t, err)
+			defer l.f.Close()
+			assert.Equal(t, test.expected, l.redactCommand(test.cmd))
+		})
+	}
+}
+
+func TestExecAuditLoggerInvalidPattern(t *testing.T) {
+	_, err := newExecAuditLogger(filepath.Join(t.TempDir(), "audit.log"), []string{"("})
+	assert.Error(t, err)
+}
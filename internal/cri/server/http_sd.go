@@ -0,0 +1,154 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containerd/log"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+const (
+	// sdScrapeAnnotation must be "true" on a pod for its containers to be
+	// listed as scrape targets.
+	sdScrapeAnnotation = "prometheus.io/scrape"
+	// sdPortAnnotation is the pod annotation naming the port workloads expose
+	// their metrics on.
+	sdPortAnnotation = "prometheus.io/port"
+)
+
+// sdTargetGroup is one entry of the Prometheus HTTP service discovery
+// response format.
+type sdTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// startHTTPSDServer serves the Prometheus HTTP service discovery endpoint
+// listing running containers as scrape targets when an address is configured.
+func (c *criService) startHTTPSDServer() {
+	addr := c.config.PrometheusSDAddress
+	if addr == "" {
+		return
+	}
+	m := http.NewServeMux()
+	m.HandleFunc("/v1/sd/containers", c.handleHTTPSD)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           m,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.L.WithError(err).Error("Failed to serve prometheus service discovery endpoint")
+		}
+	}()
+}
+
+// handleHTTPSD lists the running containers of pods opting in through the
+// "prometheus.io/scrape" annotation. Results can be narrowed with a
+// "namespace" query parameter matching the pod namespace and "annotation"
+// parameters of the form "key=value" matching pod annotations.
+func (c *criService) handleHTTPSD(w http.ResponseWriter, r *http.Request) {
+	var (
+		query       = r.URL.Query()
+		namespace   = query.Get("namespace")
+		annotations = map[string]string{}
+	)
+	for _, a := range query["annotation"] {
+		k, v, _ := strings.Cut(a, "=")
+		annotations[k] = v
+	}
+
+	groups := []sdTargetGroup{}
+	for _, cntr := range c.containerStore.List() {
+		if cntr.Status.Get().State() != runtime.ContainerState_CONTAINER_RUNNING {
+			continue
+		}
+		sandbox, err := c.sandboxStore.Get(cntr.SandboxID)
+		if err != nil {
+			continue
+		}
+		podConfig := sandbox.Config
+		if podConfig.GetAnnotations()[sdScrapeAnnotation] != "true" {
+			continue
+		}
+		port := podConfig.GetAnnotations()[sdPortAnnotation]
+		if port == "" {
+			continue
+		}
+		if namespace != "" && podConfig.GetMetadata().GetNamespace() != namespace {
+			continue
+		}
+		if !matchAnnotations(podConfig.GetAnnotations(), annotations) {
+			continue
+		}
+		ip, _, err := c.getIPs(sandbox)
+		if err != nil || ip == "" {
+			continue
+		}
+
+		labels := map[string]string{
+			"__meta_containerd_namespace":      podConfig.GetMetadata().GetNamespace(),
+			"__meta_containerd_pod_name":       podConfig.GetMetadata().GetName(),
+			"__meta_containerd_sandbox_id":     sandbox.ID,
+			"__meta_containerd_container_id":   cntr.ID,
+			"__meta_containerd_container_name": cntr.Config.GetMetadata().GetName(),
+		}
+		for k, v := range cntr.Config.GetLabels() {
+			labels["__meta_containerd_label_"+sanitizeLabelName(k)] = v
+		}
+		groups = append(groups, sdTargetGroup{
+			Targets: []string{net.JoinHostPort(ip, port)},
+			Labels:  labels,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(groups); err != nil {
+		log.L.WithError(err).Error("Failed to write service discovery response")
+	}
+}
+
+// matchAnnotations reports whether all requested annotation key/value pairs
+// are present.
+func matchAnnotations(annotations, requested map[string]string) bool {
+	for k, v := range requested {
+		if annotations[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeLabelName maps a container label key onto the character set
+// prometheus accepts in label names.
+func sanitizeLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
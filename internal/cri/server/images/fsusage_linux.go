@@ -0,0 +1,38 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import "syscall"
+
+// fsUsagePercent reports how full the filesystem containing path is: bytes
+// as a percentage of the space visible to unprivileged users, matching df,
+// and inodes as a percentage of the inode table, which overlay-heavy nodes
+// often exhaust before bytes.
+func fsUsagePercent(path string) (bytesPct, inodesPct float64, _ error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, err
+	}
+	used := st.Blocks - st.Bfree
+	if used+st.Bavail > 0 {
+		bytesPct = float64(used) / float64(used+st.Bavail) * 100
+	}
+	if st.Files > 0 {
+		inodesPct = float64(st.Files-st.Ffree) / float64(st.Files) * 100
+	}
+	return bytesPct, inodesPct, nil
+}
@@ -0,0 +1,240 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/events"
+	"github.com/containerd/containerd/v2/core/images"
+	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
+	"github.com/containerd/containerd/v2/internal/debuglog"
+	"github.com/containerd/containerd/v2/internal/ioclass"
+	"github.com/containerd/log"
+)
+
+const (
+	// imageGCQueueSize bounds the number of pending reclamations. When the
+	// queue is full RemoveImage falls back to the synchronous delete path.
+	imageGCQueueSize = 128
+	// imageGCMaxDefer caps how long a reclamation is postponed while image
+	// pulls are in flight before it is run anyway.
+	imageGCMaxDefer = time.Minute
+)
+
+// reclaimTask is the deletion of the last reference of a removed image, which
+// is what triggers content garbage collection.
+type reclaimTask struct {
+	ref string
+	// size is the compressed size of the image, used as the reclaimed bytes
+	// estimate for metrics.
+	size int64
+}
+
+// imageGCScheduler reclaims content of removed images asynchronously. GC
+// triggers are rate limited to at most one per minInterval and are treated as
+// low priority: while image pulls are in flight reclamation is deferred, so
+// GC pauses do not pile up during high pod churn.
+type imageGCScheduler struct {
+	images      images.Store
+	imageStore  *imagestore.Store
+	minInterval time.Duration
+	activePulls *atomic.Int64
+	inflight    *inflightPulls
+	taskC       chan reclaimTask
+	// kickC requests an immediate pass, skipping the inter-task interval.
+	kickC chan struct{}
+	// publisher emits ImageGCRemovedTopic events after reclamations. Nil
+	// when no event exchange is available, e.g. in tests.
+	publisher events.Publisher
+	// dryRun records and logs reclamations instead of running them, leaving
+	// the deletion to the caller's synchronous path.
+	dryRun bool
+
+	// Counters for status reporting.
+	lastGC          atomic.Int64
+	reclaimedImages atomic.Uint64
+	reclaimedBytes  atomic.Uint64
+	// Counters of what a dry run would have reclaimed.
+	wouldReclaimImages atomic.Uint64
+	wouldReclaimBytes  atomic.Uint64
+}
+
+// imageGCState is a snapshot of the scheduler state for status reporting.
+type imageGCState struct {
+	// LastGCTime is when the last reference was reclaimed.
+	LastGCTime time.Time `json:"lastGCTime,omitempty"`
+	// ReclaimedImages counts the references reclaimed since startup.
+	ReclaimedImages uint64 `json:"reclaimedImages"`
+	// ReclaimedBytes estimates the bytes released since startup.
+	ReclaimedBytes uint64 `json:"reclaimedBytes"`
+	// PendingReclaims is the current queue length.
+	PendingReclaims int `json:"pendingReclaims"`
+	// DryRun reports that reclamation is disabled and only recorded.
+	DryRun bool `json:"dryRun,omitempty"`
+	// WouldReclaimImages counts the references a dry run would have
+	// reclaimed since startup.
+	WouldReclaimImages uint64 `json:"wouldReclaimImages,omitempty"`
+	// WouldReclaimBytes estimates the bytes a dry run would have released
+	// since startup.
+	WouldReclaimBytes uint64 `json:"wouldReclaimBytes,omitempty"`
+}
+
+func newImageGCScheduler(store images.Store, imageStore *imagestore.Store, minInterval time.Duration, activePulls *atomic.Int64, inflight *inflightPulls, publisher events.Publisher, dryRun bool) *imageGCScheduler {
+	if minInterval <= 0 {
+		minInterval = 10 * time.Second
+	}
+	return &imageGCScheduler{
+		images:      store,
+		imageStore:  imageStore,
+		minInterval: minInterval,
+		activePulls: activePulls,
+		inflight:    inflight,
+		taskC:       make(chan reclaimTask, imageGCQueueSize),
+		kickC:       make(chan struct{}, 1),
+		publisher:   publisher,
+		dryRun:      dryRun,
+	}
+}
+
+// kick requests an immediate pass over the pending reclamations, skipping
+// the inter-task interval.
+func (g *imageGCScheduler) kick() {
+	select {
+	case g.kickC <- struct{}{}:
+	default:
+	}
+}
+
+// state snapshots the scheduler for status reporting.
+func (g *imageGCScheduler) state() imageGCState {
+	s := imageGCState{
+		ReclaimedImages:    g.reclaimedImages.Load(),
+		ReclaimedBytes:     g.reclaimedBytes.Load(),
+		PendingReclaims:    len(g.taskC),
+		DryRun:             g.dryRun,
+		WouldReclaimImages: g.wouldReclaimImages.Load(),
+		WouldReclaimBytes:  g.wouldReclaimBytes.Load(),
+	}
+	if n := g.lastGC.Load(); n > 0 {
+		s.LastGCTime = time.Unix(0, n)
+	}
+	return s
+}
+
+func (g *imageGCScheduler) start(ctx context.Context) {
+	go g.run(ctx)
+}
+
+// enqueue hands a reference deletion to the scheduler. It reports false when
+// the queue is full or in dry-run mode, in which case the caller must delete
+// the reference itself.
+func (g *imageGCScheduler) enqueue(t reclaimTask) bool {
+	if g.dryRun {
+		g.wouldReclaimImages.Add(1)
+		g.wouldReclaimBytes.Add(uint64(t.size))
+		log.L.Infof("image gc dry-run: would reclaim image reference %q, releasing an estimated %d bytes", t.ref, t.size)
+		return false
+	}
+	select {
+	case g.taskC <- t:
+		imageGCPendingReclaims.Set(float64(len(g.taskC)))
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *imageGCScheduler) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-g.taskC:
+			imageGCPendingReclaims.Set(float64(len(g.taskC)))
+			g.waitForLowActivity(ctx)
+			if g.inflight.inFlight(t.ref) {
+				// A re-pull of the candidate is in flight; reclaiming now
+				// could remove content the pull is unpacking. Skip it and
+				// retry on a later pass.
+				imageGCSkippedPulls.Inc()
+				debuglog.G(ctx, "imagegc").Debugf("skipping reclamation of image reference %q: pull in flight", t.ref)
+				if !g.enqueue(t) {
+					log.G(ctx).Warnf("dropping reclamation of image reference %q: gc queue full", t.ref)
+				}
+			} else {
+				g.reclaim(ctx, t)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-g.kickC:
+		case <-time.After(g.minInterval):
+		}
+	}
+}
+
+// waitForLowActivity defers reclamation while image pulls are in flight, up
+// to imageGCMaxDefer.
+func (g *imageGCScheduler) waitForLowActivity(ctx context.Context) {
+	deadline := time.Now().Add(imageGCMaxDefer)
+	for g.activePulls.Load() > 0 && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (g *imageGCScheduler) reclaim(ctx context.Context, t reclaimTask) {
+	start := time.Now()
+	var err error
+	// Reclaim at the configured background I/O class so content deletion
+	// doesn't compete with workload I/O.
+	ioclass.Run(ioclass.ActivityGC, func() {
+		err = g.images.Delete(ctx, t.ref, images.SynchronousDelete())
+	})
+	elapsed := time.Since(start)
+	imageGCPauseTimer.Update(elapsed)
+	if err != nil {
+		log.G(ctx).WithError(err).Errorf("failed to reclaim image reference %q", t.ref)
+		imageGCReclaims.WithValues("failure").Inc()
+		return
+	}
+	imageGCReclaims.WithValues("success").Inc()
+	imageGCReclaimedBytes.Inc(float64(t.size))
+	g.lastGC.Store(time.Now().UnixNano())
+	g.reclaimedImages.Add(1)
+	g.reclaimedBytes.Add(uint64(t.size))
+	debuglog.G(ctx, "imagegc").Debugf("reclaimed image reference %q (%d bytes) in %s", t.ref, t.size, elapsed)
+	if g.publisher != nil {
+		if err := g.publisher.Publish(ctx, ImageGCRemovedTopic, &ImageGCRemoved{
+			Ref:        t.ref,
+			FreedBytes: t.size,
+			Duration:   elapsed,
+		}); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to publish image gc event for %q", t.ref)
+		}
+	}
+	if err := g.imageStore.Update(ctx, t.ref); err != nil {
+		log.G(ctx).WithError(err).Errorf("failed to update image store for reclaimed reference %q", t.ref)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"time"
+
+	"github.com/containerd/typeurl/v2"
+)
+
+// ImageGCRemovedTopic is the event service topic the async image GC
+// scheduler publishes reclamations on. The metadata store already emits
+// "/images/delete" for the reference itself; this topic additionally
+// carries the freed bytes estimate and the GC pause duration.
+const ImageGCRemovedTopic = "/images/gc/removed"
+
+// ImageGCRemoved is the payload published on ImageGCRemovedTopic after the
+// async image GC scheduler reclaims an image reference. It is registered
+// with typeurl, so event subscribers receive it JSON encoded.
+type ImageGCRemoved struct {
+	// Ref is the reclaimed image reference.
+	Ref string `json:"ref"`
+	// FreedBytes estimates the bytes released: the compressed image size
+	// minus layers shared with other images.
+	FreedBytes int64 `json:"freedBytes"`
+	// Duration is how long the triggered garbage collection took.
+	Duration time.Duration `json:"duration"`
+}
+
+func init() {
+	typeurl.Register(&ImageGCRemoved{},
+		"github.com/containerd/containerd/v2/internal/cri/server/images", "ImageGCRemoved")
+}
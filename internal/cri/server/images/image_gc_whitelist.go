@@ -0,0 +1,118 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	crilabels "github.com/containerd/containerd/v2/internal/cri/labels"
+	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
+)
+
+// matchGCWhitelist reports whether any whitelist entry matches the given
+// labels. An entry is either a bare key, matching any value, or "key=value".
+func matchGCWhitelist(entries []string, labels map[string]string) bool {
+	for _, entry := range entries {
+		key, value, hasValue := strings.Cut(entry, "=")
+		v, ok := labels[key]
+		if !ok {
+			continue
+		}
+		if !hasValue || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// imageGCProtected reports whether the image is protected from image
+// garbage collection: it carries a label matching
+// `image_gc_whitelist_labels` on either its OCI image config or its
+// containerd image record (e.g. set with `ctr image label`), or a
+// container using it exited within `image_gc_exited_grace_period`.
+// Protected images are reported as pinned so the kubelet excludes them
+// when reclaiming disk space.
+func (c *CRIImageService) imageGCProtected(ctx context.Context, image imagestore.Image) bool {
+	if matchGCWhitelist(c.config.ImageGCWhitelistLabels, image.ImageSpec.Config.Labels) {
+		return true
+	}
+	if len(c.config.ImageGCWhitelistLabels) > 0 {
+		for _, ref := range image.References {
+			img, err := c.images.Get(ctx, ref)
+			if err != nil {
+				continue
+			}
+			if matchGCWhitelist(c.config.ImageGCWhitelistLabels, img.Labels) {
+				return true
+			}
+		}
+	}
+	return c.recentlyExited(image.ID)
+}
+
+// RecordImageExit notes that a container using the given image exited, so
+// the image stays protected from garbage collection for
+// `image_gc_exited_grace_period`. It is a no-op when no grace period is
+// configured.
+func (c *CRIImageService) RecordImageExit(imageID string) {
+	if c.exitGracePeriod == 0 || imageID == "" {
+		return
+	}
+	c.recentExitsMu.Lock()
+	defer c.recentExitsMu.Unlock()
+	c.recentExits[imageID] = time.Now()
+}
+
+// recentlyExited reports whether a container using the image exited within
+// the configured grace window, dropping expired records along the way.
+func (c *CRIImageService) recentlyExited(imageID string) bool {
+	if c.exitGracePeriod == 0 {
+		return false
+	}
+	c.recentExitsMu.Lock()
+	defer c.recentExitsMu.Unlock()
+	for id, at := range c.recentExits {
+		if time.Since(at) > c.exitGracePeriod {
+			delete(c.recentExits, id)
+		}
+	}
+	_, ok := c.recentExits[imageID]
+	return ok
+}
+
+// pinImageReference persists the pinned label on the given image reference,
+// protecting the image from garbage collection. It is used when a pull
+// request carries an annotation matching `image_gc_whitelist_labels`.
+func (c *CRIImageService) pinImageReference(ctx context.Context, ref string) error {
+	img, err := c.images.Get(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if img.Labels[crilabels.PinnedImageLabelKey] == crilabels.PinnedImageLabelValue {
+		return nil
+	}
+	if img.Labels == nil {
+		img.Labels = map[string]string{}
+	}
+	img.Labels[crilabels.PinnedImageLabelKey] = crilabels.PinnedImageLabelValue
+	if _, err := c.images.Update(ctx, img, "labels."+crilabels.PinnedImageLabelKey); err != nil {
+		return err
+	}
+	return c.imageStore.Update(ctx, ref)
+}
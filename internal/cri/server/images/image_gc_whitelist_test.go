@@ -0,0 +1,87 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGCWhitelist(t *testing.T) {
+	for _, test := range []struct {
+		desc     string
+		entries  []string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			desc:   "no entries",
+			labels: map[string]string{"gc-protect": "true"},
+		},
+		{
+			desc:    "no labels",
+			entries: []string{"gc-protect"},
+		},
+		{
+			desc:     "bare key matches any value",
+			entries:  []string{"gc-protect"},
+			labels:   map[string]string{"gc-protect": "whatever"},
+			expected: true,
+		},
+		{
+			desc:     "key=value matches exact value",
+			entries:  []string{"gc-protect=true"},
+			labels:   map[string]string{"gc-protect": "true"},
+			expected: true,
+		},
+		{
+			desc:    "key=value rejects other values",
+			entries: []string{"gc-protect=true"},
+			labels:  map[string]string{"gc-protect": "false"},
+		},
+		{
+			desc:     "any entry matching is enough",
+			entries:  []string{"base-image=golden", "gc-protect=true"},
+			labels:   map[string]string{"gc-protect": "true"},
+			expected: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, matchGCWhitelist(test.entries, test.labels))
+		})
+	}
+}
+
+func TestImageGCProtectedRecentExit(t *testing.T) {
+	c, _ := newTestCRIService()
+	c.exitGracePeriod = time.Minute
+	c.recentExits = make(map[string]time.Time)
+	image := imagestore.Image{ID: "sha256:c75bebcdd211f41b3a460c7bf82970ed6c75acaab9cd4c9a4e125b03ca113799"}
+
+	assert.False(t, c.imageGCProtected(context.Background(), image), "no exit recorded")
+
+	c.RecordImageExit(image.ID)
+	assert.True(t, c.imageGCProtected(context.Background(), image), "within the grace window")
+
+	c.recentExits[image.ID] = time.Now().Add(-2 * time.Minute)
+	assert.False(t, c.imageGCProtected(context.Background(), image), "grace window elapsed")
+	assert.Empty(t, c.recentExits, "expired record pruned")
+}
@@ -33,7 +33,11 @@ func (c *GRPCCRIImageService) ListImages(ctx context.Context, r *runtime.ListIma
 	for _, image := range imagesInStore {
 		// TODO(random-liu): [P0] Make sure corresponding snapshot exists. What if snapshot
 		// doesn't exist?
-		images = append(images, toCRIImage(image))
+		criImage := toCRIImage(image)
+		if c.imageGCProtected(ctx, image) {
+			criImage.Pinned = true
+		}
+		images = append(images, criImage)
 	}
 
 	return &runtime.ListImagesResponse{Images: images}, nil
@@ -46,6 +46,7 @@ import (
 	"github.com/containerd/containerd/v2/internal/cri/annotations"
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
 	crilabels "github.com/containerd/containerd/v2/internal/cri/labels"
+	"github.com/containerd/containerd/v2/internal/ioclass"
 	snpkg "github.com/containerd/containerd/v2/pkg/snapshotters"
 	"github.com/containerd/containerd/v2/pkg/tracing"
 	"github.com/containerd/errdefs"
@@ -100,7 +101,7 @@ func (c *GRPCCRIImageService) PullImage(ctx context.Context, r *runtime.PullImag
 	credentials := func(host string) (string, string, error) {
 		hostauth := r.GetAuth()
 		if hostauth == nil {
-			config := c.config.Registry.Configs[host]
+			config := c.registry().Configs[host]
 			if config.Auth != nil {
 				hostauth = toRuntimeAuthConfig(*config.Auth)
 			}
@@ -112,14 +113,20 @@ func (c *GRPCCRIImageService) PullImage(ctx context.Context, r *runtime.PullImag
 	if err != nil {
 		return nil, err
 	}
+
+	// Pin the image when the pull request carries an annotation matching the
+	// gc whitelist, so the kubelet excludes it from image garbage collection.
+	if matchGCWhitelist(c.config.ImageGCWhitelistLabels, r.GetImage().GetAnnotations()) {
+		if err := c.pinImageReference(ctx, ref); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to pin gc whitelisted image %q", ref)
+		}
+	}
 	return &runtime.PullImageResponse{ImageRef: ref}, nil
 }
 
 func (c *CRIImageService) PullImage(ctx context.Context, name string, credentials func(string) (string, string, error), sandboxConfig *runtime.PodSandboxConfig, runtimeHandler string) (_ string, err error) {
 	span := tracing.SpanFromContext(ctx)
 	defer func() {
-		// TODO: add domain label for imagePulls metrics, and we may need to provide a mechanism
-		// for the user to configure the set of registries that they are interested in.
 		if err != nil {
 			imagePulls.WithValues("failure").Inc()
 		} else {
@@ -129,6 +136,8 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 
 	inProgressImagePulls.Inc()
 	defer inProgressImagePulls.Dec()
+	c.activePulls.Add(1)
+	defer c.activePulls.Add(-1)
 	startTime := time.Now()
 
 	namedRef, err := distribution.ParseDockerRef(name)
@@ -140,6 +149,44 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 		log.G(ctx).Debugf("PullImage using normalized image ref: %q", ref)
 	}
 
+	// Enforce the pull policy of the runtime class the image is pulled for
+	// before any resolution happens. The handler comes from the image spec
+	// when set and falls back to the experimental sandbox annotation.
+	policyHandler := runtimeHandler
+	if policyHandler == "" && sandboxConfig != nil {
+		policyHandler = sandboxConfig.GetAnnotations()[annotations.RuntimeHandler]
+	}
+	policy, policed := c.config.PullPolicies[policyHandler]
+	if policed && policy.RequireDigest {
+		if _, ok := namedRef.(distribution.Canonical); !ok {
+			return "", fmt.Errorf("pull policy for runtime %q requires a digest reference, got %q", policyHandler, ref)
+		}
+	}
+
+	// Resolve against the configured mirror when a rewrite rule matches, while
+	// keeping the original reference for the image store metadata below.
+	pullRef, pattern := rewriteRef(ref, c.config.ImageRewrites)
+	if pattern != "" {
+		log.G(ctx).Infof("PullImage rewrote image ref %q to %q", ref, pullRef)
+		imageRewrites.WithValues(pattern).Inc()
+	}
+
+	// Attribute the per-registry metrics below to the registry host of the
+	// reference actually pulled from.
+	registry := "unknown"
+	if named, perr := distribution.ParseDockerRef(pullRef); perr == nil {
+		registry = distribution.Domain(named)
+	}
+
+	// Register all references the pull touches so the image GC scheduler skips
+	// them until pull and unpack complete. The image id and resolved repo
+	// references are appended below once known.
+	inflightRefs := []string{ref, pullRef}
+	c.inflight.add(inflightRefs...)
+	defer func() {
+		c.inflight.done(inflightRefs...)
+	}()
+
 	imagePullProgressTimeout, err := time.ParseDuration(c.config.ImagePullProgressTimeout)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse image_pull_progress_timeout %q: %w", c.config.ImagePullProgressTimeout, err)
@@ -148,23 +195,58 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 	var (
 		pctx, pcancel = context.WithCancel(ctx)
 
-		pullReporter = newPullProgressReporter(ref, pcancel, imagePullProgressTimeout)
+		pullReporter = newPullProgressReporter(pullRef, pcancel, imagePullProgressTimeout,
+			newPullBandwidthLimiter(c.config.ImagePullBandwidthLimit, c.globalPullLimiter))
 
-		resolver = docker.NewResolver(docker.ResolverOptions{
-			Headers: c.config.Registry.Headers,
-			Hosts:   c.registryHosts(ctx, credentials, pullReporter.optionUpdateClient),
-		})
+		hosts        = c.registryHosts(ctx, credentials, pullReporter.optionUpdateClient)
 		isSchema1    bool
-		imageHandler containerdimages.HandlerFunc = func(_ context.Context,
+		layersPulled atomic.Int32
+		layersReused atomic.Int32
+		imageHandler containerdimages.HandlerFunc = func(ctx context.Context,
 			desc imagespec.Descriptor) ([]imagespec.Descriptor, error) {
 			if desc.MediaType == containerdimages.MediaTypeDockerSchema1Manifest {
 				isSchema1 = true
 			}
+			// This handler runs before the fetch handler, so the blob's
+			// presence in the content store tells whether the layer is
+			// reused or fetched from the registry.
+			if containerdimages.IsLayerType(desc.MediaType) {
+				if _, err := c.content.Info(ctx, desc.Digest); err == nil {
+					layersReused.Add(1)
+				} else if errdefs.IsNotFound(err) {
+					layersPulled.Add(1)
+				}
+			}
 			return nil, nil
 		}
 	)
+	if policed && policy.DisallowHTTP {
+		hosts = restrictHTTPHosts(hosts, policyHandler)
+	}
+	resolver := docker.NewResolver(docker.ResolverOptions{
+		Headers: c.registry().Headers,
+		Hosts:   hosts,
+	})
 
 	defer pcancel()
+
+	// Expose this pull's bytes and download rate through the CRI status
+	// verbose info while it is in flight.
+	defer c.trackPullProgress(pullReporter)()
+
+	// Per-registry counterparts of the pull counters above, also recording
+	// the pull duration and the bytes actually read from the registry.
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		imagePullsByRegistry.WithValues(registry, outcome).Inc()
+		imagePullDuration.WithValues(registry, outcome).UpdateSince(startTime)
+		if _, bytesRead := pullReporter.reqReporter.status(); bytesRead > 0 {
+			imagePullBytes.WithValues(registry).Inc(float64(bytesRead))
+		}
+	}()
 	snapshotter, err := c.snapshotterFromPodSandboxConfig(ctx, ref, sandboxConfig)
 	if err != nil {
 		return "", err
@@ -177,6 +259,15 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 
 	labels := c.getLabels(ctx, ref)
 
+	unpackOpts := []containerd.UnpackOpt{
+		containerd.WithUnpackDuplicationSuppressor(c.unpackDuplicationSuppressor),
+		containerd.WithUnpackApplyOpts(diff.WithSyncFs(c.config.ImagePullWithSyncFs)),
+	}
+	if c.unpackLimiter != nil {
+		// The limiter is shared across pulls, so the cap is node-wide.
+		unpackOpts = append(unpackOpts, containerd.WithUnpackApplyLimiter(c.unpackLimiter))
+	}
+
 	pullOpts := []containerd.RemoteOpt{
 		containerd.WithSchema1Conversion, //nolint:staticcheck // Ignore SA1019. Need to keep deprecated package for compatibility.
 		containerd.WithResolver(resolver),
@@ -185,17 +276,18 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 		containerd.WithPullLabels(labels),
 		containerd.WithMaxConcurrentDownloads(c.config.MaxConcurrentDownloads),
 		containerd.WithImageHandler(imageHandler),
-		containerd.WithUnpackOpts([]containerd.UnpackOpt{
-			containerd.WithUnpackDuplicationSuppressor(c.unpackDuplicationSuppressor),
-			containerd.WithUnpackApplyOpts(diff.WithSyncFs(c.config.ImagePullWithSyncFs)),
-		}),
+		containerd.WithUnpackOpts(unpackOpts),
+	}
+
+	if policed && policy.Platform != "" {
+		pullOpts = append(pullOpts, containerd.WithPlatform(policy.Platform))
 	}
 
 	// Temporarily removed for v2 upgrade
 	//pullOpts = append(pullOpts, c.encryptedImagesPullOpts()...)
 	if !c.config.DisableSnapshotAnnotations {
 		pullOpts = append(pullOpts,
-			containerd.WithImageHandlerWrapper(snpkg.AppendInfoHandlerWrapper(ref)))
+			containerd.WithImageHandlerWrapper(snpkg.AppendInfoHandlerWrapper(pullRef)))
 	}
 
 	if c.config.DiscardUnpackedLayers {
@@ -205,12 +297,45 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 	}
 
 	pullReporter.start(pctx)
-	image, err := c.client.Pull(pctx, ref, pullOpts...)
+	image, err := c.client.Pull(pctx, pullRef, pullOpts...)
 	pcancel()
 	if err != nil {
-		return "", fmt.Errorf("failed to pull and unpack image %q: %w", ref, err)
+		return "", fmt.Errorf("failed to pull and unpack image %q: %w", pullRef, err)
 	}
 	span.AddEvent("Pull and unpack image complete")
+	imagePullLayers.WithValues(registry, "pulled").Inc(float64(layersPulled.Load()))
+	imagePullLayers.WithValues(registry, "reused").Inc(float64(layersReused.Load()))
+
+	// Unpack into the additionally requested snapshotters, so nodes being
+	// migrated between snapshotters (e.g. rolling out lazy pulling) have the
+	// image ready under both, sharing the already downloaded content. The pod
+	// annotation overrides the configured list per pull. Failures here do not
+	// fail the pull; the image is usable under the primary snapshotter.
+	extraSnapshotters := c.config.UnpackSnapshotters
+	if sandboxConfig != nil {
+		if v, ok := sandboxConfig.GetAnnotations()[annotations.UnpackSnapshotters]; ok {
+			extraSnapshotters = nil
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					extraSnapshotters = append(extraSnapshotters, s)
+				}
+			}
+		}
+	}
+	for _, extra := range extraSnapshotters {
+		if extra == snapshotter {
+			continue
+		}
+		var unpackErr error
+		// These unpacks are opportunistic background work, run them at the
+		// configured background I/O class.
+		ioclass.Run(ioclass.ActivityPullUnpack, func() {
+			unpackErr = image.Unpack(ctx, extra)
+		})
+		if unpackErr != nil {
+			log.G(ctx).WithError(unpackErr).Warnf("Failed to unpack image %q into snapshotter %q", pullRef, extra)
+		}
+	}
 
 	configDesc, err := image.Config(ctx)
 	if err != nil {
@@ -219,6 +344,8 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 	imageID := configDesc.Digest.String()
 
 	repoDigest, repoTag := getRepoDigestAndTag(namedRef, image.Target().Digest, isSchema1)
+	c.inflight.add(imageID, repoTag, repoDigest)
+	inflightRefs = append(inflightRefs, imageID, repoTag, repoDigest)
 	for _, r := range []string{imageID, repoTag, repoDigest} {
 		if r == "" {
 			continue
@@ -250,6 +377,23 @@ func (c *CRIImageService) PullImage(ctx context.Context, name string, credential
 	return imageID, nil
 }
 
+// restrictHTTPHosts wraps a registry host resolver to refuse plaintext HTTP
+// endpoints for runtimes whose pull policy disallows them.
+func restrictHTTPHosts(hosts docker.RegistryHosts, runtimeHandler string) docker.RegistryHosts {
+	return func(host string) ([]docker.RegistryHost, error) {
+		registries, err := hosts(host)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range registries {
+			if h.Scheme == "http" {
+				return nil, fmt.Errorf("pull policy for runtime %q disallows plaintext HTTP registry endpoint %q", runtimeHandler, h.Host)
+			}
+		}
+		return registries, nil
+	}
+}
+
 // getRepoDigestAngTag returns image repoDigest and repoTag of the named image reference.
 func getRepoDigestAndTag(namedRef distribution.Named, digest imagedigest.Digest, schema1 bool) (string, string) {
 	var repoTag, repoDigest string
@@ -420,9 +564,11 @@ func hostDirFromRoots(roots []string) func(string) (string, error) {
 	}
 }
 
-// registryHosts is the registry hosts to be used by the resolver.
+// registryHosts is the registry hosts to be used by the resolver. The
+// configured hosts are layered under any registered blob sources, so a
+// P2P or proxy backend can serve blobs with the origin as fallback.
 func (c *CRIImageService) registryHosts(ctx context.Context, credentials func(host string) (string, string, error), updateClientFn config.UpdateClientFunc) docker.RegistryHosts {
-	paths := filepath.SplitList(c.config.Registry.ConfigPath)
+	paths := filepath.SplitList(c.registry().ConfigPath)
 	if len(paths) > 0 {
 		hostOptions := config.HostOptions{
 			UpdateClient: updateClientFn,
@@ -430,10 +576,10 @@ func (c *CRIImageService) registryHosts(ctx context.Context, credentials func(ho
 		hostOptions.Credentials = credentials
 		hostOptions.HostDir = hostDirFromRoots(paths)
 
-		return config.ConfigureHosts(ctx, hostOptions)
+		return docker.WithBlobSources(config.ConfigureHosts(ctx, hostOptions))
 	}
 
-	return func(host string) ([]docker.RegistryHost, error) {
+	return docker.WithBlobSources(func(host string) ([]docker.RegistryHost, error) {
 		var registries []docker.RegistryHost
 
 		endpoints, err := c.registryEndpoints(host)
@@ -449,7 +595,7 @@ func (c *CRIImageService) registryHosts(ctx context.Context, credentials func(ho
 			var (
 				transport = newTransport()
 				client    = &http.Client{Transport: transport}
-				config    = c.config.Registry.Configs[u.Host]
+				config    = c.registry().Configs[u.Host]
 			)
 
 			if docker.IsLocalhost(host) && u.Scheme == "http" {
@@ -494,7 +640,7 @@ func (c *CRIImageService) registryHosts(ctx context.Context, credentials func(ho
 			})
 		}
 		return registries, nil
-	}
+	})
 }
 
 // toRuntimeAuthConfig converts cri plugin auth config to runtime auth config.
@@ -533,11 +679,11 @@ func addDefaultScheme(endpoint string) (string, error) {
 // It also supports wildcard host matching with `*`.
 func (c *CRIImageService) registryEndpoints(host string) ([]string, error) {
 	var endpoints []string
-	_, ok := c.config.Registry.Mirrors[host]
+	_, ok := c.registry().Mirrors[host]
 	if ok {
-		endpoints = c.config.Registry.Mirrors[host].Endpoints
+		endpoints = c.registry().Mirrors[host].Endpoints
 	} else {
-		endpoints = c.config.Registry.Mirrors["*"].Endpoints
+		endpoints = c.registry().Mirrors["*"].Endpoints
 	}
 	defaultHost, err := docker.DefaultHost(host)
 	if err != nil {
@@ -605,14 +751,21 @@ type pullProgressReporter struct {
 	cancel      context.CancelFunc
 	reqReporter pullRequestReporter
 	timeout     time.Duration
+	// limiter throttles the registry reads of this pull. Nil when no
+	// bandwidth limit is configured.
+	limiter *pullBandwidthLimiter
+	// startTime is when the pull began, for the average download rate.
+	startTime time.Time
 }
 
-func newPullProgressReporter(ref string, cancel context.CancelFunc, timeout time.Duration) *pullProgressReporter {
+func newPullProgressReporter(ref string, cancel context.CancelFunc, timeout time.Duration, limiter *pullBandwidthLimiter) *pullProgressReporter {
 	return &pullProgressReporter{
 		ref:         ref,
 		cancel:      cancel,
 		reqReporter: pullRequestReporter{},
 		timeout:     timeout,
+		limiter:     limiter,
+		startTime:   time.Now(),
 	}
 }
 
@@ -620,10 +773,26 @@ func (reporter *pullProgressReporter) optionUpdateClient(client *http.Client) er
 	client.Transport = &pullRequestReporterRoundTripper{
 		rt:          client.Transport,
 		reqReporter: &reporter.reqReporter,
+		limiter:     reporter.limiter,
 	}
 	return nil
 }
 
+// progress snapshots the pull for status reporting. The download rate is
+// averaged over the lifetime of the pull.
+func (reporter *pullProgressReporter) progress() PullProgress {
+	activeReqs, bytesRead := reporter.reqReporter.status()
+	p := PullProgress{
+		Ref:            reporter.ref,
+		ActiveRequests: activeReqs,
+		BytesRead:      bytesRead,
+	}
+	if elapsed := time.Since(reporter.startTime).Seconds(); elapsed > 0 {
+		p.BytesPerSecond = float64(bytesRead) / elapsed
+	}
+	return p
+}
+
 func (reporter *pullProgressReporter) start(ctx context.Context) {
 	if reporter.timeout == 0 {
 		log.G(ctx).Infof("no timeout and will not start pulling image %s reporter", reporter.ref)
@@ -686,13 +855,24 @@ type countingReadCloser struct {
 
 	rc          io.ReadCloser
 	reqReporter *pullRequestReporter
+
+	// ctx is the request context, used to abort bandwidth waits.
+	ctx context.Context
+	// limiter throttles the read rate. Nil when no limit is configured.
+	limiter *pullBandwidthLimiter
 }
 
 // Read reads bytes from original io.ReadCloser and increases bytes in
-// pull request reporter.
+// pull request reporter. When a bandwidth limit is configured it blocks
+// until the limiters admit the bytes just read.
 func (r *countingReadCloser) Read(p []byte) (int, error) {
 	n, err := r.rc.Read(p)
 	r.reqReporter.incByteRead(uint64(n))
+	if r.limiter != nil && n > 0 {
+		if werr := r.limiter.wait(r.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
 	return n, err
 }
 
@@ -751,6 +931,7 @@ type pullRequestReporterRoundTripper struct {
 	rt http.RoundTripper
 
 	reqReporter *pullRequestReporter
+	limiter     *pullBandwidthLimiter
 }
 
 func (rt *pullRequestReporterRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -765,6 +946,8 @@ func (rt *pullRequestReporterRoundTripper) RoundTrip(req *http.Request) (*http.R
 	resp.Body = &countingReadCloser{
 		rc:          resp.Body,
 		reqReporter: rt.reqReporter,
+		ctx:         req.Context(),
+		limiter:     rt.limiter,
 	}
 	return resp, err
 }
@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// pullBandwidthLimiter throttles the bytes an image pull reads from the
+// registry. perPull is private to one PullImage request while global is
+// shared by all pulls, so a single pull is bounded by the tighter of the
+// two. Either limiter may be nil.
+type pullBandwidthLimiter struct {
+	perPull *rate.Limiter
+	global  *rate.Limiter
+}
+
+// newPullBandwidthLimiter builds the limiter for one pull. It returns nil
+// when neither a per-pull nor a global limit is configured, in which case
+// reads are not throttled at all.
+func newPullBandwidthLimiter(perPullLimit int64, global *rate.Limiter) *pullBandwidthLimiter {
+	if perPullLimit <= 0 && global == nil {
+		return nil
+	}
+	l := &pullBandwidthLimiter{global: global}
+	if perPullLimit > 0 {
+		l.perPull = newRateLimiter(perPullLimit)
+	}
+	return l
+}
+
+// newRateLimiter builds a token bucket for the given bytes per second with
+// a one second burst.
+func newRateLimiter(bytesPerSecond int64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(bytesPerSecond))
+}
+
+// wait blocks until the limiters allow n more bytes, or the context is
+// canceled. Reads larger than a limiter's burst are admitted in chunks.
+func (l *pullBandwidthLimiter) wait(ctx context.Context, n int) error {
+	for _, lim := range []*rate.Limiter{l.perPull, l.global} {
+		if lim == nil {
+			continue
+		}
+		for remaining := n; remaining > 0; {
+			chunk := remaining
+			if burst := lim.Burst(); chunk > burst {
+				chunk = burst
+			}
+			if err := lim.WaitN(ctx, chunk); err != nil {
+				return err
+			}
+			remaining -= chunk
+		}
+	}
+	return nil
+}
@@ -21,8 +21,10 @@ import (
 	"fmt"
 
 	"github.com/containerd/containerd/v2/core/images"
+	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
 	"github.com/containerd/containerd/v2/pkg/tracing"
 	"github.com/containerd/errdefs"
+	"github.com/containerd/platforms"
 
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
@@ -58,9 +60,14 @@ func (c *CRIImageService) RemoveImage(ctx context.Context, imageSpec *runtime.Im
 	for i, ref := range image.References {
 		var opts []images.DeleteOpt
 		if i == len(image.References)-1 {
-			// Delete the last image reference synchronously to trigger garbage collection.
-			// This is best effort. It is possible that the image reference is deleted by
-			// someone else before this point.
+			// The last image reference deletion triggers garbage collection.
+			// Hand it to the async scheduler when enabled so RemoveImage does
+			// not block on the GC pause; otherwise delete it synchronously.
+			// This is best effort. It is possible that the image reference is
+			// deleted by someone else before this point.
+			if c.gcScheduler != nil && c.gcScheduler.enqueue(reclaimTask{ref: ref, size: c.reclaimableSize(ctx, image)}) {
+				continue
+			}
 			opts = []images.DeleteOpt{images.SynchronousDelete()}
 		}
 		err = c.images.Delete(ctx, ref, opts...)
@@ -75,3 +82,45 @@ func (c *CRIImageService) RemoveImage(ctx context.Context, imageSpec *runtime.Im
 	}
 	return nil
 }
+
+// reclaimableSize estimates the bytes actually freed by deleting img. Layers
+// shared with another image in the store are kept by garbage collection, so
+// counting the full compressed image size makes the freed-size accounting
+// overshoot on nodes full of images built from common bases. Shared layers
+// are matched by diff ID, which pairs up with the manifest layers by
+// position. Falls back to the compressed image size when the manifest cannot
+// be resolved.
+func (c *CRIImageService) reclaimableSize(ctx context.Context, img imagestore.Image) int64 {
+	if len(img.References) == 0 {
+		return img.Size
+	}
+	shared := make(map[string]struct{})
+	for _, other := range c.imageStore.List() {
+		if other.ID == img.ID {
+			continue
+		}
+		for _, diffID := range other.ImageSpec.RootFS.DiffIDs {
+			shared[diffID.String()] = struct{}{}
+		}
+	}
+	cimg, err := c.images.Get(ctx, img.References[0])
+	if err != nil {
+		return img.Size
+	}
+	manifest, err := images.Manifest(ctx, c.content, cimg.Target, platforms.Default())
+	if err != nil {
+		return img.Size
+	}
+	diffIDs := img.ImageSpec.RootFS.DiffIDs
+	if len(manifest.Layers) != len(diffIDs) {
+		return img.Size
+	}
+	size := cimg.Target.Size + manifest.Config.Size
+	for i, layer := range manifest.Layers {
+		if _, ok := shared[diffIDs[i].String()]; ok {
+			continue
+		}
+		size += layer.Size
+	}
+	return size
+}
@@ -0,0 +1,44 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"strings"
+
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+)
+
+// rewriteRef applies the configured image reference rewrite rules to a
+// normalized reference and returns the rewritten reference to resolve along
+// with the pattern of the matching rule. The first matching rule wins; an
+// empty pattern means no rule matched. Only resolution uses the rewritten
+// reference; the original one is still recorded in the image store so pod
+// specs keep their cluster-wide names.
+func rewriteRef(ref string, rules []criconfig.ImageRewrite) (string, string) {
+	for _, r := range rules {
+		if prefix, ok := strings.CutSuffix(r.Pattern, "*"); ok {
+			if strings.HasPrefix(ref, prefix) {
+				return r.Replacement + ref[len(prefix):], r.Pattern
+			}
+			continue
+		}
+		if ref == r.Pattern {
+			return r.Replacement, r.Pattern
+		}
+	}
+	return ref, ""
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"testing"
+
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRewriteRef(t *testing.T) {
+	rules := []criconfig.ImageRewrite{
+		{Pattern: "registry.k8s.io/pause:3.9", Replacement: "mirror.internal:5000/pause:3.9"},
+		{Pattern: "docker.io/library/*", Replacement: "mirror.internal:5000/library/"},
+		{Pattern: "docker.io/*", Replacement: "mirror.internal:5000/"},
+	}
+	for _, test := range []struct {
+		desc            string
+		ref             string
+		expectedRef     string
+		expectedPattern string
+	}{
+		{
+			desc:            "exact match",
+			ref:             "registry.k8s.io/pause:3.9",
+			expectedRef:     "mirror.internal:5000/pause:3.9",
+			expectedPattern: "registry.k8s.io/pause:3.9",
+		},
+		{
+			desc:            "first matching wildcard rule wins",
+			ref:             "docker.io/library/busybox:latest",
+			expectedRef:     "mirror.internal:5000/library/busybox:latest",
+			expectedPattern: "docker.io/library/*",
+		},
+		{
+			desc:            "later wildcard rule",
+			ref:             "docker.io/example/app:v1",
+			expectedRef:     "mirror.internal:5000/example/app:v1",
+			expectedPattern: "docker.io/*",
+		},
+		{
+			desc:        "no rule matched",
+			ref:         "registry.k8s.io/etcd:3.5",
+			expectedRef: "registry.k8s.io/etcd:3.5",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			ref, pattern := rewriteRef(test.ref, rules)
+			assert.Equal(t, test.expectedRef, ref)
+			assert.Equal(t, test.expectedPattern, pattern)
+		})
+	}
+}
@@ -52,6 +52,9 @@ func (c *CRIImageService) ImageStatus(ctx context.Context, r *runtime.ImageStatu
 	// doesn't exist?
 
 	runtimeImage := toCRIImage(image)
+	if c.imageGCProtected(ctx, image) {
+		runtimeImage.Pinned = true
+	}
 	info, err := c.toCRIImageInfo(ctx, &image, r.GetVerbose())
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate image info: %w", err)
@@ -105,6 +108,9 @@ func getUserFromImage(user string) (*int64, string) {
 type verboseImageInfo struct {
 	ChainID   string          `json:"chainID"`
 	ImageSpec imagespec.Image `json:"imageSpec"`
+	// UnpackedSnapshotters are the configured snapshotters the image is
+	// currently unpacked into.
+	UnpackedSnapshotters []string `json:"unpackedSnapshotters,omitempty"`
 }
 
 // toCRIImageInfo converts internal image object information to CRI image status response info map.
@@ -116,8 +122,9 @@ func (c *CRIImageService) toCRIImageInfo(ctx context.Context, image *imagestore.
 	info := make(map[string]string)
 
 	imi := &verboseImageInfo{
-		ChainID:   image.ChainID,
-		ImageSpec: image.ImageSpec,
+		ChainID:              image.ChainID,
+		ImageSpec:            image.ImageSpec,
+		UnpackedSnapshotters: c.unpackedSnapshotters(ctx, image),
 	}
 
 	m, err := json.Marshal(imi)
@@ -130,3 +137,32 @@ func (c *CRIImageService) toCRIImageInfo(ctx context.Context, image *imagestore.
 
 	return info, nil
 }
+
+// unpackedSnapshotters returns the configured snapshotters the image is
+// currently unpacked into, so image status reflects whether a runtime class
+// switch would need another unpack.
+func (c *CRIImageService) unpackedSnapshotters(ctx context.Context, image *imagestore.Image) []string {
+	img, err := c.client.GetImage(ctx, image.ID)
+	if err != nil {
+		log.G(ctx).WithError(err).Debugf("failed to get image %q to check unpacked snapshotters", image.ID)
+		return nil
+	}
+	candidates := append([]string{c.config.Snapshotter}, c.config.UnpackSnapshotters...)
+	for _, p := range c.runtimePlatforms {
+		candidates = append(candidates, p.Snapshotter)
+	}
+	var (
+		seen     = make(map[string]struct{})
+		unpacked []string
+	)
+	for _, s := range candidates {
+		if _, ok := seen[s]; ok || s == "" {
+			continue
+		}
+		seen[s] = struct{}{}
+		if ok, err := img.IsUnpacked(ctx, s); err == nil && ok {
+			unpacked = append(unpacked, s)
+		}
+	}
+	return unpacked
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import "sync"
+
+// inflightPulls is a reference-counted registry of image references currently
+// being pulled or unpacked. The image GC scheduler consults it so reclamation
+// never races with a re-pull of the same image.
+type inflightPulls struct {
+	mu   sync.Mutex
+	refs map[string]int
+}
+
+func newInflightPulls() *inflightPulls {
+	return &inflightPulls{refs: make(map[string]int)}
+}
+
+// add registers the given references as in flight. Empty references are
+// ignored so callers can pass optional repo tags and digests unconditionally.
+func (p *inflightPulls) add(refs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ref := range refs {
+		if ref == "" {
+			continue
+		}
+		p.refs[ref]++
+	}
+}
+
+// done releases references registered with add. The two calls must pass the
+// same set of references.
+func (p *inflightPulls) done(refs ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ref := range refs {
+		if ref == "" {
+			continue
+		}
+		if p.refs[ref] <= 1 {
+			delete(p.refs, ref)
+		} else {
+			p.refs[ref]--
+		}
+	}
+}
+
+// inFlight reports whether a pull of the given reference is in progress.
+func (p *inflightPulls) inFlight(ref string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refs[ref] > 0
+}
@@ -24,8 +24,32 @@ import (
 var (
 	imagePulls           metrics.LabeledCounter
 	inProgressImagePulls metrics.Gauge
+	imageRewrites        metrics.LabeledCounter
 	// image size in MB / image pull duration in seconds
 	imagePullThroughput prom.Histogram
+
+	// per-registry pull statistics, attributing outcome, duration, bytes
+	// read and layer reuse to the registry host pulled from
+	imagePullsByRegistry metrics.LabeledCounter
+	imagePullDuration    metrics.LabeledTimer
+	imagePullBytes       metrics.LabeledCounter
+	imagePullLayers      metrics.LabeledCounter
+
+	// counters and timings for the asynchronous image GC scheduler
+	imageGCReclaims        metrics.LabeledCounter
+	imageGCReclaimedBytes  metrics.Counter
+	imageGCPauseTimer      metrics.Timer
+	imageGCSkippedPulls    metrics.Counter
+	imageGCPendingReclaims metrics.Gauge
+
+	// duration of snapshot stats sync passes per snapshotter
+	snapshotSyncDuration metrics.LabeledTimer
+
+	// per-snapshot on-demand fetch statistics surfaced by lazy-pulling
+	// snapshotters such as overlaybd
+	overlaybdStats = newOverlaybdStatsCollector()
+	// block io of overlaybd devices attributed to the snapshots they back
+	overlaybdIO = newOverlaybdIOCollector()
 )
 
 func init() {
@@ -39,6 +63,17 @@ func init() {
 
 	imagePulls = ns.NewLabeledCounter("image_pulls", "succeeded and failed counters", "status")
 	inProgressImagePulls = ns.NewGauge("in_progress_image_pulls", "in progress pulls", metrics.Total)
+	imageRewrites = ns.NewLabeledCounter("image_rewrites", "image reference rewrites applied", "pattern")
+	imagePullsByRegistry = ns.NewLabeledCounter("image_pulls_by_registry", "succeeded and failed pull counters by registry host", "registry", "status")
+	imagePullDuration = ns.NewLabeledTimer("image_pull_duration", "duration of image pulls by registry host and outcome", "registry", "status")
+	imagePullBytes = ns.NewLabeledCounter("image_pull_bytes", "bytes read from the registry by image pulls", "registry")
+	imagePullLayers = ns.NewLabeledCounter("image_pull_layers", "image layers fetched from the registry or reused from local content", "registry", "status")
+	imageGCReclaims = ns.NewLabeledCounter("image_gc_reclaims", "async image gc reclamations", "status")
+	imageGCReclaimedBytes = ns.NewCounter("image_gc_reclaimed_bytes", "estimated bytes released by async image gc")
+	imageGCPauseTimer = ns.NewTimer("image_gc_pause", "duration of gc triggered by async image reclamation")
+	imageGCSkippedPulls = ns.NewCounter("image_gc_skipped_inflight_pulls", "gc candidates skipped because a pull of the image was in flight")
+	imageGCPendingReclaims = ns.NewGauge("image_gc_pending_reclaims", "reclamations queued by async image gc", metrics.Total)
+	snapshotSyncDuration = ns.NewLabeledTimer("snapshot_stats_sync_duration", "duration of snapshot stats sync passes", "snapshotter")
 	imagePullThroughput = prom.NewHistogram(
 		prom.HistogramOpts{
 			Namespace: namespace,
@@ -49,5 +84,7 @@ func init() {
 		},
 	)
 	ns.Add(imagePullThroughput)
+	prom.MustRegister(overlaybdStats)
+	prom.MustRegister(overlaybdIO)
 	metrics.Register(ns)
 }
@@ -0,0 +1,178 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// overlaybdDeviceLabel is the snapshot label under which the overlaybd
+	// proxy snapshotter reports the block device backing an active snapshot,
+	// e.g. "/dev/vdb" for a loop device or TCMU LUN.
+	overlaybdDeviceLabel = "containerd.io/snapshot/overlaybd.device"
+	// overlaybdBackstorePidLabel is the snapshot label naming the pid of the
+	// backstore process serving the snapshot's device.
+	overlaybdBackstorePidLabel = "containerd.io/snapshot/overlaybd.backstore-pid"
+
+	// sectorSize is the fixed sector unit of /sys/block device stats.
+	sectorSize = 512
+)
+
+// overlaybdIOCollector attributes block IO served by overlaybd devices to the
+// containers they back. The blkio/io cgroup controllers account that IO to
+// the backstore process instead of the container, so the cgroup stats miss
+// it; this collector reads the device and backstore IO counters directly and
+// exports them keyed by snapshot, which for CRI containers is the container
+// id. Values are replaced wholesale on each snapshot sync, like
+// overlaybdStatsCollector.
+type overlaybdIOCollector struct {
+	desc *prom.Desc
+
+	mu sync.Mutex
+	// stats maps snapshotter name -> snapshot key -> device IO counters.
+	stats map[string]map[string]overlaybdDeviceIO
+}
+
+// overlaybdDeviceIO holds the IO counters of one overlaybd device and its
+// backstore process.
+type overlaybdDeviceIO struct {
+	device string
+	stats  map[string]float64
+}
+
+func newOverlaybdIOCollector() *overlaybdIOCollector {
+	return &overlaybdIOCollector{
+		desc: prom.NewDesc(
+			"containerd_cri_sandboxed_overlaybd_device_io",
+			"block io of overlaybd devices and their backstore processes attributed to the backed snapshot",
+			[]string{"snapshotter", "snapshot", "device", "stat"},
+			nil,
+		),
+		stats: make(map[string]map[string]overlaybdDeviceIO),
+	}
+}
+
+// collectOverlaybdIO reads the IO counters of the device and backstore
+// process named in the given snapshot labels. It returns nil stats when the
+// snapshot is not backed by an overlaybd device or no counters are readable.
+func collectOverlaybdIO(labels map[string]string) overlaybdDeviceIO {
+	devicePath := labels[overlaybdDeviceLabel]
+	if devicePath == "" {
+		return overlaybdDeviceIO{}
+	}
+	io := overlaybdDeviceIO{
+		device: filepath.Base(devicePath),
+		stats:  readBlockDeviceStat(filepath.Base(devicePath)),
+	}
+	if pid := labels[overlaybdBackstorePidLabel]; pid != "" {
+		for name, value := range readBackstoreIO(pid) {
+			if io.stats == nil {
+				io.stats = make(map[string]float64)
+			}
+			io.stats[name] = value
+		}
+	}
+	return io
+}
+
+// readBlockDeviceStat reads the IO counters of a block device from
+// /sys/block. See https://www.kernel.org/doc/Documentation/block/stat.txt
+// for the field layout.
+func readBlockDeviceStat(device string) map[string]float64 {
+	data, err := os.ReadFile(filepath.Join("/sys/block", device, "stat"))
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 8 {
+		return nil
+	}
+	values := make([]float64, 8)
+	for i := range values {
+		if values[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return nil
+		}
+	}
+	return map[string]float64{
+		"device_read_ios":    values[0],
+		"device_read_bytes":  values[2] * sectorSize,
+		"device_write_ios":   values[4],
+		"device_write_bytes": values[6] * sectorSize,
+	}
+}
+
+// readBackstoreIO reads the accumulated IO of the backstore process from
+// /proc/<pid>/io, which accounts the remote fetches and cache accesses the
+// device counters do not cover.
+func readBackstoreIO(pid string) map[string]float64 {
+	data, err := os.ReadFile(filepath.Join("/proc", pid, "io"))
+	if err != nil {
+		return nil
+	}
+	var stats map[string]float64
+	for _, line := range strings.Split(string(data), "\n") {
+		k, v, ok := strings.Cut(line, ": ")
+		if !ok || (k != "read_bytes" && k != "write_bytes") {
+			continue
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		if stats == nil {
+			stats = make(map[string]float64)
+		}
+		stats[fmt.Sprintf("backstore_%s", k)] = value
+	}
+	return stats
+}
+
+// replace swaps in the IO statistics of all snapshots of a snapshotter
+// observed during one sync pass.
+func (c *overlaybdIOCollector) replace(snapshotter string, stats map[string]overlaybdDeviceIO) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(stats) == 0 {
+		delete(c.stats, snapshotter)
+		return
+	}
+	c.stats[snapshotter] = stats
+}
+
+func (c *overlaybdIOCollector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.desc
+}
+
+func (c *overlaybdIOCollector) Collect(ch chan<- prom.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for snapshotter, snapshots := range c.stats {
+		for key, io := range snapshots {
+			for name, value := range io.stats {
+				ch <- prom.MustNewConstMetric(c.desc, prom.GaugeValue, value, snapshotter, key, io.device, name)
+			}
+		}
+	}
+}
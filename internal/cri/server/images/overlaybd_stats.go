@@ -0,0 +1,106 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containerd/containerd/v2/client/extensions"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// overlaybdStatsLabelPrefix is the snapshot label prefix under which the
+// overlaybd proxy snapshotter reports on-demand fetch statistics, e.g.
+// "remote-bytes-fetched", "cache-hit-count", "cache-miss-count" or
+// "fetch-latency-usec". The snapshotter itself lives out of tree; any numeric
+// label below this prefix is exported as a gauge so new counters do not
+// require changes here.
+const overlaybdStatsLabelPrefix = extensions.LabelOverlaybdStatsPrefix
+
+// overlaybdStatsCollector exports on-demand fetch statistics per snapshot as
+// reported through snapshot labels. Values are replaced wholesale on each
+// snapshot sync so series of removed snapshots disappear on their own.
+type overlaybdStatsCollector struct {
+	desc *prom.Desc
+
+	mu sync.Mutex
+	// stats maps snapshotter name -> snapshot key -> stat name -> value.
+	stats map[string]map[string]map[string]float64
+}
+
+func newOverlaybdStatsCollector() *overlaybdStatsCollector {
+	return &overlaybdStatsCollector{
+		desc: prom.NewDesc(
+			"containerd_cri_sandboxed_overlaybd_stats",
+			"on-demand fetch statistics reported by the overlaybd snapshotter via snapshot labels",
+			[]string{"snapshotter", "snapshot", "stat"},
+			nil,
+		),
+		stats: make(map[string]map[string]map[string]float64),
+	}
+}
+
+// parseOverlaybdStats extracts numeric statistics labels from snapshot labels,
+// returning nil when none are present.
+func parseOverlaybdStats(labels map[string]string) map[string]float64 {
+	var stats map[string]float64
+	for k, v := range labels {
+		name, ok := strings.CutPrefix(k, overlaybdStatsLabelPrefix)
+		if !ok || name == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			continue
+		}
+		if stats == nil {
+			stats = make(map[string]float64)
+		}
+		stats[strings.ReplaceAll(name, "-", "_")] = value
+	}
+	return stats
+}
+
+// replace swaps in the statistics of all snapshots of a snapshotter observed
+// during one sync pass.
+func (c *overlaybdStatsCollector) replace(snapshotter string, stats map[string]map[string]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(stats) == 0 {
+		delete(c.stats, snapshotter)
+		return
+	}
+	c.stats[snapshotter] = stats
+}
+
+func (c *overlaybdStatsCollector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.desc
+}
+
+func (c *overlaybdStatsCollector) Collect(ch chan<- prom.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for snapshotter, snapshots := range c.stats {
+		for key, stats := range snapshots {
+			for name, value := range stats {
+				ch <- prom.MustNewConstMetric(c.desc, prom.GaugeValue, value, snapshotter, key, name)
+			}
+		}
+	}
+}
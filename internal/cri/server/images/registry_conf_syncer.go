@@ -0,0 +1,229 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	"github.com/containerd/log"
+	"github.com/fsnotify/fsnotify"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// registryConfFile is the content of the dynamic registry config file: a
+// registry section in the same shape as the static CRI configuration.
+type registryConfFile struct {
+	Registry criconfig.Registry `toml:"registry"`
+}
+
+// registryConfSyncer applies registry configuration (mirrors, auth, headers)
+// at runtime, triggered by fs change events on the dynamic registry config
+// file, so credential rotation or mirror changes take effect on the next
+// pull without restarting containerd. A file that fails to parse or
+// validate keeps the previously applied configuration.
+type registryConfSyncer struct {
+	sync.RWMutex
+	lastSyncStatus error
+	// applied is the registry configuration currently in effect.
+	applied criconfig.Registry
+	// lastDiff names the fields changed by the last reload that took
+	// effect, for the CRI status verbose info.
+	lastDiff []string
+
+	watcher  *fsnotify.Watcher
+	confPath string
+	// base is the full static image config; reloads are validated against a
+	// copy of it, and removing the file restores its registry section.
+	base criconfig.ImageConfig
+}
+
+// newRegistryConfSyncer creates a registry conf syncer watching confPath.
+func newRegistryConfSyncer(confPath string, base criconfig.ImageConfig) (*registryConfSyncer, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+
+	confDir := filepath.Dir(confPath)
+	if err := os.MkdirAll(confDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create registry conf dir=%s for watch: %w", confDir, err)
+	}
+
+	if err := watcher.Add(confDir); err != nil {
+		return nil, fmt.Errorf("failed to watch registry conf dir %s: %w", confDir, err)
+	}
+
+	syncer := &registryConfSyncer{
+		watcher:  watcher,
+		confPath: confPath,
+		base:     base,
+		applied:  base.Registry,
+	}
+
+	if err := syncer.load(); err != nil {
+		log.L.WithError(err).Error("failed to apply dynamic registry config during init")
+		syncer.updateLastStatus(err)
+	}
+	return syncer, nil
+}
+
+// syncLoop monitors fs change events on the registry config file and
+// reapplies it.
+func (syncer *registryConfSyncer) syncLoop() error {
+	for {
+		select {
+		case event, ok := <-syncer.watcher.Events:
+			if !ok {
+				log.L.Debugf("registry conf watcher channel is closed")
+				return nil
+			}
+			if filepath.Base(event.Name) != filepath.Base(syncer.confPath) || event.Has(fsnotify.Chmod) {
+				continue
+			}
+			log.L.Debugf("receiving change event from registry conf file: %s", event)
+
+			lerr := syncer.load()
+			if lerr != nil {
+				log.L.WithError(lerr).
+					Errorf("failed to reload registry configuration after receiving fs change event(%s), keeping previous", event)
+			}
+			syncer.updateLastStatus(lerr)
+
+		case err := <-syncer.watcher.Errors:
+			if err != nil {
+				log.L.WithError(err).Error("failed to continue sync registry conf change")
+				return err
+			}
+		}
+	}
+}
+
+// load reads the registry config file, validates it against a copy of the
+// static image config and applies it. A missing file restores the static
+// registry configuration; an invalid one leaves the applied configuration
+// untouched.
+func (syncer *registryConfSyncer) load() error {
+	b, err := os.ReadFile(syncer.confPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		syncer.Lock()
+		if diff := diffRegistry(syncer.applied, syncer.base.Registry); len(diff) > 0 {
+			syncer.applied = syncer.base.Registry
+			syncer.lastDiff = diff
+			log.L.Infof("restored static registry config, changed fields: %v", diff)
+		}
+		syncer.Unlock()
+		return nil
+	}
+	var cfg registryConfFile
+	if err := toml.Unmarshal(b, &cfg); err != nil {
+		return fmt.Errorf("failed to parse registry config %s: %w", syncer.confPath, err)
+	}
+	// Run the regular image config validation on a copy carrying the new
+	// registry section, so reloads obey the same rules as the static config
+	// (and the deprecated auths are mapped to configs the same way).
+	candidate := syncer.base
+	candidate.Registry = cfg.Registry
+	if _, err := criconfig.ValidateImageConfig(context.Background(), &candidate); err != nil {
+		return fmt.Errorf("invalid registry config %s: %w", syncer.confPath, err)
+	}
+
+	syncer.Lock()
+	diff := diffRegistry(syncer.applied, candidate.Registry)
+	syncer.applied = candidate.Registry
+	syncer.lastDiff = diff
+	syncer.Unlock()
+	log.L.Infof("applied dynamic registry config from %s, changed fields: %v", syncer.confPath, diff)
+	return nil
+}
+
+// diffRegistry names the fields differing between two registry
+// configurations, e.g. "mirrors.docker.io" or "headers".
+func diffRegistry(old, updated criconfig.Registry) []string {
+	var diff []string
+	if old.ConfigPath != updated.ConfigPath {
+		diff = append(diff, "config_path")
+	}
+	if !reflect.DeepEqual(old.Headers, updated.Headers) {
+		diff = append(diff, "headers")
+	}
+	for host := range old.Mirrors {
+		if !reflect.DeepEqual(old.Mirrors[host], updated.Mirrors[host]) {
+			diff = append(diff, "mirrors."+host)
+		}
+	}
+	for host := range updated.Mirrors {
+		if _, ok := old.Mirrors[host]; !ok {
+			diff = append(diff, "mirrors."+host)
+		}
+	}
+	for host := range old.Configs {
+		if !reflect.DeepEqual(old.Configs[host], updated.Configs[host]) {
+			diff = append(diff, "configs."+host)
+		}
+	}
+	for host := range updated.Configs {
+		if _, ok := old.Configs[host]; !ok {
+			diff = append(diff, "configs."+host)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+// current returns the registry configuration currently in effect.
+func (syncer *registryConfSyncer) current() criconfig.Registry {
+	syncer.RLock()
+	defer syncer.RUnlock()
+	return syncer.applied
+}
+
+// lastStatus retrieves last sync status.
+func (syncer *registryConfSyncer) lastStatus() error {
+	syncer.RLock()
+	defer syncer.RUnlock()
+	return syncer.lastSyncStatus
+}
+
+// lastAppliedDiff retrieves the fields changed by the last reload that took
+// effect.
+func (syncer *registryConfSyncer) lastAppliedDiff() []string {
+	syncer.RLock()
+	defer syncer.RUnlock()
+	return syncer.lastDiff
+}
+
+// updateLastStatus will be called after every single registry config load.
+func (syncer *registryConfSyncer) updateLastStatus(err error) {
+	syncer.Lock()
+	defer syncer.Unlock()
+	syncer.lastSyncStatus = err
+}
+
+// stop stops the watcher in the syncLoop.
+func (syncer *registryConfSyncer) stop() error {
+	return syncer.watcher.Close()
+}
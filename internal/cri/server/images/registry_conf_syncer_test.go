@@ -0,0 +1,75 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryConfSyncerLoad(t *testing.T) {
+	base := criconfig.ImageConfig{
+		Registry: criconfig.Registry{
+			Mirrors: map[string]criconfig.Mirror{
+				"docker.io": {Endpoints: []string{"https://static.mirror"}},
+			},
+		},
+	}
+
+	confPath := filepath.Join(t.TempDir(), "registry.toml")
+	syncer, err := newRegistryConfSyncer(confPath, base)
+	require.NoError(t, err)
+	defer syncer.stop()
+
+	// A missing file keeps the static registry configuration.
+	assert.NoError(t, syncer.lastStatus())
+	assert.Equal(t, []string{"https://static.mirror"}, syncer.current().Mirrors["docker.io"].Endpoints)
+
+	require.NoError(t, os.WriteFile(confPath, []byte(`
+[registry.mirrors."docker.io"]
+endpoint = ["https://dynamic.mirror"]
+`), 0600))
+	require.NoError(t, syncer.load())
+	assert.Equal(t, []string{"https://dynamic.mirror"}, syncer.current().Mirrors["docker.io"].Endpoints)
+	assert.Equal(t, []string{"mirrors.docker.io"}, syncer.lastAppliedDiff())
+
+	// An invalid file keeps the previously applied configuration.
+	require.NoError(t, os.WriteFile(confPath, []byte(`registry = "not a table"`), 0600))
+	assert.Error(t, syncer.load())
+	assert.Equal(t, []string{"https://dynamic.mirror"}, syncer.current().Mirrors["docker.io"].Endpoints)
+
+	// Mirrors cannot be combined with a hosts.toml config path, matching
+	// static validation.
+	require.NoError(t, os.WriteFile(confPath, []byte(`
+[registry]
+config_path = "/etc/containerd/certs.d"
+
+[registry.mirrors."docker.io"]
+endpoint = ["https://other.mirror"]
+`), 0600))
+	assert.Error(t, syncer.load())
+
+	// Removing the file restores the static configuration.
+	require.NoError(t, os.Remove(confPath))
+	require.NoError(t, syncer.load())
+	assert.Equal(t, []string{"https://static.mirror"}, syncer.current().Mirrors["docker.io"].Endpoints)
+}
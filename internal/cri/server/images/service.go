@@ -18,20 +18,29 @@ package images
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/events"
 	"github.com/containerd/containerd/v2/core/images"
 	"github.com/containerd/containerd/v2/core/snapshots"
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
+	"github.com/containerd/containerd/v2/internal/cri/constants"
 	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
 	snapshotstore "github.com/containerd/containerd/v2/internal/cri/store/snapshot"
 	"github.com/containerd/containerd/v2/internal/kmutex"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/log"
 	"github.com/containerd/platforms"
 	docker "github.com/distribution/reference"
 	imagedigest "github.com/opencontainers/go-digest"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
@@ -56,6 +65,8 @@ type CRIImageService struct {
 	// client is a subset of the containerd client
 	// and will be replaced by image store and transfer service
 	client imageClient
+	// content is the content store used to check blob presence during pulls.
+	content content.Store
 	// imageFSPaths contains path to image filesystem for snapshotters.
 	imageFSPaths map[string]string
 	// runtimePlatforms are the platforms configured for a runtime.
@@ -68,6 +79,139 @@ type CRIImageService struct {
 	// one in-flight fetch request or unpack handler for a given descriptor's
 	// or chain ID.
 	unpackDuplicationSuppressor kmutex.KeyedLocker
+	// activePulls counts in-flight pulls so the image GC scheduler can defer
+	// reclamation during high image activity.
+	activePulls atomic.Int64
+	// inflight tracks the references of in-flight pulls so the image GC
+	// scheduler can skip candidates being pulled or unpacked.
+	inflight *inflightPulls
+	// gcScheduler reclaims content of removed images asynchronously. Nil when
+	// AsyncImageGC is disabled.
+	gcScheduler *imageGCScheduler
+	// registryConfMonitor hot-reloads the registry configuration when
+	// DynamicRegistryConfigPath is set. Nil otherwise.
+	registryConfMonitor *registryConfSyncer
+	// globalPullLimiter enforces ImagePullGlobalBandwidthLimit across all
+	// image pulls. Nil when unlimited.
+	globalPullLimiter *rate.Limiter
+	// unpackLimiter enforces MaxConcurrentUnpacks across all image pulls.
+	// Nil when unlimited.
+	unpackLimiter *semaphore.Weighted
+	// pullProgressMu protects pullProgress.
+	pullProgressMu sync.Mutex
+	// pullProgress tracks the reporters of in-flight pulls for status
+	// reporting.
+	pullProgress map[*pullProgressReporter]struct{}
+	// exitGracePeriod is how long the image of an exited container stays
+	// protected from garbage collection. Zero disables the protection.
+	exitGracePeriod time.Duration
+	// recentExitsMu protects recentExits.
+	recentExitsMu sync.Mutex
+	// recentExits maps image IDs to the last time a container using the
+	// image exited. Nil when exitGracePeriod is zero.
+	recentExits map[string]time.Time
+}
+
+// ImageGCStatus is the async image GC scheduler state reported through the
+// CRI status verbose info.
+type ImageGCStatus struct {
+	imageGCState
+	// DiskUsagePercent maps each image filesystem to how full it is in
+	// bytes.
+	DiskUsagePercent map[string]float64 `json:"diskUsagePercent,omitempty"`
+	// InodeUsagePercent maps each image filesystem to how full its inode
+	// table is, which overlay-heavy nodes often exhaust before bytes.
+	InodeUsagePercent map[string]float64 `json:"inodeUsagePercent,omitempty"`
+}
+
+// ImageGCStatus reports the async image GC scheduler state and the disk
+// usage of the image filesystems. enabled is false when AsyncImageGC is off.
+func (c *CRIImageService) ImageGCStatus() (status ImageGCStatus, enabled bool) {
+	if c.gcScheduler == nil {
+		return ImageGCStatus{}, false
+	}
+	status.imageGCState = c.gcScheduler.state()
+	for name, p := range c.imageFSPaths {
+		bytesPct, inodesPct, err := fsUsagePercent(p)
+		if err != nil {
+			continue
+		}
+		if status.DiskUsagePercent == nil {
+			status.DiskUsagePercent = make(map[string]float64)
+			status.InodeUsagePercent = make(map[string]float64)
+		}
+		status.DiskUsagePercent[name] = bytesPct
+		status.InodeUsagePercent[name] = inodesPct
+	}
+	return status, true
+}
+
+// TriggerImageGC requests an immediate pass over the pending reclamations,
+// skipping the scheduler's inter-task interval. It is a no-op when
+// AsyncImageGC is off.
+func (c *CRIImageService) TriggerImageGC() {
+	if c.gcScheduler != nil {
+		c.gcScheduler.kick()
+	}
+}
+
+// PullProgress describes an in-flight image pull for status reporting.
+type PullProgress struct {
+	// Ref is the reference being pulled.
+	Ref string `json:"ref"`
+	// ActiveRequests is the number of registry requests in flight.
+	ActiveRequests int32 `json:"activeRequests"`
+	// BytesRead is the total bytes read from the registry so far.
+	BytesRead uint64 `json:"bytesRead"`
+	// BytesPerSecond is the download rate averaged over the pull so far.
+	BytesPerSecond float64 `json:"bytesPerSecond"`
+}
+
+// ActivePullProgress snapshots the progress of all in-flight image pulls
+// for status reporting.
+func (c *CRIImageService) ActivePullProgress() []PullProgress {
+	c.pullProgressMu.Lock()
+	defer c.pullProgressMu.Unlock()
+	var progress []PullProgress
+	for reporter := range c.pullProgress {
+		progress = append(progress, reporter.progress())
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].Ref < progress[j].Ref })
+	return progress
+}
+
+// trackPullProgress registers the reporter of an in-flight pull for status
+// reporting. The returned func removes it when the pull finishes.
+func (c *CRIImageService) trackPullProgress(reporter *pullProgressReporter) func() {
+	c.pullProgressMu.Lock()
+	c.pullProgress[reporter] = struct{}{}
+	c.pullProgressMu.Unlock()
+	return func() {
+		c.pullProgressMu.Lock()
+		delete(c.pullProgress, reporter)
+		c.pullProgressMu.Unlock()
+	}
+}
+
+// RegistryReloadStatus reports the state of the dynamic registry config
+// syncer: the fields changed by the last reload that took effect and the
+// error of the last attempt. enabled is false when no dynamic registry
+// config path is configured.
+func (c *CRIImageService) RegistryReloadStatus() (diff []string, enabled bool, lastErr error) {
+	if c.registryConfMonitor == nil {
+		return nil, false, nil
+	}
+	return c.registryConfMonitor.lastAppliedDiff(), true, c.registryConfMonitor.lastStatus()
+}
+
+// registry returns the registry configuration currently in effect, which is
+// the dynamically reloaded one when a dynamic registry config path is
+// configured and the static configuration otherwise.
+func (c *CRIImageService) registry() criconfig.Registry {
+	if c.registryConfMonitor != nil {
+		return c.registryConfMonitor.current()
+	}
+	return c.config.Registry
 }
 
 type GRPCCRIImageService struct {
@@ -79,6 +223,9 @@ type CRIImageServiceOptions struct {
 
 	Images images.Store
 
+	// Publisher emits image GC events on the event service. May be nil.
+	Publisher events.Publisher
+
 	ImageFSPaths map[string]string
 
 	RuntimePlatforms map[string]ImagePlatform
@@ -103,11 +250,22 @@ func NewService(config criconfig.ImageConfig, options *CRIImageServiceOptions) (
 		config:                      config,
 		images:                      options.Images,
 		client:                      options.Client,
+		content:                     options.Content,
 		imageStore:                  imagestore.NewStore(options.Images, options.Content, platforms.Default()),
 		imageFSPaths:                options.ImageFSPaths,
 		runtimePlatforms:            options.RuntimePlatforms,
 		snapshotStore:               snapshotstore.NewStore(),
 		unpackDuplicationSuppressor: kmutex.New(),
+		inflight:                    newInflightPulls(),
+		pullProgress:                make(map[*pullProgressReporter]struct{}),
+	}
+
+	if limit := config.ImagePullGlobalBandwidthLimit; limit > 0 {
+		svc.globalPullLimiter = newRateLimiter(limit)
+	}
+
+	if limit := config.MaxConcurrentUnpacks; limit > 0 {
+		svc.unpackLimiter = semaphore.NewWeighted(int64(limit))
 	}
 
 	log.L.Info("Start snapshots syncer")
@@ -115,9 +273,45 @@ func NewService(config criconfig.ImageConfig, options *CRIImageServiceOptions) (
 		svc.snapshotStore,
 		options.Snapshotters,
 		time.Duration(svc.config.StatsCollectPeriod)*time.Second,
+		svc.config.StatsCollectWorkers,
 	)
 	snapshotsSyncer.start()
 
+	if config.ImageGCExitedGracePeriod != "" {
+		grace, err := time.ParseDuration(config.ImageGCExitedGracePeriod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse image_gc_exited_grace_period %q: %w", config.ImageGCExitedGracePeriod, err)
+		}
+		svc.exitGracePeriod = grace
+		svc.recentExits = make(map[string]time.Time)
+	}
+
+	if path := config.DynamicRegistryConfigPath; path != "" {
+		m, err := newRegistryConfSyncer(path, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create registry conf monitor: %w", err)
+		}
+		svc.registryConfMonitor = m
+		log.L.Infof("Start registry conf syncer for %s", path)
+		// Losing the syncer only loses runtime registry updates, so its
+		// exit is not fatal for the image service.
+		go func() {
+			if err := m.syncLoop(); err != nil {
+				log.L.WithError(err).Error("registry conf syncer exited")
+			}
+		}()
+	}
+
+	if config.AsyncImageGC {
+		minInterval, err := time.ParseDuration(config.ImageGCMinInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse image_gc_min_interval %q: %w", config.ImageGCMinInterval, err)
+		}
+		log.L.Info("Start async image gc scheduler")
+		svc.gcScheduler = newImageGCScheduler(svc.images, svc.imageStore, minInterval, &svc.activePulls, svc.inflight, options.Publisher, config.ImageGCDryRun)
+		svc.gcScheduler.start(namespaces.WithNamespace(context.Background(), constants.K8sContainerdNamespace))
+	}
+
 	return &svc, nil
 }
 
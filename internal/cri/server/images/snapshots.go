@@ -18,16 +18,26 @@ package images
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	snapshot "github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/internal/cputune"
+	crilabels "github.com/containerd/containerd/v2/internal/cri/labels"
 	snapshotstore "github.com/containerd/containerd/v2/internal/cri/store/snapshot"
 	ctrdutil "github.com/containerd/containerd/v2/internal/cri/util"
+	"github.com/containerd/containerd/v2/internal/debuglog"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 )
 
+// statsCallTimeout bounds a single call into a snapshotter during stats
+// collection, so one hanging snapshotter or one huge upper dir scan cannot
+// stall the whole sync pass.
+const statsCallTimeout = time.Minute
+
 // snapshotsSyncer syncs snapshot stats periodically. imagefs info and container stats
 // should both use cached result here.
 // TODO(random-liu): Benchmark with high workload. We may need a statsSyncer instead if
@@ -36,15 +46,19 @@ type snapshotsSyncer struct {
 	store        *snapshotstore.Store
 	snapshotters map[string]snapshot.Snapshotter
 	syncPeriod   time.Duration
+	// workers bounds the concurrent Usage calls per snapshotter, defaulting
+	// to the effective CPU count when not configured.
+	workers int
 }
 
 // newSnapshotsSyncer creates a snapshot syncer.
 func newSnapshotsSyncer(store *snapshotstore.Store, snapshotters map[string]snapshot.Snapshotter,
-	period time.Duration) *snapshotsSyncer {
+	period time.Duration, workers int) *snapshotsSyncer {
 	return &snapshotsSyncer{
 		store:        store,
 		snapshotters: snapshotters,
 		syncPeriod:   period,
+		workers:      workers,
 	}
 }
 
@@ -55,8 +69,6 @@ func (s *snapshotsSyncer) start() {
 	tick := time.NewTicker(s.syncPeriod)
 	go func() {
 		defer tick.Stop()
-		// TODO(random-liu): This is expensive. We should do benchmark to
-		// check the resource usage and optimize this.
 		for {
 			if err := s.sync(); err != nil {
 				log.L.WithError(err).Error("Failed to sync snapshot stats")
@@ -66,58 +78,28 @@ func (s *snapshotsSyncer) start() {
 	}()
 }
 
-// sync updates all snapshots stats.
+// sync updates all snapshots stats, walking the snapshotters concurrently.
 func (s *snapshotsSyncer) sync() error {
 	ctx := ctrdutil.NamespacedContext()
 	start := time.Now().UnixNano()
 
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
 	for key, snapshotter := range s.snapshotters {
-		var snapshots []snapshot.Info
-		// Do not call `Usage` directly in collect function, because
-		// `Usage` takes time, we don't want `Walk` to hold read lock
-		// of snapshot metadata store for too long time.
-		// TODO(random-liu): Set timeout for the following 2 contexts.
-		if err := snapshotter.Walk(ctx, func(ctx context.Context, info snapshot.Info) error {
-			snapshots = append(snapshots, info)
-			return nil
-		}); err != nil {
-			return fmt.Errorf("walk all snapshots for %q failed: %w", key, err)
-		}
-		for _, info := range snapshots {
-			snapshotKey := snapshotstore.Key{
-				Key:         info.Name,
-				Snapshotter: key,
-			}
-			sn, err := s.store.Get(snapshotKey)
-			if err == nil {
-				// Only update timestamp for non-active snapshot.
-				if sn.Kind == info.Kind && sn.Kind != snapshot.KindActive {
-					sn.Timestamp = time.Now().UnixNano()
-					s.store.Add(sn)
-					continue
-				}
-			}
-			// Get newest stats if the snapshot is new or active.
-			sn = snapshotstore.Snapshot{
-				Key: snapshotstore.Key{
-					Key:         info.Name,
-					Snapshotter: key,
-				},
-				Kind:      info.Kind,
-				Timestamp: time.Now().UnixNano(),
+		wg.Add(1)
+		go func(key string, snapshotter snapshot.Snapshotter) {
+			defer wg.Done()
+			if err := s.syncSnapshotter(ctx, key, snapshotter); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
 			}
-			usage, err := snapshotter.Usage(ctx, info.Name)
-			if err != nil {
-				if !errdefs.IsNotFound(err) {
-					log.L.WithError(err).Errorf("Failed to get usage for snapshot %q", info.Name)
-				}
-				continue
-			}
-			sn.Size = uint64(usage.Size)
-			sn.Inodes = uint64(usage.Inodes)
-			s.store.Add(sn)
-		}
+		}(key, snapshotter)
 	}
+	wg.Wait()
 
 	for _, sn := range s.store.List() {
 		if sn.Timestamp >= start {
@@ -126,5 +108,146 @@ func (s *snapshotsSyncer) sync() error {
 		// Delete the snapshot stats if it's not updated this time.
 		s.store.Delete(sn.Key)
 	}
+	debuglog.G(ctx, "snapshotter").Debugf("synced snapshot stats in %s", time.Duration(time.Now().UnixNano()-start))
+	return errors.Join(errs...)
+}
+
+// syncSnapshotter updates the stats of all snapshots of one snapshotter,
+// fanning the expensive Usage calls out to a bounded worker pool.
+func (s *snapshotsSyncer) syncSnapshotter(ctx context.Context, key string, snapshotter snapshot.Snapshotter) error {
+	syncStart := time.Now()
+	defer snapshotSyncDuration.WithValues(key).UpdateSince(syncStart)
+
+	var snapshots []snapshot.Info
+	// Do not call `Usage` directly in collect function, because
+	// `Usage` takes time, we don't want `Walk` to hold read lock
+	// of snapshot metadata store for too long time.
+	walkCtx, cancel := context.WithTimeout(ctx, statsCallTimeout)
+	err := snapshotter.Walk(walkCtx, func(ctx context.Context, info snapshot.Info) error {
+		snapshots = append(snapshots, info)
+		return nil
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("walk all snapshots for %q failed: %w", key, err)
+	}
+
+	parents := make(map[string]string, len(snapshots))
+	for _, info := range snapshots {
+		parents[info.Name] = info.Parent
+	}
+
+	var (
+		quota    = snapshot.WritableLayerQuotaFor(key)
+		obdStats = make(map[string]map[string]float64)
+		obdIO    = make(map[string]overlaybdDeviceIO)
+		// statsMu guards obdStats and obdIO against the workers.
+		statsMu sync.Mutex
+
+		infos = make(chan snapshot.Info)
+		wg    sync.WaitGroup
+	)
+	workers := cputune.Workers(s.workers)
+	if workers > len(snapshots) {
+		workers = len(snapshots)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for info := range infos {
+				if stats := parseOverlaybdStats(info.Labels); stats != nil {
+					statsMu.Lock()
+					obdStats[info.Name] = stats
+					statsMu.Unlock()
+				}
+				if io := collectOverlaybdIO(info.Labels); io.stats != nil {
+					statsMu.Lock()
+					obdIO[info.Name] = io
+					statsMu.Unlock()
+				}
+				s.syncSnapshot(ctx, key, snapshotter, quota, info, parents)
+			}
+		}()
+	}
+	for _, info := range snapshots {
+		infos <- info
+	}
+	close(infos)
+	wg.Wait()
+
+	overlaybdStats.replace(key, obdStats)
+	overlaybdIO.replace(key, obdIO)
 	return nil
 }
+
+// syncSnapshot updates the stats store entry of a single snapshot.
+func (s *snapshotsSyncer) syncSnapshot(ctx context.Context, key string, snapshotter snapshot.Snapshotter,
+	quota snapshot.WritableLayerQuota, info snapshot.Info, parents map[string]string) {
+	snapshotKey := snapshotstore.Key{
+		Key:         info.Name,
+		Snapshotter: key,
+	}
+	sn, err := s.store.Get(snapshotKey)
+	if err == nil {
+		// Only update timestamp for non-active snapshot.
+		if sn.Kind == info.Kind && sn.Kind != snapshot.KindActive {
+			sn.Timestamp = time.Now().UnixNano()
+			s.store.Add(sn)
+			return
+		}
+	}
+	// Get newest stats if the snapshot is new or active.
+	sn = snapshotstore.Snapshot{
+		Key:         snapshotKey,
+		Kind:        info.Kind,
+		Parent:      info.Parent,
+		ParentChain: parentChain(info.Name, parents),
+		ImageRef:    info.Labels[crilabels.SnapshotImageRefLabel],
+		Driver:      key,
+		Timestamp:   time.Now().UnixNano(),
+	}
+	// An active snapshot under a quota reports usage through the
+	// snapshotter's quota handler when one is registered.
+	if quota != nil && info.Kind == snapshot.KindActive {
+		if dir := info.Labels[snapshot.LabelWritableLayerPath]; dir != "" {
+			quotaCtx, cancel := context.WithTimeout(ctx, statsCallTimeout)
+			u, err := quota.Usage(quotaCtx, dir)
+			cancel()
+			if err == nil {
+				sn.Size = uint64(u.Size)
+				sn.Inodes = uint64(u.Inodes)
+				s.store.Add(sn)
+				return
+			} else if !errdefs.IsNotImplemented(err) {
+				log.L.WithError(err).Errorf("Failed to get quota usage for snapshot %q", info.Name)
+			}
+		}
+	}
+	usageCtx, cancel := context.WithTimeout(ctx, statsCallTimeout)
+	usage, err := snapshotter.Usage(usageCtx, info.Name)
+	cancel()
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			log.L.WithError(err).Errorf("Failed to get usage for snapshot %q", info.Name)
+		}
+		return
+	}
+	sn.Size = uint64(usage.Size)
+	sn.Inodes = uint64(usage.Inodes)
+	s.store.Add(sn)
+}
+
+// parentChain returns the keys of the ancestors of the named snapshot,
+// nearest first, from the parent mapping of one walk pass.
+func parentChain(name string, parents map[string]string) []string {
+	var chain []string
+	for p := parents[name]; p != ""; p = parents[p] {
+		chain = append(chain, p)
+		if len(chain) > len(parents) {
+			// Defend against a corrupt mapping with a parent cycle.
+			break
+		}
+	}
+	return chain
+}
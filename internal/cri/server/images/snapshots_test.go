@@ -0,0 +1,39 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package images
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentChain(t *testing.T) {
+	parents := map[string]string{
+		"layer1":    "",
+		"layer2":    "layer1",
+		"layer3":    "layer2",
+		"container": "layer3",
+	}
+	assert.Equal(t, []string{"layer3", "layer2", "layer1"}, parentChain("container", parents))
+	assert.Empty(t, parentChain("layer1", parents))
+	assert.Empty(t, parentChain("unknown", parents))
+
+	// A parent cycle must not hang the syncer.
+	cycle := map[string]string{"a": "b", "b": "a"}
+	assert.NotEmpty(t, parentChain("a", cycle))
+}
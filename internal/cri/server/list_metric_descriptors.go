@@ -19,11 +19,21 @@ package server
 import (
 	"context"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
+// ListMetricDescriptors returns the descriptors of the metrics which will be
+// served by ListPodSandboxMetrics, restricted to the configured metric set.
 func (c *criService) ListMetricDescriptors(context.Context, *runtime.ListMetricDescriptorsRequest) (*runtime.ListMetricDescriptorsResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListMetricDescriptors not implemented")
+	descriptors := make([]*runtime.MetricDescriptor, 0, len(podSandboxMetrics))
+	for _, m := range podSandboxMetrics {
+		if !c.metricEnabled(m.name) {
+			continue
+		}
+		descriptors = append(descriptors, &runtime.MetricDescriptor{
+			Name: m.name,
+			Help: m.help,
+		})
+	}
+	return &runtime.ListMetricDescriptorsResponse{Descriptors: descriptors}, nil
 }
@@ -28,6 +28,7 @@ var (
 	sandboxRuntimeCreateTimer metrics.LabeledTimer
 	sandboxRuntimeStopTimer   metrics.LabeledTimer
 	sandboxRemoveTimer        metrics.LabeledTimer
+	sandboxStopContainerTimer metrics.LabeledTimer
 
 	containerListTimer          metrics.Timer
 	containerRemoveTimer        metrics.LabeledTimer
@@ -40,6 +41,19 @@ var (
 	networkPluginOperations        metrics.LabeledCounter
 	networkPluginOperationsErrors  metrics.LabeledCounter
 	networkPluginOperationsLatency metrics.LabeledTimer
+
+	networkOrphanedAttachments metrics.Counter
+	networkOrphanedNetNS       metrics.Counter
+
+	networkProbeFailures metrics.Counter
+	networkProbeRepairs  metrics.Counter
+
+	storageHealthFailures metrics.LabeledCounter
+
+	numaCrossPlacements metrics.Counter
+
+	snapshotRetrySuccesses metrics.Counter
+	snapshotRetryFailures  metrics.Counter
 )
 
 func init() {
@@ -53,6 +67,7 @@ func init() {
 	sandboxRuntimeCreateTimer = ns.NewLabeledTimer("sandbox_runtime_create", "time to create a sandbox in the runtime", "runtime")
 	sandboxRuntimeStopTimer = ns.NewLabeledTimer("sandbox_runtime_stop", "time to stop a sandbox", "runtime")
 	sandboxRemoveTimer = ns.NewLabeledTimer("sandbox_remove", "time to remove a sandbox", "runtime")
+	sandboxStopContainerTimer = ns.NewLabeledTimer("sandbox_stop_container", "time to stop one container while stopping its sandbox", "runtime")
 
 	containerListTimer = ns.NewTimer("container_list", "time to list containers")
 	containerRemoveTimer = ns.NewLabeledTimer("container_remove", "time to remove a container", "runtime")
@@ -66,6 +81,19 @@ func init() {
 	networkPluginOperationsErrors = ns.NewLabeledCounter("network_plugin_operations_errors_total", "cumulative number of network plugin operations by operation type", "operation_type")
 	networkPluginOperationsLatency = ns.NewLabeledTimer("network_plugin_operations_duration_seconds", "latency in seconds of network plugin operations. Broken down by operation type", "operation_type")
 
+	networkOrphanedAttachments = ns.NewCounter("network_orphaned_attachments_reclaimed", "cumulative number of leaked CNI attachments torn down by network reconciliation")
+	networkOrphanedNetNS = ns.NewCounter("network_orphaned_netns_removed", "cumulative number of orphaned network namespaces removed by network reconciliation")
+
+	networkProbeFailures = ns.NewCounter("network_probe_failures", "cumulative number of ready sandboxes whose network probe found a broken network")
+	networkProbeRepairs = ns.NewCounter("network_probe_repairs", "cumulative number of sandbox networks re-established by invoking CNI setup again")
+
+	storageHealthFailures = ns.NewLabeledCounter("storage_health_failures", "cumulative number of storage paths transitioning to unhealthy, by path and reason", "path", "reason")
+
+	numaCrossPlacements = ns.NewCounter("numa_cross_placements", "cumulative number of containers which requested NUMA pinning but could not be constrained to a single NUMA node")
+
+	snapshotRetrySuccesses = ns.NewCounter("snapshot_retry_successes", "cumulative number of container snapshots prepared successfully after retrying a transient error")
+	snapshotRetryFailures = ns.NewCounter("snapshot_retry_failures", "cumulative number of container snapshot preparations which still failed after retrying")
+
 	metrics.Register(ns)
 }
 
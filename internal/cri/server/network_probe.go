@@ -0,0 +1,138 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+// startNetworkProber periodically validates the network of ready sandboxes
+// and re-invokes CNI setup for sandboxes found broken, when
+// `network_probe_period` is configured.
+func (c *criService) startNetworkProber(ctx context.Context) {
+	if c.config.NetworkProbePeriod == "" {
+		return
+	}
+	period, err := time.ParseDuration(c.config.NetworkProbePeriod)
+	if err != nil {
+		// Validated at config load time.
+		log.G(ctx).WithError(err).Error("Invalid network probe period")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			c.probeNetworks(ctx)
+		}
+	}()
+}
+
+// probeNetworks checks the network of every ready sandbox and attempts a CNI
+// re-setup for sandboxes whose network is broken, for example after a CNI
+// plugin upgrade flushed interfaces or routes.
+func (c *criService) probeNetworks(ctx context.Context) {
+	for _, sandbox := range c.sandboxStore.List() {
+		if sandbox.Status.Get().State != sandboxstore.StateReady {
+			continue
+		}
+		if sandbox.NetNSPath == "" || sandbox.NetNS == nil {
+			continue
+		}
+		// A removed network namespace belongs to a sandbox being torn
+		// down, not to a degraded one.
+		if closed, err := sandbox.NetNS.Closed(); err != nil || closed {
+			continue
+		}
+		err := probeSandboxNetwork(ctx, sandbox.NetNSPath)
+		if err == nil {
+			continue
+		}
+		networkProbeFailures.Inc()
+		log.G(ctx).WithError(err).Warnf("Network of sandbox %q is broken, re-invoking CNI setup", sandbox.ID)
+		if err := c.repairSandboxNetwork(ctx, sandbox); err != nil {
+			log.G(ctx).WithError(err).Errorf("Failed to repair network of sandbox %q", sandbox.ID)
+			continue
+		}
+		networkProbeRepairs.Inc()
+		log.G(ctx).Infof("Re-established network of sandbox %q", sandbox.ID)
+		// Nudge event subscribers to resync the pod now that its network
+		// changed underneath it.
+		c.generateAndSendContainerEvent(ctx, sandbox.ID, sandbox.ID, runtime.ContainerEventType_CONTAINER_STARTED_EVENT)
+	}
+}
+
+// repairSandboxNetwork re-invokes CNI for a sandbox whose network probe
+// failed. CNI CHECK is run first so plugin diagnostics end up in the log,
+// then setup is invoked again; plugins are required to treat a repeated ADD
+// of the same attachment as idempotent.
+func (c *criService) repairSandboxNetwork(ctx context.Context, sandbox sandboxstore.Sandbox) error {
+	netPlugin := c.getNetworkPlugin(sandbox.RuntimeHandler)
+	if netPlugin == nil {
+		return errors.New("cni config not initialized")
+	}
+	id := sandbox.ID
+	opts, err := c.cniNamespaceOpts(id, sandbox.Config)
+	if err != nil {
+		return fmt.Errorf("get cni namespace options: %w", err)
+	}
+
+	if err := netPlugin.Check(ctx, id, sandbox.NetNSPath, opts...); err != nil {
+		log.G(ctx).WithError(err).Debugf("CNI check for sandbox %q", id)
+	}
+
+	netStart := time.Now()
+	var result *cni.Result
+	if c.config.CniConfig.NetworkPluginSetupSerially {
+		result, err = netPlugin.SetupSerially(ctx, id, sandbox.NetNSPath, opts...)
+	} else {
+		result, err = netPlugin.Setup(ctx, id, sandbox.NetNSPath, opts...)
+	}
+	networkPluginOperations.WithValues(networkSetUpOp).Inc()
+	networkPluginOperationsLatency.WithValues(networkSetUpOp).UpdateSince(netStart)
+	if err != nil {
+		networkPluginOperationsErrors.WithValues(networkSetUpOp).Inc()
+		return err
+	}
+	logDebugCNIResult(ctx, id, result)
+
+	if err := c.setupAdditionalNetworks(ctx, &sandbox); err != nil {
+		return fmt.Errorf("failed to re-attach additional networks: %w", err)
+	}
+
+	// Refresh the persisted CNI result so a later teardown matches the
+	// re-established attachment.
+	sandbox.CNIResult = result
+	if err := c.storeCNIResult(&sandbox); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to persist CNI result of sandbox %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+	"github.com/vishvananda/netlink"
+)
+
+// probeSandboxNetwork validates the network inside the sandbox network
+// namespace: the default interface must exist and be up, carry at least one
+// address and have a default route.
+func probeSandboxNetwork(ctx context.Context, netNSPath string) error {
+	return ns.WithNetNSPath(netNSPath, func(_ ns.NetNS) error {
+		link, err := netlink.LinkByName(defaultIfName)
+		if err != nil {
+			return fmt.Errorf("default interface %q missing: %w", defaultIfName, err)
+		}
+		if link.Attrs().Flags&net.FlagUp == 0 {
+			return fmt.Errorf("default interface %q is down", defaultIfName)
+		}
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("failed to list addresses of %q: %w", defaultIfName, err)
+		}
+		hasAddr := false
+		for _, addr := range addrs {
+			if !addr.IP.IsLinkLocalUnicast() {
+				hasAddr = true
+				break
+			}
+		}
+		if !hasAddr {
+			return fmt.Errorf("default interface %q has no address assigned", defaultIfName)
+		}
+		routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("failed to list routes: %w", err)
+		}
+		for _, route := range routes {
+			if route.Dst == nil || route.Dst.IP.IsUnspecified() {
+				return nil
+			}
+		}
+		return fmt.Errorf("no default route present")
+	})
+}
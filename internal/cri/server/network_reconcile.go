@@ -0,0 +1,182 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containerd/log"
+
+	"github.com/containerd/containerd/v2/pkg/netns"
+)
+
+const (
+	// cniResultsCacheDir is the directory libcni caches attachment results
+	// in. Each attachment of a live or leaked sandbox has one file here, and
+	// tearing the attachment down releases its IPAM lease.
+	cniResultsCacheDir = "/var/lib/cni/results"
+	// networkOrphanGracePeriod is how old CNI state must be before it is
+	// considered leaked, so reconciliation does not race with sandboxes
+	// currently being set up.
+	networkOrphanGracePeriod = 5 * time.Minute
+)
+
+// startNetworkReconciler periodically tears down CNI attachments and network
+// namespaces whose sandbox no longer exists, typically left behind by CNI
+// plugin crashes, when `network_reconcile_period` is configured.
+func (c *criService) startNetworkReconciler(ctx context.Context) {
+	if c.config.NetworkReconcilePeriod == "" {
+		return
+	}
+	period, err := time.ParseDuration(c.config.NetworkReconcilePeriod)
+	if err != nil {
+		// Validated at config load time.
+		log.G(ctx).WithError(err).Error("Invalid network reconcile period")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			c.reconcileNetworks(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// reconcileNetworks tears down leaked CNI attachments and orphaned network
+// namespaces and reports how much was reclaimed.
+func (c *criService) reconcileNetworks(ctx context.Context) {
+	attachments := c.reconcileCNICache(ctx)
+	namespaces := c.reconcileNetNSDir(ctx)
+	if attachments > 0 || namespaces > 0 {
+		log.G(ctx).Infof("Network reconciliation reclaimed %d leaked CNI attachments and %d orphaned network namespaces",
+			attachments, namespaces)
+	}
+}
+
+// cniCachedAttachment is the subset of the libcni result cache file format
+// needed to identify the attachment's sandbox.
+type cniCachedAttachment struct {
+	Kind        string `json:"kind"`
+	ContainerID string `json:"containerId"`
+	NetNS       string `json:"netns"`
+}
+
+// reconcileCNICache invokes CNI DEL for cached attachments whose sandbox no
+// longer exists, releasing their IPAM leases. It returns the number of
+// sandboxes whose attachments were reclaimed.
+func (c *criService) reconcileCNICache(ctx context.Context) int {
+	entries, err := os.ReadDir(cniResultsCacheDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.G(ctx).WithError(err).Error("Failed to read CNI results cache")
+		}
+		return 0
+	}
+	// netns per orphaned sandbox id; one DEL per sandbox tears down all of
+	// its cached attachments.
+	orphans := map[string]string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < networkOrphanGracePeriod {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cniResultsCacheDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var attachment cniCachedAttachment
+		if err := json.Unmarshal(data, &attachment); err != nil || attachment.ContainerID == "" {
+			continue
+		}
+		if _, err := c.sandboxStore.Get(attachment.ContainerID); err == nil {
+			continue
+		}
+		orphans[attachment.ContainerID] = attachment.NetNS
+	}
+
+	reclaimed := 0
+	for id, nsPath := range orphans {
+		netPlugin := c.getNetworkPlugin("")
+		if netPlugin == nil {
+			break
+		}
+		if err := netPlugin.Remove(ctx, id, nsPath); err != nil {
+			log.G(ctx).WithError(err).Errorf("Failed to tear down leaked CNI attachments of sandbox %q", id)
+			continue
+		}
+		networkOrphanedAttachments.Inc()
+		reclaimed++
+	}
+	return reclaimed
+}
+
+// reconcileNetNSDir removes network namespace mounts not referenced by any
+// sandbox. It returns the number of namespaces removed.
+func (c *criService) reconcileNetNSDir(ctx context.Context) int {
+	netnsMountDir := "/var/run/netns"
+	if c.config.NetNSMountsUnderStateDir {
+		netnsMountDir = filepath.Join(c.config.StateDir, "netns")
+	}
+	entries, err := os.ReadDir(netnsMountDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.G(ctx).WithError(err).Error("Failed to read netns mount directory")
+		}
+		return 0
+	}
+
+	referenced := map[string]struct{}{}
+	for _, sandbox := range c.sandboxStore.List() {
+		if sandbox.NetNSPath != "" {
+			referenced[sandbox.NetNSPath] = struct{}{}
+		}
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		// Only touch namespaces created by this plugin.
+		if !strings.HasPrefix(entry.Name(), "cni-") {
+			continue
+		}
+		nsPath := filepath.Join(netnsMountDir, entry.Name())
+		if _, ok := referenced[nsPath]; ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < networkOrphanGracePeriod {
+			continue
+		}
+		if err := netns.LoadNetNS(nsPath).Remove(); err != nil {
+			log.G(ctx).WithError(err).Errorf("Failed to remove orphaned network namespace %q", nsPath)
+			continue
+		}
+		networkOrphanedNetNS.Inc()
+		removed++
+	}
+	return removed
+}
@@ -0,0 +1,136 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// numaSysfsRoot is where the kernel exposes the NUMA topology.
+const numaSysfsRoot = "/sys/devices/system/node"
+
+// NUMA placement policies configurable per runtime.
+const (
+	// numaPolicyPacked fills one NUMA node with pods before using the next.
+	numaPolicyPacked = "packed"
+	// numaPolicySpread balances pods across NUMA nodes.
+	numaPolicySpread = "spread"
+)
+
+// numaNode is one NUMA node of the machine.
+type numaNode struct {
+	id   int
+	cpus string
+}
+
+// numaAllocator places pods on NUMA nodes, constraining their containers to
+// the CPUs and memory of a single node. Placements are tracked per pod so all
+// containers of a pod land on the same node.
+type numaAllocator struct {
+	mu    sync.Mutex
+	nodes []numaNode
+	pods  map[string]int
+}
+
+// newNUMAAllocator discovers the machine's NUMA topology from sysfsRoot. It
+// returns nil when the machine has fewer than two NUMA nodes, there is
+// nothing to place then.
+func newNUMAAllocator(sysfsRoot string) (*numaAllocator, error) {
+	entries, err := os.ReadDir(sysfsRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read NUMA topology: %w", err)
+	}
+	var nodes []numaNode
+	for _, entry := range entries {
+		id, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), "node"))
+		if !strings.HasPrefix(entry.Name(), "node") || err != nil {
+			continue
+		}
+		cpus, err := os.ReadFile(filepath.Join(sysfsRoot, entry.Name(), "cpulist"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cpulist of NUMA node %d: %w", id, err)
+		}
+		nodes = append(nodes, numaNode{id: id, cpus: strings.TrimSpace(string(cpus))})
+	}
+	if len(nodes) < 2 {
+		return nil, nil
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	return &numaAllocator{
+		nodes: nodes,
+		pods:  make(map[string]int),
+	}, nil
+}
+
+// place chooses a NUMA node for the pod according to policy and returns its
+// CPU list and node id, both also usable as cpuset.mems. Placing a pod which
+// already has a node returns the existing placement.
+func (a *numaAllocator) place(podID, policy string) (cpus, mems string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if policy != numaPolicyPacked && policy != numaPolicySpread {
+		return "", "", fmt.Errorf("unknown NUMA policy %q, expected %q or %q", policy, numaPolicyPacked, numaPolicySpread)
+	}
+
+	if id, ok := a.pods[podID]; ok {
+		return a.node(id).cpus, strconv.Itoa(id), nil
+	}
+
+	counts := make(map[int]int, len(a.nodes))
+	for _, id := range a.pods {
+		counts[id]++
+	}
+	best := a.nodes[0]
+	for _, node := range a.nodes[1:] {
+		// Ties go to the lowest node id either way.
+		if policy == numaPolicyPacked && counts[node.id] > counts[best.id] {
+			// Prefer the fullest node, filling it up before the next.
+			best = node
+		} else if policy == numaPolicySpread && counts[node.id] < counts[best.id] {
+			best = node
+		}
+	}
+
+	a.pods[podID] = best.id
+	return best.cpus, strconv.Itoa(best.id), nil
+}
+
+// release drops the pod's placement.
+func (a *numaAllocator) release(podID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.pods, podID)
+}
+
+func (a *numaAllocator) node(id int) numaNode {
+	for _, node := range a.nodes {
+		if node.id == id {
+			return node
+		}
+	}
+	return numaNode{}
+}
@@ -0,0 +1,123 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNUMATopology(t *testing.T, nodes map[int]string) string {
+	root := t.TempDir()
+	for id, cpus := range nodes {
+		dir := filepath.Join(root, fmt.Sprintf("node%d", id))
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cpulist"), []byte(cpus+"\n"), 0644))
+	}
+	return root
+}
+
+func TestNewNUMAAllocator(t *testing.T) {
+	t.Run("missing topology", func(t *testing.T) {
+		a, err := newNUMAAllocator(filepath.Join(t.TempDir(), "nonexistent"))
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("single node", func(t *testing.T) {
+		a, err := newNUMAAllocator(writeNUMATopology(t, map[int]string{0: "0-7"}))
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("two nodes", func(t *testing.T) {
+		a, err := newNUMAAllocator(writeNUMATopology(t, map[int]string{0: "0-7", 1: "8-15"}))
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		assert.Equal(t, []numaNode{{id: 0, cpus: "0-7"}, {id: 1, cpus: "8-15"}}, a.nodes)
+	})
+}
+
+func TestNUMAPlacement(t *testing.T) {
+	newAllocator := func(t *testing.T) *numaAllocator {
+		a, err := newNUMAAllocator(writeNUMATopology(t, map[int]string{0: "0-7", 1: "8-15"}))
+		require.NoError(t, err)
+		require.NotNil(t, a)
+		return a
+	}
+
+	t.Run("packed fills one node first", func(t *testing.T) {
+		a := newAllocator(t)
+		for _, pod := range []string{"p1", "p2", "p3"} {
+			cpus, mems, err := a.place(pod, numaPolicyPacked)
+			require.NoError(t, err)
+			assert.Equal(t, "0-7", cpus, pod)
+			assert.Equal(t, "0", mems, pod)
+		}
+	})
+
+	t.Run("spread balances across nodes", func(t *testing.T) {
+		a := newAllocator(t)
+		_, mems, err := a.place("p1", numaPolicySpread)
+		require.NoError(t, err)
+		assert.Equal(t, "0", mems)
+		cpus, mems, err := a.place("p2", numaPolicySpread)
+		require.NoError(t, err)
+		assert.Equal(t, "8-15", cpus)
+		assert.Equal(t, "1", mems)
+	})
+
+	t.Run("placement is stable per pod", func(t *testing.T) {
+		a := newAllocator(t)
+		_, first, err := a.place("p1", numaPolicySpread)
+		require.NoError(t, err)
+		_, _, err = a.place("p2", numaPolicySpread)
+		require.NoError(t, err)
+		_, again, err := a.place("p1", numaPolicySpread)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+	})
+
+	t.Run("release frees the node", func(t *testing.T) {
+		a := newAllocator(t)
+		_, _, err := a.place("p1", numaPolicySpread)
+		require.NoError(t, err)
+		a.release("p1")
+		_, mems, err := a.place("p2", numaPolicySpread)
+		require.NoError(t, err)
+		assert.Equal(t, "0", mems)
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		a := newAllocator(t)
+		_, _, err := a.place("p1", "interleave")
+		assert.ErrorContains(t, err, "unknown NUMA policy")
+	})
+}
+
+func TestCPUsWithin(t *testing.T) {
+	assert.True(t, cpusWithin("4-5", "0-7"))
+	assert.True(t, cpusWithin("0,7", "0-7"))
+	assert.False(t, cpusWithin("6-9", "0-7"))
+	assert.False(t, cpusWithin("8", "0-7"))
+	assert.False(t, cpusWithin("", "0-7"))
+}
@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"github.com/containerd/log"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// podCgroupCPUPeriod is the CPU period pod-level quotas are normalized to.
+const podCgroupCPUPeriod = 100000
+
+// reconcilePodCgroup sizes the pod-level cgroup of the sandbox to the pod's
+// resources: the kubelet provided pod resources when present, and the sum of
+// the pod's container resources plus the pod overhead otherwise. It is a
+// no-op when pod cgroup management is disabled or the sandbox has no cgroup
+// parent, and best effort otherwise.
+func (c *criService) reconcilePodCgroup(sandboxID string) {
+	if c.podCgroups == nil {
+		return
+	}
+	sandbox, err := c.sandboxStore.Get(sandboxID)
+	if err != nil {
+		return
+	}
+	linux := sandbox.Config.GetLinux()
+	if linux.GetCgroupParent() == "" {
+		return
+	}
+	target := linux.GetResources()
+	if target == nil {
+		target = c.sumPodResources(sandboxID, linux.GetOverhead())
+	}
+	if err := c.podCgroups.Reconcile(linux.GetCgroupParent(), target); err != nil {
+		log.L.WithError(err).Warnf("Failed to reconcile pod cgroup %q", linux.GetCgroupParent())
+	}
+}
+
+// sumPodResources adds up the resources of the pod's containers plus the pod
+// overhead. A limit stays unset when any container doesn't set it, the pod is
+// unlimited then.
+func (c *criService) sumPodResources(sandboxID string, overhead *runtime.LinuxContainerResources) *runtime.LinuxContainerResources {
+	sum := &runtime.LinuxContainerResources{CpuPeriod: podCgroupCPUPeriod}
+	cpuLimited, memLimited := true, true
+	for _, cntr := range c.containerStore.List() {
+		if cntr.SandboxID != sandboxID {
+			continue
+		}
+		res := cntr.Config.GetLinux().GetResources()
+		sum.CpuShares += res.GetCpuShares()
+		if res.GetCpuQuota() > 0 && res.GetCpuPeriod() > 0 {
+			sum.CpuQuota += res.GetCpuQuota() * podCgroupCPUPeriod / res.GetCpuPeriod()
+		} else {
+			cpuLimited = false
+		}
+		if res.GetMemoryLimitInBytes() > 0 {
+			sum.MemoryLimitInBytes += res.GetMemoryLimitInBytes()
+		} else {
+			memLimited = false
+		}
+	}
+	sum.CpuShares += overhead.GetCpuShares()
+	if cpuLimited {
+		if overhead.GetCpuQuota() > 0 && overhead.GetCpuPeriod() > 0 {
+			sum.CpuQuota += overhead.GetCpuQuota() * podCgroupCPUPeriod / overhead.GetCpuPeriod()
+		}
+	} else {
+		sum.CpuQuota = 0
+	}
+	if memLimited {
+		sum.MemoryLimitInBytes += overhead.GetMemoryLimitInBytes()
+	} else {
+		sum.MemoryLimitInBytes = 0
+	}
+	return sum
+}
@@ -0,0 +1,176 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/containerd/cgroups/v3"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// podCgroupManager creates, sizes and removes pod-level cgroups on behalf of
+// a kubelet which delegates pod cgroup management. It handles both the
+// cgroupfs and the systemd cgroup driver naming, on cgroup v1 and v2.
+type podCgroupManager struct {
+	mountpoint string
+	unified    bool
+}
+
+func newPodCgroupManager() *podCgroupManager {
+	return &podCgroupManager{
+		mountpoint: "/sys/fs/cgroup",
+		unified:    cgroups.Mode() == cgroups.Unified,
+	}
+}
+
+// paths returns the cgroupfs directories backing the pod-level cgroup: the
+// unified directory on cgroup v2, and the cpu and memory controller
+// directories on cgroup v1.
+func (m *podCgroupManager) paths(cgroupParent string) []string {
+	path := cgroupParent
+	if strings.HasSuffix(path, ".slice") {
+		path = expandSystemdSlice(filepath.Base(path))
+	}
+	if m.unified {
+		return []string{filepath.Join(m.mountpoint, path)}
+	}
+	return []string{
+		filepath.Join(m.mountpoint, "cpu", path),
+		filepath.Join(m.mountpoint, "memory", path),
+	}
+}
+
+// expandSystemdSlice expands a systemd slice name to its cgroupfs path, e.g.
+// "kubepods-besteffort-pod1.slice" becomes
+// "kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1.slice".
+func expandSystemdSlice(slice string) string {
+	var path, prefix string
+	for _, part := range strings.Split(strings.TrimSuffix(slice, ".slice"), "-") {
+		if prefix != "" {
+			prefix += "-"
+		}
+		prefix += part
+		path = filepath.Join(path, prefix+".slice")
+	}
+	return path
+}
+
+// Ensure creates the pod-level cgroup when it is missing and validates that
+// an existing one is usable. The expanded path places the cgroup under its
+// expected parent, so a mis-parented leftover is simply not found and a
+// correctly parented one is created.
+func (m *podCgroupManager) Ensure(cgroupParent string) error {
+	for _, dir := range m.paths(cgroupParent) {
+		if info, err := os.Stat(dir); err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("pod cgroup %q exists but is not a directory", dir)
+			}
+			continue
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat pod cgroup %q: %w", dir, err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create pod cgroup %q: %w", dir, err)
+		}
+		if m.unified {
+			// Delegate the cpu and memory controllers to the new cgroup.
+			// Best effort, the parent may already delegate them.
+			_ = os.WriteFile(filepath.Join(filepath.Dir(dir), "cgroup.subtree_control"), []byte("+cpu +memory"), 0)
+		}
+	}
+	return nil
+}
+
+// Reconcile sets the pod-level cgroup's CPU and memory limits to res. Unset
+// limits in res lift the corresponding cgroup limit.
+func (m *podCgroupManager) Reconcile(cgroupParent string, res *runtime.LinuxContainerResources) error {
+	dirs := m.paths(cgroupParent)
+	if m.unified {
+		dir := dirs[0]
+		cpuMax := "max"
+		if res.GetCpuQuota() > 0 {
+			cpuMax = strconv.FormatInt(res.GetCpuQuota(), 10)
+		}
+		period := res.GetCpuPeriod()
+		if period <= 0 {
+			period = podCgroupCPUPeriod
+		}
+		if err := writeCgroupFile(dir, "cpu.max", fmt.Sprintf("%s %d", cpuMax, period)); err != nil {
+			return err
+		}
+		if shares := res.GetCpuShares(); shares > 0 {
+			weight := 1 + ((shares-2)*9999)/262142
+			if err := writeCgroupFile(dir, "cpu.weight", strconv.FormatInt(weight, 10)); err != nil {
+				return err
+			}
+		}
+		memMax := "max"
+		if res.GetMemoryLimitInBytes() > 0 {
+			memMax = strconv.FormatInt(res.GetMemoryLimitInBytes(), 10)
+		}
+		return writeCgroupFile(dir, "memory.max", memMax)
+	}
+
+	cpuDir, memDir := dirs[0], dirs[1]
+	quota := res.GetCpuQuota()
+	if quota <= 0 {
+		quota = -1
+	}
+	if err := writeCgroupFile(cpuDir, "cpu.cfs_quota_us", strconv.FormatInt(quota, 10)); err != nil {
+		return err
+	}
+	period := res.GetCpuPeriod()
+	if period <= 0 {
+		period = podCgroupCPUPeriod
+	}
+	if err := writeCgroupFile(cpuDir, "cpu.cfs_period_us", strconv.FormatInt(period, 10)); err != nil {
+		return err
+	}
+	if shares := res.GetCpuShares(); shares > 0 {
+		if err := writeCgroupFile(cpuDir, "cpu.shares", strconv.FormatInt(shares, 10)); err != nil {
+			return err
+		}
+	}
+	limit := res.GetMemoryLimitInBytes()
+	if limit <= 0 {
+		limit = -1
+	}
+	return writeCgroupFile(memDir, "memory.limit_in_bytes", strconv.FormatInt(limit, 10))
+}
+
+// Remove removes the pod-level cgroup. The container cgroups below it are
+// removed by their runtime, so by pod removal the directories are empty.
+func (m *podCgroupManager) Remove(cgroupParent string) error {
+	for _, dir := range m.paths(cgroupParent) {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove pod cgroup %q: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+func writeCgroupFile(dir, name, value string) error {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Join(dir, name), err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestExpandSystemdSlice(t *testing.T) {
+	assert.Equal(t, "kubepods.slice", expandSystemdSlice("kubepods.slice"))
+	assert.Equal(t,
+		"kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1.slice",
+		expandSystemdSlice("kubepods-besteffort-pod1.slice"))
+}
+
+func TestPodCgroupPaths(t *testing.T) {
+	m := &podCgroupManager{mountpoint: "/sys/fs/cgroup", unified: true}
+	assert.Equal(t,
+		[]string{"/sys/fs/cgroup/kubepods/besteffort/pod1"},
+		m.paths("/kubepods/besteffort/pod1"))
+	assert.Equal(t,
+		[]string{"/sys/fs/cgroup/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-pod1.slice"},
+		m.paths("kubepods-besteffort-pod1.slice"))
+
+	m.unified = false
+	assert.Equal(t,
+		[]string{
+			"/sys/fs/cgroup/cpu/kubepods/pod1",
+			"/sys/fs/cgroup/memory/kubepods/pod1",
+		},
+		m.paths("/kubepods/pod1"))
+}
+
+func TestPodCgroupEnsureReconcileRemove(t *testing.T) {
+	m := &podCgroupManager{mountpoint: t.TempDir(), unified: true}
+	parent := "/kubepods/pod1"
+
+	require.NoError(t, m.Ensure(parent))
+	dir := m.paths(parent)[0]
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	// Ensure on an existing cgroup is a no-op.
+	require.NoError(t, m.Ensure(parent))
+
+	require.NoError(t, m.Reconcile(parent, &runtime.LinuxContainerResources{
+		CpuQuota:           200000,
+		CpuPeriod:          100000,
+		CpuShares:          2048,
+		MemoryLimitInBytes: 1 << 30,
+	}))
+	cpuMax, err := os.ReadFile(filepath.Join(dir, "cpu.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "200000 100000", string(cpuMax))
+	memMax, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "1073741824", string(memMax))
+
+	// Unset limits are lifted.
+	require.NoError(t, m.Reconcile(parent, &runtime.LinuxContainerResources{}))
+	cpuMax, err = os.ReadFile(filepath.Join(dir, "cpu.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "max 100000", string(cpuMax))
+	memMax, err = os.ReadFile(filepath.Join(dir, "memory.max"))
+	require.NoError(t, err)
+	assert.Equal(t, "max", string(memMax))
+
+	// Remove only the cgroup's own files, then the directory.
+	for _, f := range []string{"cpu.max", "cpu.weight", "memory.max", "cgroup.subtree_control"} {
+		os.Remove(filepath.Join(dir, f))
+	}
+	require.NoError(t, m.Remove(parent))
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	// Removing a missing cgroup is a no-op.
+	require.NoError(t, m.Remove(parent))
+}
+
+func TestSumPodResources(t *testing.T) {
+	c := newTestCRIService()
+	sum := c.sumPodResources("nonexistent", &runtime.LinuxContainerResources{
+		CpuShares: 2,
+	})
+	assert.Equal(t, int64(2), sum.CpuShares)
+	assert.Equal(t, int64(0), sum.CpuQuota)
+	assert.Equal(t, int64(0), sum.MemoryLimitInBytes)
+}
@@ -0,0 +1,42 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// podCgroupManager is only supported on Linux.
+type podCgroupManager struct{}
+
+func newPodCgroupManager() *podCgroupManager {
+	return nil
+}
+
+func (m *podCgroupManager) Ensure(cgroupParent string) error {
+	return nil
+}
+
+func (m *podCgroupManager) Reconcile(cgroupParent string, res *runtime.LinuxContainerResources) error {
+	return nil
+}
+
+func (m *podCgroupManager) Remove(cgroupParent string) error {
+	return nil
+}
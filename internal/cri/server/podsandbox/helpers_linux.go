@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/containerd/log"
+	units "github.com/docker/go-units"
 	"github.com/moby/sys/mountinfo"
 	runtimespec "github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/opencontainers/selinux/go-selinux/label"
@@ -38,6 +39,7 @@ import (
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
 	"github.com/containerd/containerd/v2/internal/cri/seutil"
 	"github.com/containerd/containerd/v2/pkg/seccomp"
 )
@@ -88,6 +90,39 @@ func (c *Controller) getSandboxDevShm(id string) string {
 	return filepath.Join(c.getVolatileSandboxRootDir(id), "shm")
 }
 
+// sandboxShmSize returns the size of the sandbox /dev/shm tmpfs, taking the
+// SandboxShmSize pod annotation into account.
+func sandboxShmSize(config *runtime.PodSandboxConfig) (int64, error) {
+	v, ok := config.GetAnnotations()[annotations.SandboxShmSize]
+	if !ok {
+		return defaultShmSize, nil
+	}
+	size, err := units.RAMInBytes(v)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse annotation %s=%q: %w", annotations.SandboxShmSize, v, err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("annotation %s=%q must be a positive size", annotations.SandboxShmSize, v)
+	}
+	return size, nil
+}
+
+// hostDevShm reports whether the sandbox should use the host /dev/shm, either
+// because of host IPC or because the SandboxDevShm pod annotation selects it.
+func hostDevShm(config *runtime.PodSandboxConfig) (bool, error) {
+	if config.GetLinux().GetSecurityContext().GetNamespaceOptions().GetIpc() == runtime.NamespaceMode_NODE {
+		return true, nil
+	}
+	switch v := config.GetAnnotations()[annotations.SandboxDevShm]; v {
+	case "", "pod":
+		return false, nil
+	case "node":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid annotation %s=%q, expected %q or %q", annotations.SandboxDevShm, v, "pod", "node")
+	}
+}
+
 func toLabel(selinuxOptions *runtime.SELinuxOption) ([]string, error) {
 	var labels []string
 
@@ -25,6 +25,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sys/unix"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
 )
 
 func TestGetCgroupsPath(t *testing.T) {
@@ -68,6 +71,114 @@ func TestGetCgroupsPath(t *testing.T) {
 	}
 }
 
+func TestSandboxShmSize(t *testing.T) {
+	for _, test := range []struct {
+		desc      string
+		value     string
+		expected  int64
+		expectErr bool
+	}{
+		{
+			desc:     "should use the default size without annotation",
+			expected: defaultShmSize,
+		},
+		{
+			desc:     "should support size in bytes",
+			value:    "1048576",
+			expected: 1048576,
+		},
+		{
+			desc:     "should support human readable size",
+			value:    "256m",
+			expected: 256 * 1024 * 1024,
+		},
+		{
+			desc:      "should reject non-positive size",
+			value:     "0",
+			expectErr: true,
+		},
+		{
+			desc:      "should reject invalid size",
+			value:     "many-bytes",
+			expectErr: true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			config := &runtime.PodSandboxConfig{}
+			if test.value != "" {
+				config.Annotations = map[string]string{annotations.SandboxShmSize: test.value}
+			}
+			size, err := sandboxShmSize(config)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, size)
+		})
+	}
+}
+
+func TestHostDevShm(t *testing.T) {
+	for _, test := range []struct {
+		desc       string
+		hostIpc    bool
+		annotation string
+		expected   bool
+		expectErr  bool
+	}{
+		{
+			desc:     "should default to the pod /dev/shm",
+			expected: false,
+		},
+		{
+			desc:    "should use the host /dev/shm for host ipc",
+			hostIpc: true,
+			// The annotation is ignored with host ipc.
+			annotation: "pod",
+			expected:   true,
+		},
+		{
+			desc:       "should support selecting the pod /dev/shm explicitly",
+			annotation: "pod",
+			expected:   false,
+		},
+		{
+			desc:       "should support selecting the host /dev/shm",
+			annotation: "node",
+			expected:   true,
+		},
+		{
+			desc:       "should reject unknown values",
+			annotation: "shared",
+			expectErr:  true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			config := &runtime.PodSandboxConfig{}
+			if test.hostIpc {
+				config.Linux = &runtime.LinuxPodSandboxConfig{
+					SecurityContext: &runtime.LinuxSandboxSecurityContext{
+						NamespaceOptions: &runtime.NamespaceOption{
+							Ipc: runtime.NamespaceMode_NODE,
+						},
+					},
+				}
+			}
+			if test.annotation != "" {
+				config.Annotations = map[string]string{annotations.SandboxDevShm: test.annotation}
+			}
+			host, err := hostDevShm(config)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, host)
+		})
+	}
+}
+
 func TestEnsureRemoveAllWithMount(t *testing.T) {
 	if os.Getuid() != 0 {
 		t.Skip("skipping test that requires root")
@@ -18,6 +18,7 @@ package podsandbox
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -40,6 +41,7 @@ import (
 	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
 	ctrdutil "github.com/containerd/containerd/v2/internal/cri/util"
 	containerdio "github.com/containerd/containerd/v2/pkg/cio"
+	"github.com/containerd/containerd/v2/pkg/shim"
 	"github.com/containerd/errdefs"
 )
 
@@ -99,7 +101,14 @@ func (c *Controller) Start(ctx context.Context, id string) (cin sandbox.Controll
 	// NOTE: sandboxContainerSpec SHOULD NOT have side
 	// effect, e.g. accessing/creating files, so that we can test
 	// it safely.
-	spec, err := c.sandboxContainerSpec(id, config, &image.ImageSpec.Config, metadata.NetNSPath, ociRuntime.PodAnnotations)
+	podAnnotations := ociRuntime.PodAnnotations
+	if len(ociRuntime.SandboxAnnotationPrefixes) > 0 {
+		podAnnotations = append([]string(nil), podAnnotations...)
+		for _, p := range ociRuntime.SandboxAnnotationPrefixes {
+			podAnnotations = append(podAnnotations, p+"*")
+		}
+	}
+	spec, err := c.sandboxContainerSpec(id, config, &image.ImageSpec.Config, metadata.NetNSPath, podAnnotations)
 	if err != nil {
 		return cin, fmt.Errorf("failed to generate sandbox container spec: %w", err)
 	}
@@ -124,6 +133,17 @@ func (c *Controller) Start(ctx context.Context, id string) (cin sandbox.Controll
 		spec.Process.SelinuxLabel = ""
 	}
 
+	if len(ociRuntime.ShimEnv) > 0 {
+		v, err := json.Marshal(ociRuntime.ShimEnv)
+		if err != nil {
+			return cin, fmt.Errorf("failed to marshal shim env: %w", err)
+		}
+		if spec.Annotations == nil {
+			spec.Annotations = make(map[string]string)
+		}
+		spec.Annotations[shim.EnvAnnotation] = string(v)
+	}
+
 	// Generate spec options that will be applied to the spec later.
 	specOpts, err := c.sandboxContainerSpecOpts(config, &image.ImageSpec.Config)
 	if err != nil {
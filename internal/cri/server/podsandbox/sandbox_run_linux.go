@@ -115,7 +115,9 @@ func (c *Controller) sandboxContainerSpec(id string, config *runtime.PodSandboxC
 	// It's fine to generate the spec before the sandbox /dev/shm
 	// is actually created.
 	sandboxDevShm := c.getSandboxDevShm(id)
-	if nsOptions.GetIpc() == runtime.NamespaceMode_NODE {
+	if host, err := hostDevShm(config); err != nil {
+		return nil, err
+	} else if host {
 		sandboxDevShm = devShm
 	}
 	// Remove the default /dev/shm mount from defaultMounts, it is added in oci/mounts.go.
@@ -287,7 +289,9 @@ func (c *Controller) setupSandboxFiles(id string, config *runtime.PodSandboxConf
 	}
 
 	// Setup sandbox /dev/shm.
-	if config.GetLinux().GetSecurityContext().GetNamespaceOptions().GetIpc() == runtime.NamespaceMode_NODE {
+	if host, err := hostDevShm(config); err != nil {
+		return err
+	} else if host {
 		if _, err := c.os.Stat(devShm); err != nil {
 			return fmt.Errorf("host %q is not available for host ipc: %w", devShm, err)
 		}
@@ -296,7 +300,11 @@ func (c *Controller) setupSandboxFiles(id string, config *runtime.PodSandboxConf
 		if err := c.os.MkdirAll(sandboxDevShm, 0700); err != nil {
 			return fmt.Errorf("failed to create sandbox shm: %w", err)
 		}
-		shmproperty := fmt.Sprintf("mode=1777,size=%d", defaultShmSize)
+		shmSize, err := sandboxShmSize(config)
+		if err != nil {
+			return err
+		}
+		shmproperty := fmt.Sprintf("mode=1777,size=%d", shmSize)
 		if err := c.os.Mount("shm", sandboxDevShm, "tmpfs", uintptr(unix.MS_NOEXEC|unix.MS_NOSUID|unix.MS_NODEV), shmproperty); err != nil {
 			return fmt.Errorf("failed to mount sandbox shm: %w", err)
 		}
@@ -328,7 +336,7 @@ func parseDNSOptions(servers, searches, options []string) (string, error) {
 // cleanupSandboxFiles unmount some sandbox files, we rely on the removal of sandbox root directory to
 // remove these files. Unmount should *NOT* return error if the mount point is already unmounted.
 func (c *Controller) cleanupSandboxFiles(id string, config *runtime.PodSandboxConfig) error {
-	if config.GetLinux().GetSecurityContext().GetNamespaceOptions().GetIpc() != runtime.NamespaceMode_NODE {
+	if host, _ := hostDevShm(config); !host {
 		path, err := c.os.FollowSymlinkInScope(c.getSandboxDevShm(id), "/")
 		if err != nil {
 			return fmt.Errorf("failed to follow symlink: %w", err)
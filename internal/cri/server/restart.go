@@ -81,6 +81,7 @@ func (c *criService) recover(ctx context.Context) error {
 				return nil
 			}
 			log.G(ctx2).Debugf("Loaded sandbox %+v", sb)
+			c.restoreCNIResult(ctx2, &sb)
 			if err := c.sandboxStore.Add(sb); err != nil {
 				return fmt.Errorf("failed to add sandbox %q to store: %w", sandbox.ID(), err)
 			}
@@ -141,6 +142,8 @@ func (c *criService) recover(ctx context.Context) error {
 		// Load network namespace.
 		sb.NetNS = getNetNS(&metadata)
 
+		c.restoreCNIResult(ctx, &sb)
+
 		if err := c.sandboxStore.Add(sb); err != nil {
 			return fmt.Errorf("failed to add stored sandbox %q to store: %w", sbx.ID, err)
 		}
@@ -191,6 +194,15 @@ func (c *criService) recover(ctx context.Context) error {
 		return err
 	}
 
+	// Drop exclusive CPU assignments of containers removed while containerd
+	// was down, so their CPUs go back to the pool.
+	if c.cpusetAllocator != nil {
+		c.cpusetAllocator.prune(func(id string) bool {
+			_, err := c.containerStore.Get(id)
+			return err == nil
+		})
+	}
+
 	// Recover all images.
 	if err := c.ImageService.CheckImages(ctx); err != nil {
 		return fmt.Errorf("failed to check images: %w", err)
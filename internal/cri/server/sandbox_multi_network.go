@@ -0,0 +1,165 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/containerd/go-cni"
+	"github.com/containerd/log"
+	cnilibrary "github.com/containernetworking/cni/libcni"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+// additionalIfPrefix is the interface name prefix used for additional
+// network attachments, so they never collide with the default interface.
+const additionalIfPrefix = "net"
+
+// additionalNetworkNames returns the CNI network names the sandbox requests
+// to be attached to in addition to the default network.
+func additionalNetworkNames(config *runtime.PodSandboxConfig) []string {
+	var names []string
+	for _, name := range strings.Split(config.GetAnnotations()[annotations.AdditionalNetworks], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// loadAdditionalNetworks builds a CNI instance holding the named network
+// configurations from the CNI conf dir of the given runtime class, in the
+// requested order.
+func (c *criService) loadAdditionalNetworks(runtimeClass string, names []string) (cni.CNI, error) {
+	confDir := c.networkPluginConfDir(runtimeClass)
+
+	netPlugin, err := cni.New(cni.WithMinNetworkCount(len(names)),
+		cni.WithInterfacePrefix(additionalIfPrefix),
+		cni.WithPluginConfDir(confDir),
+		cni.WithPluginDir([]string{c.config.NetworkPluginBinDir}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize cni: %w", err)
+	}
+
+	files, err := cnilibrary.ConfFiles(confDir, []string{".conf", ".conflist", ".json"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cni config dir %q: %w", confDir, err)
+	}
+	confLists := make(map[string]*cnilibrary.NetworkConfigList, len(files))
+	for _, file := range files {
+		var confList *cnilibrary.NetworkConfigList
+		if strings.HasSuffix(file, ".conflist") {
+			confList, err = cnilibrary.ConfListFromFile(file)
+		} else {
+			var conf *cnilibrary.NetworkConfig
+			conf, err = cnilibrary.ConfFromFile(file)
+			if err == nil {
+				confList, err = cnilibrary.ConfListFromConf(conf)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cni config file %q: %w", file, err)
+		}
+		if _, ok := confLists[confList.Name]; !ok {
+			confLists[confList.Name] = confList
+		}
+	}
+
+	opts := make([]cni.Opt, 0, len(names))
+	for _, name := range names {
+		confList, ok := confLists[name]
+		if !ok {
+			return nil, fmt.Errorf("additional network %q not found in cni config dir %q", name, confDir)
+		}
+		opts = append(opts, cni.WithConfListBytes(confList.Bytes))
+	}
+	if err := netPlugin.Load(opts...); err != nil {
+		return nil, fmt.Errorf("failed to load additional networks: %w", err)
+	}
+	return netPlugin, nil
+}
+
+// setupAdditionalNetworks attaches the sandbox to the additional networks
+// requested through the pod annotation, after the default network has been
+// set up. The merged CNI result is persisted in the sandbox metadata.
+func (c *criService) setupAdditionalNetworks(ctx context.Context, sandbox *sandboxstore.Sandbox) error {
+	names := additionalNetworkNames(sandbox.Config)
+	if len(names) == 0 {
+		return nil
+	}
+	id := sandbox.ID
+
+	netPlugin, err := c.loadAdditionalNetworks(sandbox.RuntimeHandler, names)
+	if err != nil {
+		return err
+	}
+	opts, err := c.cniNamespaceOpts(id, sandbox.Config)
+	if err != nil {
+		return fmt.Errorf("get cni namespace options: %w", err)
+	}
+	log.G(ctx).WithField("podsandboxid", id).Debugf("begin additional cni setup for networks %v", names)
+	netStart := time.Now()
+	var result *cni.Result
+	if c.config.CniConfig.NetworkPluginSetupSerially {
+		result, err = netPlugin.SetupSerially(ctx, id, sandbox.NetNSPath, opts...)
+	} else {
+		result, err = netPlugin.Setup(ctx, id, sandbox.NetNSPath, opts...)
+	}
+	networkPluginOperations.WithValues(networkSetUpOp).Inc()
+	networkPluginOperationsLatency.WithValues(networkSetUpOp).UpdateSince(netStart)
+	if err != nil {
+		networkPluginOperationsErrors.WithValues(networkSetUpOp).Inc()
+		return err
+	}
+	logDebugCNIResult(ctx, id, result)
+	sandbox.AdditionalCNIResult = result
+	return nil
+}
+
+// teardownAdditionalNetworks detaches the sandbox from the additional
+// networks requested through the pod annotation.
+func (c *criService) teardownAdditionalNetworks(ctx context.Context, sandbox sandboxstore.Sandbox) error {
+	names := additionalNetworkNames(sandbox.Config)
+	if len(names) == 0 {
+		return nil
+	}
+	id := sandbox.ID
+
+	netPlugin, err := c.loadAdditionalNetworks(sandbox.RuntimeHandler, names)
+	if err != nil {
+		return err
+	}
+	opts, err := c.cniNamespaceOpts(id, sandbox.Config)
+	if err != nil {
+		return fmt.Errorf("get cni namespace options: %w", err)
+	}
+	netStart := time.Now()
+	err = netPlugin.Remove(ctx, id, sandbox.NetNSPath, opts...)
+	networkPluginOperations.WithValues(networkTearDownOp).Inc()
+	networkPluginOperationsLatency.WithValues(networkTearDownOp).UpdateSince(netStart)
+	if err != nil {
+		networkPluginOperationsErrors.WithValues(networkTearDownOp).Inc()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/containerd/containerd/v2/internal/cri/annotations"
+)
+
+func TestAdditionalNetworkNames(t *testing.T) {
+	for _, test := range []struct {
+		desc       string
+		annotation string
+		expected   []string
+	}{
+		{
+			desc: "no annotation",
+		},
+		{
+			desc:       "empty annotation",
+			annotation: " ",
+		},
+		{
+			desc:       "single network",
+			annotation: "macvlan-conf",
+			expected:   []string{"macvlan-conf"},
+		},
+		{
+			desc:       "multiple networks with spaces and empty entries",
+			annotation: "macvlan-conf, sriov-conf,,ipvlan-conf ",
+			expected:   []string{"macvlan-conf", "sriov-conf", "ipvlan-conf"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			config := &runtime.PodSandboxConfig{}
+			if test.annotation != "" {
+				config.Annotations = map[string]string{
+					annotations.AdditionalNetworks: test.annotation,
+				}
+			}
+			assert.Equal(t, test.expected, additionalNetworkNames(config))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"github.com/docker/go-metrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+)
+
+// sandboxNetworkCollector exposes the per-interface network counters kept in
+// the sandbox store as Prometheus metrics. The counters are refreshed from
+// the sandbox network namespaces whenever pod sandbox stats are collected,
+// so scrapes never enter a network namespace themselves.
+type sandboxNetworkCollector struct {
+	sandboxStore *sandboxstore.Store
+
+	rxBytes   *prometheus.Desc
+	rxPackets *prometheus.Desc
+	rxErrors  *prometheus.Desc
+	txBytes   *prometheus.Desc
+	txPackets *prometheus.Desc
+	txErrors  *prometheus.Desc
+}
+
+// registerSandboxNetworkCollector registers the sandbox network metrics with
+// the global metrics registry. It must be called at most once.
+func registerSandboxNetworkCollector(sandboxStore *sandboxstore.Store) {
+	ns := metrics.NewNamespace("containerd", "cri_sandboxed", nil)
+	ns.Add(newSandboxNetworkCollector(ns, sandboxStore))
+	metrics.Register(ns)
+}
+
+func newSandboxNetworkCollector(ns *metrics.Namespace, sandboxStore *sandboxstore.Store) *sandboxNetworkCollector {
+	labels := []string{"sandbox", "interface"}
+	return &sandboxNetworkCollector{
+		sandboxStore: sandboxStore,
+		rxBytes:      ns.NewDesc("sandbox_network_rx", "The number of bytes received by the sandbox interface", metrics.Bytes, labels...),
+		rxPackets:    ns.NewDesc("sandbox_network_rx_packets", "The number of packets received by the sandbox interface", metrics.Total, labels...),
+		rxErrors:     ns.NewDesc("sandbox_network_rx_errors", "The number of receive errors of the sandbox interface", metrics.Total, labels...),
+		txBytes:      ns.NewDesc("sandbox_network_tx", "The number of bytes transmitted by the sandbox interface", metrics.Bytes, labels...),
+		txPackets:    ns.NewDesc("sandbox_network_tx_packets", "The number of packets transmitted by the sandbox interface", metrics.Total, labels...),
+		txErrors:     ns.NewDesc("sandbox_network_tx_errors", "The number of transmit errors of the sandbox interface", metrics.Total, labels...),
+	}
+}
+
+func (c *sandboxNetworkCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rxBytes
+	ch <- c.rxPackets
+	ch <- c.rxErrors
+	ch <- c.txBytes
+	ch <- c.txPackets
+	ch <- c.txErrors
+}
+
+func (c *sandboxNetworkCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, sandbox := range c.sandboxStore.List() {
+		if sandbox.NetworkStats == nil {
+			continue
+		}
+		for _, iface := range sandbox.NetworkStats.Interfaces {
+			ch <- prometheus.MustNewConstMetric(c.rxBytes, prometheus.CounterValue, float64(iface.RxBytes), sandbox.ID, iface.Name)
+			ch <- prometheus.MustNewConstMetric(c.rxPackets, prometheus.CounterValue, float64(iface.RxPackets), sandbox.ID, iface.Name)
+			ch <- prometheus.MustNewConstMetric(c.rxErrors, prometheus.CounterValue, float64(iface.RxErrors), sandbox.ID, iface.Name)
+			ch <- prometheus.MustNewConstMetric(c.txBytes, prometheus.CounterValue, float64(iface.TxBytes), sandbox.ID, iface.Name)
+			ch <- prometheus.MustNewConstMetric(c.txPackets, prometheus.CounterValue, float64(iface.TxPackets), sandbox.ID, iface.Name)
+			ch <- prometheus.MustNewConstMetric(c.txErrors, prometheus.CounterValue, float64(iface.TxErrors), sandbox.ID, iface.Name)
+		}
+	}
+}
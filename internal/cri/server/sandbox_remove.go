@@ -24,6 +24,8 @@ import (
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 
+	"github.com/containerd/containerd/v2/internal/rwlayer"
+	"github.com/containerd/containerd/v2/pkg/coredump"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
@@ -91,12 +93,41 @@ func (c *criService) RemovePodSandbox(ctx context.Context, r *runtime.RemovePodS
 		log.G(ctx).WithError(err).Errorf("NRI pod removal notification failed")
 	}
 
+	if ociRuntime, err := c.config.GetSandboxRuntime(sandbox.Config, sandbox.Metadata.RuntimeHandler); err == nil && ociRuntime.CoreDumpPath != "" {
+		if err := coredump.CleanupPod(ociRuntime.CoreDumpPath, id); err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to remove core dump directory of sandbox %q", id)
+		}
+	}
+
+	// Destroy any rw-layer encryption keys of the sandbox, rendering leftover
+	// writable-layer data unreadable.
+	if err := rwlayer.DestroyPodKeys(ctx, id); err != nil {
+		log.G(ctx).WithError(err).Warnf("failed to destroy rw-layer keys of sandbox %q", id)
+	}
+
+	// Drop the pod's NUMA placement, if any.
+	if c.numa != nil {
+		c.numa.release(id)
+	}
+
+	// Remove the pod-level cgroup when containerd manages pod cgroups.
+	if c.podCgroups != nil {
+		if parent := sandbox.Config.GetLinux().GetCgroupParent(); parent != "" {
+			if err := c.podCgroups.Remove(parent); err != nil {
+				log.G(ctx).WithError(err).Warnf("Failed to remove pod cgroup %q", parent)
+			}
+		}
+	}
+
 	// Remove sandbox from sandbox store. Note that once the sandbox is successfully
 	// deleted:
 	// 1) ListPodSandbox will not include this sandbox.
 	// 2) PodSandboxStatus and StopPodSandbox will return error.
 	// 3) On-going operations which have held the reference will not be affected.
 	c.sandboxStore.Delete(id)
+	c.statsCache.evictSandbox(id)
+	// Drop any persisted CNI result the network teardown left behind.
+	c.deleteCNIResult(ctx, id)
 
 	if err := c.client.SandboxStore().Delete(ctx, id); err != nil {
 		if !errdefs.IsNotFound(err) {
@@ -61,6 +61,10 @@ func (c *criService) RunPodSandbox(ctx context.Context, r *runtime.RunPodSandbox
 	name := makeSandboxName(metadata)
 	log.G(ctx).WithField("podsandboxid", id).Debugf("generated id for sandbox name %q", name)
 
+	if err := c.checkSandboxDensity(); err != nil {
+		return nil, err
+	}
+
 	// cleanupErr records the last error returned by the critical cleanup operations in deferred functions,
 	// like CNI teardown and stopping the running sandbox task.
 	// If cleanup is not completed for some reason, the CRI-plugin will leave the sandbox
@@ -159,8 +163,27 @@ func (c *criService) RunPodSandbox(ctx context.Context, r *runtime.RunPodSandbox
 		}
 	}
 
-	// Setup the network namespace if host networking wasn't requested.
-	if !hostNetwork(config) && !userNsEnabled {
+	// Create or validate the pod-level cgroup when containerd manages pod
+	// cgroups for the kubelet.
+	if c.podCgroups != nil {
+		if parent := config.GetLinux().GetCgroupParent(); parent != "" {
+			if err := c.podCgroups.Ensure(parent); err != nil {
+				return nil, fmt.Errorf("failed to ensure pod cgroup %q: %w", parent, err)
+			}
+			// Apply the kubelet provided pod resources right away, the sum
+			// of containers is reconciled as they come and go.
+			if res := config.GetLinux().GetResources(); res != nil {
+				if err := c.podCgroups.Reconcile(parent, res); err != nil {
+					log.G(ctx).WithError(err).Warnf("Failed to size pod cgroup %q", parent)
+				}
+			}
+		}
+	}
+
+	// Setup the network namespace if host networking wasn't requested. Wasm
+	// runtimes provide their own network stack inside the shim, so no netns is
+	// created for them either.
+	if !hostNetwork(config) && !userNsEnabled && !ociRuntime.Wasm {
 		// XXX: We do c&p of this code later for the podNetwork && userNsEnabled case too.
 		// We can't move this to a function, as the defer calls need to be executed if other
 		// errors are returned in this function. So, we would need more refactors to move
@@ -265,7 +288,7 @@ func (c *criService) RunPodSandbox(ctx context.Context, r *runtime.RunPodSandbox
 		return nil, fmt.Errorf("failed to start sandbox %q: %w", id, err)
 	}
 
-	if !hostNetwork(config) && userNsEnabled {
+	if !hostNetwork(config) && userNsEnabled && !ociRuntime.Wasm {
 		// If userns is enabled, then the netns was created by the OCI runtime
 		// on controller.Start(). The OCI runtime needs to create the netns
 		// because, if userns is in use, the netns needs to be owned by the
@@ -431,6 +454,17 @@ func (c *criService) getNetworkPlugin(runtimeClass string) cni.CNI {
 	return i
 }
 
+// networkPluginConfDir returns the CNI conf dir used by the runtime class,
+// defaults to the global CNI conf dir in the CRI config.
+func (c *criService) networkPluginConfDir(runtimeClass string) string {
+	if runtimeClass != defaultNetworkPlugin {
+		if rc, ok := c.config.Runtimes[runtimeClass]; ok && rc.NetworkPluginConfDir != "" {
+			return rc.NetworkPluginConfDir
+		}
+	}
+	return c.config.NetworkPluginConfDir
+}
+
 // setupPodNetwork setups up the network for a pod
 func (c *criService) setupPodNetwork(ctx context.Context, sandbox *sandboxstore.Sandbox) error {
 	var (
@@ -445,7 +479,7 @@ func (c *criService) setupPodNetwork(ctx context.Context, sandbox *sandboxstore.
 		return errors.New("cni config not initialized")
 	}
 
-	opts, err := cniNamespaceOpts(id, config)
+	opts, err := c.cniNamespaceOpts(id, config)
 	if err != nil {
 		return fmt.Errorf("get cni namespace options: %w", err)
 	}
@@ -464,16 +498,26 @@ func (c *criService) setupPodNetwork(ctx context.Context, sandbox *sandboxstore.
 	}
 	logDebugCNIResult(ctx, id, result)
 	// Check if the default interface has IP config
-	if configs, ok := result.Interfaces[defaultIfName]; ok && len(configs.IPConfigs) > 0 {
-		sandbox.IP, sandbox.AdditionalIPs = selectPodIPs(ctx, configs.IPConfigs, c.config.IPPreference)
-		sandbox.CNIResult = result
-		return nil
-	}
-	return fmt.Errorf("failed to find network info for sandbox %q", id)
+	configs, ok := result.Interfaces[defaultIfName]
+	if !ok || len(configs.IPConfigs) == 0 {
+		return fmt.Errorf("failed to find network info for sandbox %q", id)
+	}
+	sandbox.IP, sandbox.AdditionalIPs = selectPodIPs(ctx, configs.IPConfigs, c.config.IPPreference)
+	sandbox.CNIResult = result
+	// Attach any additional networks requested through the pod annotation.
+	if err := c.setupAdditionalNetworks(ctx, sandbox); err != nil {
+		return fmt.Errorf("failed to attach additional networks: %w", err)
+	}
+	// Persist the CNI results before the sandbox metadata is stored so
+	// teardown still knows about the attachment if we crash in between.
+	if err := c.storeCNIResult(sandbox); err != nil {
+		return fmt.Errorf("failed to persist CNI result: %w", err)
+	}
+	return nil
 }
 
 // cniNamespaceOpts get CNI namespace options from sandbox config.
-func cniNamespaceOpts(id string, config *runtime.PodSandboxConfig) ([]cni.NamespaceOpts, error) {
+func (c *criService) cniNamespaceOpts(id string, config *runtime.PodSandboxConfig) ([]cni.NamespaceOpts, error) {
 	opts := []cni.NamespaceOpts{
 		cni.WithLabels(toCNILabels(id, config)),
 		cni.WithCapability(annotations.PodAnnotations, config.Annotations),
@@ -503,9 +547,29 @@ func cniNamespaceOpts(id string, config *runtime.PodSandboxConfig) ([]cni.Namesp
 		opts = append(opts, cni.WithCapabilityCgroupPath(cgroup))
 	}
 
+	if ipRanges := c.cniIPRanges(); len(ipRanges) > 0 {
+		opts = append(opts, cni.WithCapabilityIPRanges(ipRanges))
+	}
+
 	return opts, nil
 }
 
+// cniIPRanges returns the node's pod CIDRs in the CNI ipRanges capability
+// format. Empty until the kubelet reports the pod CIDR through
+// UpdateRuntimeConfig.
+func (c *criService) cniIPRanges() []cni.IPRanges {
+	c.podCIDRsMu.RLock()
+	defer c.podCIDRsMu.RUnlock()
+	if len(c.podCIDRs) == 0 {
+		return nil
+	}
+	ranges := make([]cni.IPRanges, 0, len(c.podCIDRs))
+	for _, cidr := range c.podCIDRs {
+		ranges = append(ranges, cni.IPRanges{Subnet: cidr})
+	}
+	return ranges
+}
+
 // toCNILabels adds pod metadata into CNI labels.
 func toCNILabels(id string, config *runtime.PodSandboxConfig) map[string]string {
 	return map[string]string{
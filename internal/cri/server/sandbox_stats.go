@@ -33,10 +33,15 @@ func (c *criService) PodSandboxStats(
 		return nil, fmt.Errorf("an error occurred when trying to find sandbox %s: %w", r.GetPodSandboxId(), err)
 	}
 
+	if cached, ok := c.statsCache.getSandbox(sandbox.ID); ok {
+		return &runtime.PodSandboxStatsResponse{Stats: cached}, nil
+	}
+
 	podSandboxStats, err := c.podSandboxStats(ctx, sandbox)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode pod sandbox metrics %s: %w", r.GetPodSandboxId(), err)
 	}
+	c.statsCache.putSandbox(podSandboxStats)
 
 	return &runtime.PodSandboxStatsResponse{Stats: podSandboxStats}, nil
 }
@@ -19,12 +19,14 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/containerd/cgroups/v3"
 	"github.com/containerd/cgroups/v3/cgroup1"
 	cgroupsv2 "github.com/containerd/cgroups/v3/cgroup2"
 	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
+	"github.com/containerd/containerd/v2/internal/cri/store/stats"
 	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 	"github.com/containernetworking/plugins/pkg/ns"
@@ -72,15 +74,10 @@ func (c *criService) podSandboxStats(
 		podSandboxStats.Linux.Memory = memoryStats
 
 		if sandbox.NetNSPath != "" {
-			rxBytes, rxErrors, txBytes, txErrors := getContainerNetIO(ctx, sandbox.NetNSPath)
-			podSandboxStats.Linux.Network = &runtime.NetworkUsage{
-				DefaultInterface: &runtime.NetworkInterfaceUsage{
-					Name:     defaultIfName,
-					RxBytes:  &runtime.UInt64Value{Value: rxBytes},
-					RxErrors: &runtime.UInt64Value{Value: rxErrors},
-					TxBytes:  &runtime.UInt64Value{Value: txBytes},
-					TxErrors: &runtime.UInt64Value{Value: txErrors},
-				},
+			networkStats := getSandboxNetworkStats(ctx, sandbox.NetNSPath, timestamp)
+			podSandboxStats.Linux.Network = toCRINetworkUsage(networkStats)
+			if err := c.sandboxStore.UpdateNetworkStats(sandbox.ID, networkStats); err != nil {
+				log.G(ctx).WithError(err).Warnf("failed to update network stats of sandbox %q", sandbox.ID)
 			}
 		}
 
@@ -123,25 +120,68 @@ func (c *criService) podSandboxStats(
 	return podSandboxStats, nil
 }
 
-// https://github.com/cri-o/cri-o/blob/74a5cf8dffd305b311eb1c7f43a4781738c388c1/internal/oci/stats.go#L32
-func getContainerNetIO(ctx context.Context, netNsPath string) (rxBytes, rxErrors, txBytes, txErrors uint64) {
+// getSandboxNetworkStats collects the counters of every non-loopback
+// interface inside the sandbox network namespace.
+func getSandboxNetworkStats(ctx context.Context, netNsPath string, timestamp time.Time) *stats.NetworkStats {
+	networkStats := &stats.NetworkStats{Timestamp: timestamp}
 	ns.WithNetNSPath(netNsPath, func(_ ns.NetNS) error {
-		link, err := netlink.LinkByName(defaultIfName)
+		links, err := netlink.LinkList()
 		if err != nil {
-			log.G(ctx).WithError(err).Errorf("unable to retrieve network namespace stats for netNsPath: %v, interface: %v", netNsPath, defaultIfName)
+			log.G(ctx).WithError(err).Errorf("unable to retrieve network namespace stats for netNsPath: %v", netNsPath)
 			return err
 		}
-		attrs := link.Attrs()
-		if attrs != nil && attrs.Statistics != nil {
-			rxBytes = attrs.Statistics.RxBytes
-			rxErrors = attrs.Statistics.RxErrors
-			txBytes = attrs.Statistics.TxBytes
-			txErrors = attrs.Statistics.TxErrors
+		for _, link := range links {
+			attrs := link.Attrs()
+			if attrs == nil || attrs.Statistics == nil || attrs.Flags&net.FlagLoopback != 0 {
+				continue
+			}
+			networkStats.Interfaces = append(networkStats.Interfaces, stats.InterfaceStats{
+				Name:      attrs.Name,
+				RxBytes:   attrs.Statistics.RxBytes,
+				RxPackets: attrs.Statistics.RxPackets,
+				RxErrors:  attrs.Statistics.RxErrors,
+				TxBytes:   attrs.Statistics.TxBytes,
+				TxPackets: attrs.Statistics.TxPackets,
+				TxErrors:  attrs.Statistics.TxErrors,
+			})
 		}
 		return nil
 	})
 
-	return rxBytes, rxErrors, txBytes, txErrors
+	return networkStats
+}
+
+// toCRINetworkUsage converts collected network namespace counters to the CRI
+// network usage format, with the default interface reported separately from
+// any additional attachments.
+func toCRINetworkUsage(networkStats *stats.NetworkStats) *runtime.NetworkUsage {
+	usage := &runtime.NetworkUsage{
+		Timestamp: networkStats.Timestamp.UnixNano(),
+		// Keep reporting the default interface, zero valued, even when it
+		// could not be collected.
+		DefaultInterface: &runtime.NetworkInterfaceUsage{
+			Name:     defaultIfName,
+			RxBytes:  &runtime.UInt64Value{},
+			RxErrors: &runtime.UInt64Value{},
+			TxBytes:  &runtime.UInt64Value{},
+			TxErrors: &runtime.UInt64Value{},
+		},
+	}
+	for _, iface := range networkStats.Interfaces {
+		criIface := &runtime.NetworkInterfaceUsage{
+			Name:     iface.Name,
+			RxBytes:  &runtime.UInt64Value{Value: iface.RxBytes},
+			RxErrors: &runtime.UInt64Value{Value: iface.RxErrors},
+			TxBytes:  &runtime.UInt64Value{Value: iface.TxBytes},
+			TxErrors: &runtime.UInt64Value{Value: iface.TxErrors},
+		}
+		if iface.Name == defaultIfName {
+			usage.DefaultInterface = criIface
+		} else {
+			usage.Interfaces = append(usage.Interfaces, criIface)
+		}
+	}
+	return usage
 }
 
 func metricsForSandbox(sandbox sandboxstore.Sandbox) (interface{}, error) {
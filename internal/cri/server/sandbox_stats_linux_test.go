@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/containerd/containerd/v2/internal/cri/store/stats"
+)
+
+func TestToCRINetworkUsage(t *testing.T) {
+	timestamp := time.Now()
+	networkStats := &stats.NetworkStats{
+		Timestamp: timestamp,
+		Interfaces: []stats.InterfaceStats{
+			{
+				Name:      "net1",
+				RxBytes:   10,
+				RxPackets: 1,
+				TxBytes:   20,
+				TxPackets: 2,
+			},
+			{
+				Name:      defaultIfName,
+				RxBytes:   100,
+				RxPackets: 10,
+				RxErrors:  1,
+				TxBytes:   200,
+				TxPackets: 20,
+				TxErrors:  2,
+			},
+		},
+	}
+
+	usage := toCRINetworkUsage(networkStats)
+	assert.Equal(t, timestamp.UnixNano(), usage.Timestamp)
+	assert.Equal(t, defaultIfName, usage.DefaultInterface.Name)
+	assert.Equal(t, uint64(100), usage.DefaultInterface.RxBytes.Value)
+	assert.Equal(t, uint64(1), usage.DefaultInterface.RxErrors.Value)
+	assert.Equal(t, uint64(200), usage.DefaultInterface.TxBytes.Value)
+	assert.Equal(t, uint64(2), usage.DefaultInterface.TxErrors.Value)
+	assert.Len(t, usage.Interfaces, 1)
+	assert.Equal(t, "net1", usage.Interfaces[0].Name)
+	assert.Equal(t, uint64(10), usage.Interfaces[0].RxBytes.Value)
+	assert.Equal(t, uint64(20), usage.Interfaces[0].TxBytes.Value)
+}
+
+func TestToCRINetworkUsageMissingDefaultInterface(t *testing.T) {
+	usage := toCRINetworkUsage(&stats.NetworkStats{Timestamp: time.Now()})
+	assert.Equal(t, defaultIfName, usage.DefaultInterface.Name)
+	assert.Equal(t, uint64(0), usage.DefaultInterface.RxBytes.Value)
+	assert.Empty(t, usage.Interfaces)
+}
@@ -37,6 +37,10 @@ func (c *criService) ListPodSandboxStats(
 	var errs []error
 	podSandboxStats := new(runtime.ListPodSandboxStatsResponse)
 	for _, sandbox := range sandboxes {
+		if cached, ok := c.statsCache.getSandbox(sandbox.ID); ok {
+			podSandboxStats.Stats = append(podSandboxStats.Stats, cached)
+			continue
+		}
 		sandboxStats, err := c.podSandboxStats(ctx, sandbox)
 		switch {
 		case errdefs.IsUnavailable(err), errdefs.IsNotFound(err):
@@ -44,6 +48,7 @@ func (c *criService) ListPodSandboxStats(
 		case err != nil:
 			errs = append(errs, fmt.Errorf("failed to decode sandbox container metrics for sandbox %q: %w", sandbox.ID, err))
 		default:
+			c.statsCache.putSandbox(sandboxStats)
 			podSandboxStats.Stats = append(podSandboxStats.Stats, sandboxStats)
 		}
 	}
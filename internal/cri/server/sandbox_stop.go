@@ -20,11 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/containerd/log"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 
+	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
 	sandboxstore "github.com/containerd/containerd/v2/internal/cri/store/sandbox"
 	"github.com/containerd/errdefs"
 )
@@ -53,16 +55,8 @@ func (c *criService) stopPodSandbox(ctx context.Context, sandbox sandboxstore.Sa
 	// and container may still be created, so production should not rely on this behavior.
 	// TODO(random-liu): Introduce a state in sandbox to avoid future container creation.
 	stop := time.Now()
-	containers := c.containerStore.List()
-	for _, container := range containers {
-		if container.SandboxID != id {
-			continue
-		}
-		// Forcibly stop the container. Do not use `StopContainer`, because it introduces a race
-		// if a container is removed after list.
-		if err := c.stopContainer(ctx, container, 0); err != nil {
-			return fmt.Errorf("failed to stop container %q: %w", container.ID, err)
-		}
+	if err := c.stopSandboxContainers(ctx, sandbox); err != nil {
+		return err
 	}
 
 	// Only stop sandbox container when it's running or unknown.
@@ -85,7 +79,10 @@ func (c *criService) stopPodSandbox(ctx context.Context, sandbox sandboxstore.Sa
 		log.G(ctx).WithError(err).Errorf("NRI sandbox stop notification failed")
 	}
 
-	// Teardown network for sandbox.
+	// Teardown network for sandbox through the teardown pipeline, so the CNI
+	// teardown and any mounts it holds are fully released before the network
+	// namespace is removed. Removal itself already detaches lazily, serving
+	// as the force fallback for busy mounts.
 	if sandbox.NetNS != nil {
 		netStop := time.Now()
 		// Use empty netns path if netns is not available. This is defined in:
@@ -95,11 +92,22 @@ func (c *criService) stopPodSandbox(ctx context.Context, sandbox sandboxstore.Sa
 		} else if closed {
 			sandbox.NetNSPath = ""
 		}
-		if err := c.teardownPodNetwork(ctx, sandbox); err != nil {
-			return fmt.Errorf("failed to destroy network for sandbox %q: %w", id, err)
+		steps := []teardownStep{
+			{
+				name: "teardown network",
+				run: func(ctx context.Context) error {
+					return c.teardownPodNetwork(ctx, sandbox)
+				},
+			},
+			{
+				name: "remove network namespace",
+				run: func(ctx context.Context) error {
+					return sandbox.NetNS.Remove()
+				},
+			},
 		}
-		if err := sandbox.NetNS.Remove(); err != nil {
-			return fmt.Errorf("failed to remove network namespace for sandbox %q: %w", id, err)
+		if err := runTeardownSteps(ctx, id, steps); err != nil {
+			return fmt.Errorf("failed to destroy network for sandbox %q: %w", id, err)
 		}
 		sandboxDeleteNetwork.UpdateSince(netStop)
 	}
@@ -109,6 +117,59 @@ func (c *criService) stopPodSandbox(ctx context.Context, sandbox sandboxstore.Sa
 	return nil
 }
 
+// sandboxStopTeardownReserve is the part of the request deadline kept back
+// from parallel container stops for stopping the sandbox itself and tearing
+// down its network.
+const sandboxStopTeardownReserve = 5 * time.Second
+
+// stopSandboxContainers forcibly stops all containers of the sandbox in
+// parallel. The stops share the request's remaining deadline budget minus a
+// reserve for the rest of the sandbox teardown, every container is attempted
+// even when some fail, and all failures are returned together.
+func (c *criService) stopSandboxContainers(ctx context.Context, sandbox sandboxstore.Sandbox) error {
+	var containers []containerstore.Container
+	for _, container := range c.containerStore.List() {
+		if container.SandboxID == sandbox.ID {
+			containers = append(containers, container)
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	stopCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		budget := remaining - sandboxStopTeardownReserve
+		if budget < remaining/2 {
+			budget = remaining / 2
+		}
+		var cancel context.CancelFunc
+		stopCtx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	errs := make([]error, len(containers))
+	var wg sync.WaitGroup
+	for i, container := range containers {
+		i, container := i, container
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			// Forcibly stop the container. Do not use `StopContainer`, because it introduces a race
+			// if a container is removed after list.
+			if err := c.stopContainer(stopCtx, container, 0); err != nil {
+				errs[i] = fmt.Errorf("failed to stop container %q: %w", container.ID, err)
+				return
+			}
+			sandboxStopContainerTimer.WithValues(sandbox.RuntimeHandler).UpdateSince(start)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
 // waitSandboxStop waits for sandbox to be stopped until context is cancelled or
 // the context deadline is exceeded.
 func (c *criService) waitSandboxStop(ctx context.Context, sandbox sandboxstore.Sandbox) error {
@@ -132,11 +193,17 @@ func (c *criService) teardownPodNetwork(ctx context.Context, sandbox sandboxstor
 		path   = sandbox.NetNSPath
 		config = sandbox.Config
 	)
-	opts, err := cniNamespaceOpts(id, config)
+	opts, err := c.cniNamespaceOpts(id, config)
 	if err != nil {
 		return fmt.Errorf("get cni namespace options: %w", err)
 	}
 
+	// Detach additional networks before the default network so their
+	// plugins still find the sandbox interface in place.
+	if err := c.teardownAdditionalNetworks(ctx, sandbox); err != nil {
+		return fmt.Errorf("failed to remove additional networks: %w", err)
+	}
+
 	netStart := time.Now()
 	err = netPlugin.Remove(ctx, id, path, opts...)
 	networkPluginOperations.WithValues(networkTearDownOp).Inc()
@@ -145,5 +212,6 @@ func (c *criService) teardownPodNetwork(ctx context.Context, sandbox sandboxstor
 		networkPluginOperationsErrors.WithValues(networkTearDownOp).Inc()
 		return err
 	}
+	c.deleteCNIResult(ctx, id)
 	return nil
 }
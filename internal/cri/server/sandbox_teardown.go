@@ -0,0 +1,82 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// teardownStepRetries is the default number of attempts per teardown step.
+	teardownStepRetries = 3
+	// teardownRetryDelay is the pause between attempts of a teardown step.
+	teardownRetryDelay = 500 * time.Millisecond
+)
+
+// teardownStep is one step of a sandbox teardown pipeline. Steps run in
+// order and a step must succeed, possibly through its force fallback, before
+// the next one runs, so steps releasing mounts always complete before steps
+// removing what they are mounted on.
+type teardownStep struct {
+	// name identifies the step in errors and logs.
+	name string
+	// run performs the step. It is retried on failure, since busy mounts are
+	// typically released shortly after container processes exit.
+	run func(ctx context.Context) error
+	// force, when non-nil, is a last resort (e.g. a lazy detach) run once
+	// after all attempts of run have failed.
+	force func(ctx context.Context) error
+	// retries overrides teardownStepRetries when positive.
+	retries int
+}
+
+// runTeardownSteps runs the teardown steps of a sandbox in order, stopping
+// at the first step which cannot be completed.
+func runTeardownSteps(ctx context.Context, id string, steps []teardownStep) error {
+	for _, step := range steps {
+		retries := step.retries
+		if retries <= 0 {
+			retries = teardownStepRetries
+		}
+		var err error
+		for attempt := 1; attempt <= retries; attempt++ {
+			if attempt > 1 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(teardownRetryDelay):
+				}
+			}
+			if err = step.run(ctx); err == nil {
+				break
+			}
+			log.G(ctx).WithError(err).Warnf("Teardown step %q of sandbox %q failed (attempt %d of %d)", step.name, id, attempt, retries)
+		}
+		if err != nil && step.force != nil {
+			log.G(ctx).Warnf("Forcing teardown step %q of sandbox %q", step.name, id)
+			err = step.force(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("teardown step %q: %w", step.name, err)
+		}
+	}
+	return nil
+}
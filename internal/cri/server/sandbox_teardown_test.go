@@ -0,0 +1,106 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTeardownSteps(t *testing.T) {
+	t.Run("busy mount released after retries", func(t *testing.T) {
+		attempts := 0
+		err := runTeardownSteps(context.Background(), "sandbox", []teardownStep{
+			{
+				name: "unmount",
+				run: func(context.Context) error {
+					attempts++
+					if attempts < 3 {
+						return syscall.EBUSY
+					}
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("force fallback rescues persistently busy mount", func(t *testing.T) {
+		forced := false
+		err := runTeardownSteps(context.Background(), "sandbox", []teardownStep{
+			{
+				name: "unmount",
+				run: func(context.Context) error {
+					return syscall.EBUSY
+				},
+				force: func(context.Context) error {
+					forced = true
+					return nil
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.True(t, forced)
+	})
+
+	t.Run("failing step blocks later steps", func(t *testing.T) {
+		removed := false
+		err := runTeardownSteps(context.Background(), "sandbox", []teardownStep{
+			{
+				name:    "unmount",
+				retries: 2,
+				run: func(context.Context) error {
+					return syscall.EBUSY
+				},
+			},
+			{
+				name: "remove",
+				run: func(context.Context) error {
+					removed = true
+					return nil
+				},
+			},
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, syscall.EBUSY)
+		assert.ErrorContains(t, err, `teardown step "unmount"`)
+		assert.False(t, removed)
+	})
+
+	t.Run("cancelled context stops retrying", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		err := runTeardownSteps(ctx, "sandbox", []teardownStep{
+			{
+				name: "unmount",
+				run: func(context.Context) error {
+					attempts++
+					cancel()
+					return syscall.EBUSY
+				},
+			},
+		})
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -18,9 +18,11 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"slices"
 	"sync"
 	"sync/atomic"
@@ -43,6 +45,7 @@ import (
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
 	"github.com/containerd/containerd/v2/internal/cri/nri"
 	"github.com/containerd/containerd/v2/internal/cri/server/events"
+	imagesservice "github.com/containerd/containerd/v2/internal/cri/server/images"
 	containerstore "github.com/containerd/containerd/v2/internal/cri/store/container"
 	imagestore "github.com/containerd/containerd/v2/internal/cri/store/image"
 	"github.com/containerd/containerd/v2/internal/cri/store/label"
@@ -62,6 +65,10 @@ var kernelSupportsRRO bool
 // defaultNetworkPlugin is used for the default CNI configuration
 const defaultNetworkPlugin = "default"
 
+// containerEventsReplaySize is the number of recent container events kept for
+// replay to subscribers reconnecting to GetContainerEvents.
+const containerEventsReplaySize = 128
+
 // CRIService is the interface implement CRI remote service server.
 type CRIService interface {
 	// Closer is used by containerd to gracefully stop cri service.
@@ -69,6 +76,11 @@ type CRIService interface {
 
 	IsInitialized() bool
 
+	// Healthy returns nil when the service is ready to serve requests, i.e.
+	// initialization including state recovery has completed and the critical
+	// background syncers are healthy.
+	Healthy() error
+
 	Run(ready func()) error
 }
 
@@ -107,6 +119,14 @@ type ImageService interface {
 	LocalResolve(refOrID string) (imagestore.Image, error)
 
 	ImageFSPaths() map[string]string
+
+	RegistryReloadStatus() (diff []string, enabled bool, lastErr error)
+
+	ImageGCStatus() (status imagesservice.ImageGCStatus, enabled bool)
+	TriggerImageGC()
+	RecordImageExit(imageID string)
+
+	ActivePullProgress() []imagesservice.PullProgress
 }
 
 // criService implements CRIService.
@@ -131,6 +151,12 @@ type criService struct {
 	containerNameIndex *registrar.Registrar
 	// netPlugin is used to setup and teardown network when run/stop pod sandbox.
 	netPlugin map[string]cni.CNI
+	// podCIDRs is the node's pod CIDR ranges most recently reported by the
+	// kubelet through UpdateRuntimeConfig, passed to the CNI plugins as
+	// the ipRanges capability args.
+	podCIDRs []string
+	// podCIDRsMu protects podCIDRs.
+	podCIDRsMu sync.RWMutex
 	// client is an instance of the containerd client
 	client *containerd.Client
 	// streamServer is the streaming server serves container streaming request.
@@ -143,6 +169,9 @@ type criService struct {
 	// cniNetConfMonitor is used to reload cni network conf if there is
 	// any valid fs change events from cni network conf dir.
 	cniNetConfMonitor map[string]*cniNetConfSyncer
+	// debugConfMonitor applies debug facility toggles on fs change events
+	// from the debug config file. Nil when no path is configured.
+	debugConfMonitor *debugConfSyncer
 	// allCaps is the list of the capabilities.
 	// When nil, parsed from CapEff of /proc/self/status.
 	allCaps []string //nolint:nolintlint,unused // Ignore on non-Linux
@@ -155,6 +184,29 @@ type criService struct {
 	sandboxService sandboxService
 	// runtimeHandlers contains runtime handler info
 	runtimeHandlers []*runtime.RuntimeHandler
+	// sandboxMetrics caches pod sandbox metrics for the stats collection period.
+	sandboxMetrics podSandboxMetricsCache
+	// taskMetrics serves single container stats from batched task metrics.
+	taskMetrics taskMetricsCache
+	// statsCache serves container and pod sandbox stats collected within
+	// the stats freshness window from cache. Nil when no window is
+	// configured.
+	statsCache *statsCache
+	// execAudit records Exec, ExecSync and Attach calls when configured.
+	execAudit *execAuditLogger
+	// storageHealth monitors the writability of the storage paths backing
+	// containers and images.
+	storageHealth *storageHealthMonitor
+	// cpusetAllocator assigns exclusive CPUs to containers of Guaranteed
+	// pods when an exclusive cpuset pool is configured, nil otherwise.
+	cpusetAllocator *cpusetAllocator
+	// numa places pods requesting NUMA pinning on the machine's NUMA nodes.
+	// It is nil when no runtime has a NUMA policy or the machine has a
+	// single NUMA node.
+	numa *numaAllocator
+	// podCgroups manages pod-level cgroups when the kubelet delegates their
+	// creation, nil when disabled.
+	podCgroups *podCgroupManager
 }
 
 type CRIServiceOptions struct {
@@ -198,6 +250,8 @@ func NewCRIService(options *CRIServiceOptions) (CRIService, runtime.RuntimeServi
 	}
 
 	// TODO: Make discard time configurable
+	// Recent events are replayed to reconnecting subscribers so kubelet's
+	// evented PLEG does not miss state changes across a brief disconnect.
 	c.containerEventsQ = eventq.New[runtime.ContainerEventResponse](5*time.Minute, func(event runtime.ContainerEventResponse) {
 		containerEventsDroppedCount.Inc()
 		log.L.WithFields(
@@ -205,17 +259,38 @@ func NewCRIService(options *CRIServiceOptions) (CRIService, runtime.RuntimeServi
 				"container": event.ContainerId,
 				"type":      event.ContainerEventType,
 			}).Warn("container event discarded")
-	})
+	}, eventq.WithReplay(containerEventsReplaySize))
+
+	// Expose the sandbox network counters collected into the sandbox store.
+	registerSandboxNetworkCollector(c.sandboxStore)
+
+	if window := config.StatsFreshnessWindow; window != "" {
+		d, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid stats freshness window %q: %w", window, err)
+		}
+		if d > 0 {
+			c.statsCache = newStatsCache(d)
+		}
+	}
+
+	if config.ExecAuditLogPath != "" {
+		c.execAudit, err = newExecAuditLogger(config.ExecAuditLogPath, config.ExecAuditRedactPatterns)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create exec audit logger: %w", err)
+		}
+	}
 
 	if err := c.initPlatform(); err != nil {
 		return nil, nil, fmt.Errorf("initialize platform: %w", err)
 	}
 
-	// prepare streaming server
-	c.streamServer, err = streaming.NewServer(options.StreamingConfig, newStreamRuntime(c))
+	// prepare streaming server, wrapped with the read-only logs endpoint
+	streamServer, err := streaming.NewServer(options.StreamingConfig, newStreamRuntime(c))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create stream server: %w", err)
 	}
+	c.streamServer = newLogStreamServer(c, options.StreamingConfig, streamServer)
 
 	c.eventMonitor = events.NewEventMonitor(&criEventHandler{c: c})
 
@@ -236,8 +311,50 @@ func NewCRIService(options *CRIServiceOptions) (CRIService, runtime.RuntimeServi
 		}
 	}
 
+	if path := c.config.DebugConfigPath; path != "" {
+		m, err := newDebugConfSyncer(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create debug conf monitor: %w", err)
+		}
+		c.debugConfMonitor = m
+	}
+
 	c.nri = options.NRI
 
+	// Monitor the writability of the snapshotter roots and the content store.
+	storagePaths := []string{
+		filepath.Join(config.ContainerdRootDir, fmt.Sprintf("%s.%s", plugins.ContentPlugin, "content")),
+	}
+	for _, p := range c.imageFSPaths {
+		storagePaths = append(storagePaths, p)
+	}
+	c.storageHealth = newStorageHealthMonitor(storagePaths...)
+
+	if pool := config.ExclusiveCPUSetPool; pool != "" {
+		c.cpusetAllocator, err = newCPUSetAllocator(pool, config.RootDir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create cpuset allocator: %w", err)
+		}
+	}
+
+	if config.ManagePodCgroups {
+		c.podCgroups = newPodCgroupManager()
+	}
+
+	for name, r := range config.Runtimes {
+		switch r.NUMAPolicy {
+		case "", numaPolicyPacked, numaPolicySpread:
+		default:
+			return nil, nil, fmt.Errorf("invalid numa_policy %q of runtime %q", r.NUMAPolicy, name)
+		}
+		if r.NUMAPolicy != "" && c.numa == nil {
+			c.numa, err = newNUMAAllocator(numaSysfsRoot)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to discover NUMA topology: %w", err)
+			}
+		}
+	}
+
 	c.runtimeHandlers, err = c.introspectRuntimeHandlers(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to introspect runtime handlers: %w", err)
@@ -285,6 +402,30 @@ func (c *criService) Run(ready func()) error {
 		}()
 	}
 
+	// Start the debug conf syncer. Losing it only loses runtime debug
+	// toggling, so its exit is not treated as fatal for the CRI service.
+	if c.debugConfMonitor != nil {
+		log.L.Infof("Start debug conf syncer for %s", c.config.DebugConfigPath)
+		go func() {
+			if err := c.debugConfMonitor.syncLoop(); err != nil {
+				log.L.WithError(err).Error("debug conf syncer exited")
+			}
+		}()
+	}
+
+	// Start the Prometheus service discovery endpoint if configured.
+	c.startHTTPSDServer()
+
+	// Start reconciliation of leaked CNI state if configured.
+	c.startNetworkReconciler(context.Background())
+
+	// Start probing sandbox networks if configured.
+	c.startNetworkProber(context.Background())
+
+	// Start monitoring storage path health.
+	log.L.Info("Start storage health monitor")
+	c.storageHealth.start(context.Background())
+
 	// Start streaming server.
 	log.L.Info("Start streaming server")
 	streamServerErrCh := make(chan error)
@@ -346,6 +487,11 @@ func (c *criService) Close() error {
 			log.L.WithError(err).Errorf("failed to stop cni network conf monitor for %s", name)
 		}
 	}
+	if c.debugConfMonitor != nil {
+		if err := c.debugConfMonitor.stop(); err != nil {
+			log.L.WithError(err).Error("failed to stop debug conf monitor")
+		}
+	}
 	c.eventMonitor.Stop()
 	if err := c.streamServer.Stop(); err != nil {
 		return fmt.Errorf("failed to stop stream server: %w", err)
@@ -358,6 +504,21 @@ func (c *criService) IsInitialized() bool {
 	return c.initialized.Load()
 }
 
+// Healthy reports the readiness of the CRI service for the grpc health
+// service. It distinguishes a socket that exists from a service that can
+// actually handle requests.
+func (c *criService) Healthy() error {
+	if !c.initialized.Load() {
+		return errors.New("still recovering sandbox and container state")
+	}
+	for name, syncer := range c.cniNetConfMonitor {
+		if err := syncer.lastStatus(); err != nil {
+			return fmt.Errorf("cni config syncer %q is unhealthy: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func (c *criService) introspectRuntimeHandlers(ctx context.Context) ([]*runtime.RuntimeHandler, error) {
 	var res []*runtime.RuntimeHandler
 	intro := c.client.IntrospectionService()
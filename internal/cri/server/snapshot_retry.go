@@ -0,0 +1,70 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"time"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/log"
+)
+
+// snapshotRetryInitialBackoff is the delay before the first retry of a
+// transient snapshot preparation error. It doubles on every further retry.
+const snapshotRetryInitialBackoff = 100 * time.Millisecond
+
+// withSnapshotRetry retries the snapshot preparing container opt up to
+// maxRetries times with exponential backoff when it fails with a transient
+// snapshotter error, so short device or metastore hiccups do not surface as
+// CreateContainerError to the kubelet.
+func withSnapshotRetry(f containerd.NewContainerOpts, maxRetries int) containerd.NewContainerOpts {
+	return func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
+		backoff := snapshotRetryInitialBackoff
+		for attempt := 0; ; attempt++ {
+			err := f(ctx, client, c)
+			if err == nil {
+				if attempt > 0 {
+					snapshotRetrySuccesses.Inc()
+				}
+				return nil
+			}
+			if attempt >= maxRetries || !retryableSnapshotError(err) {
+				if attempt > 0 {
+					snapshotRetryFailures.Inc()
+				}
+				return err
+			}
+			log.G(ctx).WithError(err).Warnf("Transient snapshot error for container %q, retrying in %v (%d of %d)",
+				c.ID, backoff, attempt+1, maxRetries)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// retryableSnapshotError reports whether a snapshot preparation error is
+// transient and worth retrying.
+func retryableSnapshotError(err error) bool {
+	return errdefs.IsUnavailable(err)
+}
@@ -0,0 +1,92 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/containers"
+	"github.com/containerd/errdefs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSnapshotRetry(t *testing.T) {
+	transientErr := fmt.Errorf("device busy: %w", errdefs.ErrUnavailable)
+	hardErr := errors.New("no such device")
+
+	for _, test := range []struct {
+		desc     string
+		failures int
+		failWith error
+		retries  int
+		expected error
+		calls    int
+	}{
+		{
+			desc:    "success on first attempt",
+			retries: 3,
+			calls:   1,
+		},
+		{
+			desc:     "transient error retried to success",
+			failures: 2,
+			failWith: transientErr,
+			retries:  3,
+			calls:    3,
+		},
+		{
+			desc:     "transient error exhausts retries",
+			failures: 5,
+			failWith: transientErr,
+			retries:  2,
+			expected: transientErr,
+			calls:    3,
+		},
+		{
+			desc:     "non-retryable error fails immediately",
+			failures: 1,
+			failWith: hardErr,
+			retries:  3,
+			expected: hardErr,
+			calls:    1,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var calls int
+			f := func(ctx context.Context, client *containerd.Client, c *containers.Container) error {
+				calls++
+				if calls <= test.failures {
+					return test.failWith
+				}
+				return nil
+			}
+			err := withSnapshotRetry(f, test.retries)(context.Background(), nil, &containers.Container{ID: "c1"})
+			assert.Equal(t, test.expected, err)
+			assert.Equal(t, test.calls, calls)
+		})
+	}
+}
+
+func TestRetryableSnapshotError(t *testing.T) {
+	assert.True(t, retryableSnapshotError(fmt.Errorf("wrapped: %w", errdefs.ErrUnavailable)))
+	assert.False(t, retryableSnapshotError(errors.New("bad config")))
+	assert.False(t, retryableSnapshotError(errdefs.ErrNotFound))
+}
@@ -0,0 +1,142 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// statsCache caches converted CRI stats so kubelet summary API scrapes
+// don't re-read cgroups for every ContainerStats and PodSandboxStats call.
+// Entries are populated whenever stats are collected through the tasks
+// service, served while younger than the configured freshness window, and
+// dropped when their container or sandbox is removed. A nil cache is valid
+// and caches nothing, which is how caching is disabled.
+type statsCache struct {
+	window time.Duration
+
+	mu         sync.Mutex
+	containers map[string]containerStatsEntry
+	sandboxes  map[string]sandboxStatsEntry
+}
+
+type containerStatsEntry struct {
+	stats     *runtime.ContainerStats
+	collected time.Time
+}
+
+type sandboxStatsEntry struct {
+	stats     *runtime.PodSandboxStats
+	collected time.Time
+}
+
+func newStatsCache(window time.Duration) *statsCache {
+	return &statsCache{
+		window:     window,
+		containers: make(map[string]containerStatsEntry),
+		sandboxes:  make(map[string]sandboxStatsEntry),
+	}
+}
+
+// getContainer returns the cached stats of the given container when they are
+// still within the freshness window.
+func (s *statsCache) getContainer(id string) (*runtime.ContainerStats, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.containers[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.collected) >= s.window {
+		delete(s.containers, id)
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// putContainer caches freshly collected container stats, keyed by the
+// container id in the stats attributes.
+func (s *statsCache) putContainer(stats *runtime.ContainerStats) {
+	if s == nil || stats.GetAttributes().GetId() == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.containers[stats.GetAttributes().GetId()] = containerStatsEntry{
+		stats:     stats,
+		collected: time.Now(),
+	}
+}
+
+// evictContainer drops the cached stats of a removed container.
+func (s *statsCache) evictContainer(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.containers, id)
+}
+
+// getSandbox returns the cached stats of the given sandbox when they are
+// still within the freshness window.
+func (s *statsCache) getSandbox(id string) (*runtime.PodSandboxStats, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.sandboxes[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.collected) >= s.window {
+		delete(s.sandboxes, id)
+		return nil, false
+	}
+	return entry.stats, true
+}
+
+// putSandbox caches freshly collected pod sandbox stats, keyed by the
+// sandbox id in the stats attributes.
+func (s *statsCache) putSandbox(stats *runtime.PodSandboxStats) {
+	if s == nil || stats.GetAttributes().GetId() == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sandboxes[stats.GetAttributes().GetId()] = sandboxStatsEntry{
+		stats:     stats,
+		collected: time.Now(),
+	}
+}
+
+// evictSandbox drops the cached stats of a removed sandbox.
+func (s *statsCache) evictSandbox(id string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sandboxes, id)
+}
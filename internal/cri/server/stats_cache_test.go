@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestStatsCache(t *testing.T) {
+	cache := newStatsCache(time.Minute)
+	cs := &runtime.ContainerStats{
+		Attributes: &runtime.ContainerAttributes{Id: "c1"},
+	}
+	ps := &runtime.PodSandboxStats{
+		Attributes: &runtime.PodSandboxAttributes{Id: "s1"},
+	}
+
+	_, ok := cache.getContainer("c1")
+	assert.False(t, ok, "empty cache should miss")
+
+	cache.putContainer(cs)
+	cache.putSandbox(ps)
+
+	got, ok := cache.getContainer("c1")
+	assert.True(t, ok)
+	assert.Equal(t, cs, got)
+	gotSandbox, ok := cache.getSandbox("s1")
+	assert.True(t, ok)
+	assert.Equal(t, ps, gotSandbox)
+
+	cache.evictContainer("c1")
+	cache.evictSandbox("s1")
+	_, ok = cache.getContainer("c1")
+	assert.False(t, ok, "evicted container stats should miss")
+	_, ok = cache.getSandbox("s1")
+	assert.False(t, ok, "evicted sandbox stats should miss")
+}
+
+func TestStatsCacheExpiry(t *testing.T) {
+	cache := newStatsCache(time.Nanosecond)
+	cache.putContainer(&runtime.ContainerStats{
+		Attributes: &runtime.ContainerAttributes{Id: "c1"},
+	})
+	time.Sleep(time.Millisecond)
+	_, ok := cache.getContainer("c1")
+	assert.False(t, ok, "stats older than the freshness window should miss")
+}
+
+func TestStatsCacheDisabled(t *testing.T) {
+	var cache *statsCache
+	// A nil cache caches nothing and must not panic.
+	cache.putContainer(&runtime.ContainerStats{
+		Attributes: &runtime.ContainerAttributes{Id: "c1"},
+	})
+	_, ok := cache.getContainer("c1")
+	assert.False(t, ok)
+	cache.evictContainer("c1")
+}
@@ -52,10 +52,23 @@ func (c *criService) Status(ctx context.Context, r *runtime.StatusRequest) (*run
 		}
 	}
 
+	storageCondition := &runtime.RuntimeCondition{
+		Type:   StorageReady,
+		Status: true,
+	}
+	if c.storageHealth != nil {
+		if err := c.storageHealth.healthy(); err != nil {
+			storageCondition.Status = false
+			storageCondition.Reason = storageNotReadyReason
+			storageCondition.Message = fmt.Sprintf("Storage path is not writable: %v", err)
+		}
+	}
+
 	resp := &runtime.StatusResponse{
 		Status: &runtime.RuntimeStatus{Conditions: []*runtime.RuntimeCondition{
 			runtimeCondition,
 			networkCondition,
+			storageCondition,
 		}},
 		RuntimeHandlers: c.runtimeHandlers,
 	}
@@ -95,6 +108,54 @@ func (c *criService) Status(ctx context.Context, r *runtime.StatusRequest) (*run
 			}
 		}
 		resp.Info["lastCNILoadStatus"] = defaultStatus
+
+		usageByt, err := json.Marshal(c.densityUsage())
+		if err != nil {
+			return nil, err
+		}
+		resp.Info["density"] = string(usageByt)
+
+		if c.debugConfMonitor != nil {
+			debugByt, err := json.Marshal(c.debugConfMonitor.current())
+			if err != nil {
+				return nil, err
+			}
+			resp.Info["debugConfig"] = string(debugByt)
+			s := "OK"
+			if lerr := c.debugConfMonitor.lastStatus(); lerr != nil {
+				s = lerr.Error()
+			}
+			resp.Info["lastDebugConfigLoadStatus"] = s
+		}
+
+		if gc, enabled := c.ImageGCStatus(); enabled {
+			gcByt, err := json.Marshal(gc)
+			if err != nil {
+				return nil, err
+			}
+			resp.Info["imageGC"] = string(gcByt)
+		}
+
+		if pulls := c.ActivePullProgress(); len(pulls) > 0 {
+			pullsByt, err := json.Marshal(pulls)
+			if err != nil {
+				return nil, err
+			}
+			resp.Info["activeImagePulls"] = string(pullsByt)
+		}
+
+		if diff, enabled, lerr := c.RegistryReloadStatus(); enabled {
+			s := "OK"
+			if lerr != nil {
+				s = lerr.Error()
+			}
+			resp.Info["lastRegistryConfigLoadStatus"] = s
+			diffByt, err := json.Marshal(diff)
+			if err != nil {
+				return nil, err
+			}
+			resp.Info["lastRegistryConfigDiff"] = string(diffByt)
+		}
 	}
 	intro, err := c.client.IntrospectionService().Server(ctx)
 	if err != nil {
@@ -136,6 +197,10 @@ func runtimeConditionContainerdHasNoDeprecationWarnings(deprecations []*introspe
 }
 
 const (
+	// StorageReady is a string for [runtime.RuntimeCondition.Type], reporting
+	// whether the storage paths backing containers and images are writable.
+	StorageReady = "StorageReady"
+
 	// ContainerdHasNoDeprecationWarnings is a string for [runtime.RuntimeCondition.Type].
 	ContainerdHasNoDeprecationWarnings = "ContainerdHasNoDeprecationWarnings"
 
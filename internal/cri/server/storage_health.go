@@ -0,0 +1,168 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// storageHealthCheckPeriod is how often the storage paths are probed.
+	storageHealthCheckPeriod = 30 * time.Second
+	// storageHealthProbeFile is the name of the probe file written into each
+	// monitored path.
+	storageHealthProbeFile = ".cri-storage-probe"
+)
+
+// storageNotReadyReason is the reason reported on the storage condition when
+// a monitored path is not writable.
+const storageNotReadyReason = "StorageUnhealthy"
+
+// storageHealthMonitor periodically verifies that the storage paths backing
+// containers and images are writable, catching read-only remounts and full
+// disks before they surface as mass container failures. Failures flip the
+// storage condition reported by Status so the kubelet can cordon the node.
+type storageHealthMonitor struct {
+	paths []string
+
+	mu       sync.RWMutex
+	failures map[string]error
+}
+
+func newStorageHealthMonitor(paths ...string) *storageHealthMonitor {
+	m := &storageHealthMonitor{
+		failures: make(map[string]error),
+	}
+	seen := make(map[string]struct{})
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		m.paths = append(m.paths, p)
+	}
+	sort.Strings(m.paths)
+	return m
+}
+
+// start probes all paths once and then keeps probing periodically until the
+// context is cancelled.
+func (m *storageHealthMonitor) start(ctx context.Context) {
+	m.check(ctx)
+	go func() {
+		ticker := time.NewTicker(storageHealthCheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.check(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (m *storageHealthMonitor) check(ctx context.Context) {
+	for _, p := range m.paths {
+		err := probeStoragePath(p)
+
+		m.mu.Lock()
+		prev := m.failures[p]
+		if err != nil {
+			m.failures[p] = err
+		} else {
+			delete(m.failures, p)
+		}
+		m.mu.Unlock()
+
+		switch {
+		case err != nil && prev == nil:
+			storageHealthFailures.WithValues(p, storageFailureReason(err)).Inc()
+			log.G(ctx).WithError(err).Errorf("Storage path %q became unhealthy", p)
+		case err == nil && prev != nil:
+			log.G(ctx).Infof("Storage path %q recovered", p)
+		}
+	}
+}
+
+// healthy returns nil when all monitored paths are writable, or an error
+// describing every failing path.
+func (m *storageHealthMonitor) healthy() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.failures) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(m.failures))
+	for _, p := range m.paths {
+		if err, ok := m.failures[p]; ok {
+			errs = append(errs, fmt.Errorf("path %q: %w", p, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// probeStoragePath verifies the path accepts and persists writes by writing,
+// syncing and removing a small probe file.
+func probeStoragePath(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	probe := filepath.Join(path, storageHealthProbeFile)
+	f, err := os.OpenFile(probe, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, werr := f.WriteString(time.Now().Format(time.RFC3339Nano))
+	serr := f.Sync()
+	if err := f.Close(); werr == nil && serr == nil && err != nil {
+		werr = err
+	}
+	if rerr := os.Remove(probe); werr == nil && serr == nil && rerr != nil {
+		return rerr
+	}
+	if werr != nil {
+		return werr
+	}
+	return serr
+}
+
+// storageFailureReason classifies a probe failure for the failure metric.
+func storageFailureReason(err error) string {
+	switch {
+	case errors.Is(err, syscall.ENOSPC):
+		return "enospc"
+	case errors.Is(err, syscall.EROFS):
+		return "readonly"
+	default:
+		return "error"
+	}
+}
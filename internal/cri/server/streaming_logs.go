@@ -0,0 +1,214 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/kubelet/pkg/cri/streaming"
+)
+
+// logsFollowPollInterval is how often the logs endpoint checks the log file
+// for new data when following.
+const logsFollowPollInterval = 250 * time.Millisecond
+
+// logStreamServer wraps the kubelet streaming server so the same address also
+// serves a read-only "/logs/{containerID}" endpoint streaming the container's
+// CRI log file, with optional "follow" and "tail" query parameters. It lets
+// node debugging tools follow container output without going through the
+// kubelet or reading log files directly.
+type logStreamServer struct {
+	streaming.Server
+	c      *criService
+	server *http.Server
+}
+
+// newLogStreamServer wraps the given streaming server, taking over serving on
+// the configured address so log requests can be routed alongside the
+// exec/attach/portforward endpoints.
+func newLogStreamServer(c *criService, config streaming.Config, inner streaming.Server) *logStreamServer {
+	s := &logStreamServer{
+		Server: inner,
+		c:      c,
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/logs/", http.HandlerFunc(s.serveLogs))
+	mux.Handle("/", inner)
+	s.server = &http.Server{
+		Addr:      config.Addr,
+		Handler:   mux,
+		TLSConfig: config.TLSConfig,
+	}
+	return s
+}
+
+func (s *logStreamServer) Start(stayUp bool) error {
+	if !stayUp {
+		// The CRI service always runs the stream server until shutdown.
+		return errors.New("stayUp=false is not supported")
+	}
+	if s.server.TLSConfig != nil {
+		return s.server.ListenAndServeTLS("", "")
+	}
+	return s.server.ListenAndServe()
+}
+
+func (s *logStreamServer) Stop() error {
+	return s.server.Close()
+}
+
+func (s *logStreamServer) serveLogs(w http.ResponseWriter, r *http.Request) {
+	containerID := strings.TrimPrefix(r.URL.Path, "/logs/")
+	if containerID == "" || strings.Contains(containerID, "/") {
+		http.Error(w, "invalid container id", http.StatusBadRequest)
+		return
+	}
+	cntr, err := s.c.containerStore.Get(containerID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("container %q not found", containerID), http.StatusNotFound)
+		return
+	}
+	logPath := cntr.Metadata.LogPath
+	if logPath == "" {
+		http.Error(w, fmt.Sprintf("container %q has no log path configured", containerID), http.StatusNotFound)
+		return
+	}
+
+	follow := false
+	if v := r.URL.Query().Get("follow"); v != "" {
+		follow, err = strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid follow parameter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	tail := int64(-1)
+	if v := r.URL.Query().Get("tail"); v != "" {
+		tail, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || tail < 0 {
+			http.Error(w, fmt.Sprintf("invalid tail parameter %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to open log file: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	if tail >= 0 {
+		offset, err := tailOffset(f, tail)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to seek log file: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			http.Error(w, fmt.Sprintf("failed to seek log file: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			if !follow {
+				return
+			}
+			// Stop following once the container is gone and all data has
+			// been read, so clients do not hang on removed containers.
+			if _, cerr := s.c.containerStore.Get(containerID); cerr != nil {
+				return
+			}
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(logsFollowPollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// tailOffset returns the offset of the first byte of the trailing n lines of
+// the file, scanning backwards from the end.
+func tailOffset(f *os.File, n int64) (int64, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := st.Size()
+	if n == 0 {
+		return size, nil
+	}
+	var (
+		blockSize = int64(4096)
+		lines     int64
+		end       = size
+		buf       = make([]byte, blockSize)
+	)
+	// Ignore a trailing newline terminating the last line.
+	if size > 0 {
+		if _, err := f.ReadAt(buf[:1], size-1); err == nil && buf[0] == '\n' {
+			end--
+		}
+	}
+	for end > 0 {
+		start := end - blockSize
+		if start < 0 {
+			start = 0
+		}
+		b := buf[:end-start]
+		if _, err := f.ReadAt(b, start); err != nil {
+			return 0, err
+		}
+		for i := int64(len(b)) - 1; i >= 0; i-- {
+			if b[i] == '\n' {
+				lines++
+				if lines == n {
+					return start + i + 1, nil
+				}
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
@@ -0,0 +1,89 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailOffset(t *testing.T) {
+	for _, test := range []struct {
+		desc     string
+		content  string
+		tail     int64
+		expected string
+	}{
+		{
+			desc:     "zero tail returns nothing",
+			content:  "line1\nline2\nline3\n",
+			tail:     0,
+			expected: "",
+		},
+		{
+			desc:     "last line",
+			content:  "line1\nline2\nline3\n",
+			tail:     1,
+			expected: "line3\n",
+		},
+		{
+			desc:     "last two lines",
+			content:  "line1\nline2\nline3\n",
+			tail:     2,
+			expected: "line2\nline3\n",
+		},
+		{
+			desc:     "tail larger than file returns everything",
+			content:  "line1\nline2\n",
+			tail:     10,
+			expected: "line1\nline2\n",
+		},
+		{
+			desc:     "unterminated last line",
+			content:  "line1\nline2",
+			tail:     1,
+			expected: "line2",
+		},
+		{
+			desc:     "empty file",
+			content:  "",
+			tail:     5,
+			expected: "",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "container.log")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0600))
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+
+			offset, err := tailOffset(f, test.tail)
+			require.NoError(t, err)
+			_, err = f.Seek(offset, io.SeekStart)
+			require.NoError(t, err)
+			got, err := io.ReadAll(f)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(got))
+		})
+	}
+}
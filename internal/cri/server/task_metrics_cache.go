@@ -0,0 +1,76 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/v2/api/services/tasks/v1"
+	"github.com/containerd/containerd/v2/api/types"
+)
+
+// taskMetricsCache answers per-container stats requests from one batched
+// Metrics call covering all tasks, refreshed at most once per metrics
+// collection period. Without it, a kubelet asking for each container
+// individually turns into one shim round trip per container, which adds up
+// on nodes running hundreds of containers.
+type taskMetricsCache struct {
+	mu        sync.Mutex
+	collected time.Time
+	metrics   map[string]*types.Metric
+}
+
+// taskMetric returns the most recently collected metric of the task with the
+// given id, fetching a fresh batch for all tasks when the cached one is older
+// than the metrics collection period.
+func (c *criService) taskMetric(ctx context.Context, id string) (*types.Metric, error) {
+	window := time.Duration(c.config.MetricsCollectPeriod) * time.Second
+	if window <= 0 {
+		// Caching disabled, query the single task directly.
+		resp, err := c.client.TaskService().Metrics(ctx, &tasks.MetricsRequest{Filters: []string{"id==" + id}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metrics for task: %w", err)
+		}
+		if len(resp.Metrics) != 1 {
+			return nil, fmt.Errorf("unexpected metrics response: %+v", resp.Metrics)
+		}
+		return resp.Metrics[0], nil
+	}
+
+	c.taskMetrics.mu.Lock()
+	defer c.taskMetrics.mu.Unlock()
+	if c.taskMetrics.collected.IsZero() || time.Since(c.taskMetrics.collected) >= window {
+		resp, err := c.client.TaskService().Metrics(ctx, &tasks.MetricsRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metrics for tasks: %w", err)
+		}
+		metrics := make(map[string]*types.Metric, len(resp.Metrics))
+		for _, stat := range resp.Metrics {
+			metrics[stat.ID] = stat
+		}
+		c.taskMetrics.metrics = metrics
+		c.taskMetrics.collected = time.Now()
+	}
+	metric, ok := c.taskMetrics.metrics[id]
+	if !ok {
+		return nil, fmt.Errorf("no metrics collected for task %q", id)
+	}
+	return metric, nil
+}
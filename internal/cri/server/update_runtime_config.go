@@ -67,31 +67,37 @@ func (c *criService) UpdateRuntimeConfig(ctx context.Context, r *runtime.UpdateR
 		return nil, fmt.Errorf("get routes: %w", err)
 	}
 
+	// Remember the node's pod CIDRs; sandbox network setup passes them
+	// down to the CNI plugins as the ipRanges capability args.
+	c.podCIDRsMu.Lock()
+	c.podCIDRs = cidrs
+	c.podCIDRsMu.Unlock()
+
 	confTemplate := c.config.NetworkPluginConfTemplate
 	if confTemplate == "" {
 		log.G(ctx).Info("No cni config template is specified, wait for other system components to drop the config.")
 		return &runtime.UpdateRuntimeConfigResponse{}, nil
 	}
-	netPlugin := c.netPlugin[defaultNetworkPlugin]
-	if netPlugin == nil {
-		log.G(ctx).Infof("Network plugin is ready, skip generating cni config from template %q", confTemplate)
-		return &runtime.UpdateRuntimeConfigResponse{}, nil
-	}
-	netStart := time.Now()
-	err = netPlugin.Status()
-	networkPluginOperations.WithValues(networkStatusOp).Inc()
-	networkPluginOperationsLatency.WithValues(networkStatusOp).UpdateSince(netStart)
-	if err == nil {
-		log.G(ctx).Infof("Network plugin is ready, skip generating cni config from template %q", confTemplate)
-		return &runtime.UpdateRuntimeConfigResponse{}, nil
-	}
-	networkPluginOperationsErrors.WithValues(networkStatusOp).Inc()
-	if err := netPlugin.Load(c.cniLoadOptions()...); err == nil {
-		log.G(ctx).Infof("CNI config is successfully loaded, skip generating cni config from template %q", confTemplate)
-		return &runtime.UpdateRuntimeConfigResponse{}, nil
-	}
-	if err := writeCNIConfigFile(ctx, c.config.NetworkPluginConfDir, confTemplate, cidrs[0], cidrs, routes); err != nil {
-		return nil, err
+	// Generate the config for every network plugin whose conf dir, global
+	// or runtime class specific, has no usable config yet.
+	for name, netPlugin := range c.netPlugin {
+		confDir := c.networkPluginConfDir(name)
+		netStart := time.Now()
+		err = netPlugin.Status()
+		networkPluginOperations.WithValues(networkStatusOp).Inc()
+		networkPluginOperationsLatency.WithValues(networkStatusOp).UpdateSince(netStart)
+		if err == nil {
+			log.G(ctx).Infof("Network plugin for %q is ready, skip generating cni config from template %q", confDir, confTemplate)
+			continue
+		}
+		networkPluginOperationsErrors.WithValues(networkStatusOp).Inc()
+		if err := netPlugin.Load(c.cniLoadOptions()...); err == nil {
+			log.G(ctx).Infof("CNI config for %q is successfully loaded, skip generating cni config from template %q", confDir, confTemplate)
+			continue
+		}
+		if err := writeCNIConfigFile(ctx, confDir, confTemplate, cidrs[0], cidrs, routes); err != nil {
+			return nil, err
+		}
 	}
 	return &runtime.UpdateRuntimeConfigResponse{}, nil
 }
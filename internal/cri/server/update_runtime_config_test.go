@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/containerd/go-cni"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
@@ -141,3 +142,61 @@ func TestUpdateRuntimeConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateRuntimeConfigPodCIDRs(t *testing.T) {
+	c := newTestCRIService()
+	assert.Empty(t, c.cniIPRanges())
+
+	req := &runtime.UpdateRuntimeConfigRequest{
+		RuntimeConfig: &runtime.RuntimeConfig{
+			NetworkConfig: &runtime.NetworkConfig{
+				PodCidr: "10.0.0.0/24, 2001:4860:4860::/64",
+			},
+		},
+	}
+	_, err := c.UpdateRuntimeConfig(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, []cni.IPRanges{
+		{Subnet: "10.0.0.0/24"},
+		{Subnet: "2001:4860:4860::/64"},
+	}, c.cniIPRanges())
+}
+
+func TestUpdateRuntimeConfigRuntimeClassConfDir(t *testing.T) {
+	const testTemplate = `{"name": "test-pod-network", "subnet": "{{.PodCIDR}}"}`
+	testDir := t.TempDir()
+	templateName := filepath.Join(testDir, "template")
+	require.NoError(t, os.WriteFile(templateName, []byte(testTemplate), 0666))
+	confDir := filepath.Join(testDir, "net.d")
+	kataConfDir := filepath.Join(testDir, "kata-net.d")
+
+	c := newTestCRIService()
+	c.config.CniConfig = criconfig.CniConfig{
+		NetworkPluginConfDir:      confDir,
+		NetworkPluginConfTemplate: templateName,
+	}
+	c.config.Runtimes = map[string]criconfig.Runtime{
+		"kata": {NetworkPluginConfDir: kataConfDir},
+	}
+	c.netPlugin["kata"] = servertesting.NewFakeCNIPlugin()
+	for _, plugin := range c.netPlugin {
+		plugin.(*servertesting.FakeCNIPlugin).StatusErr = errors.New("random error")
+		plugin.(*servertesting.FakeCNIPlugin).LoadErr = errors.New("random error")
+	}
+
+	req := &runtime.UpdateRuntimeConfigRequest{
+		RuntimeConfig: &runtime.RuntimeConfig{
+			NetworkConfig: &runtime.NetworkConfig{
+				PodCidr: "10.0.0.0/24",
+			},
+		},
+	}
+	_, err := c.UpdateRuntimeConfig(context.Background(), req)
+	assert.NoError(t, err)
+	expected := `{"name": "test-pod-network", "subnet": "10.0.0.0/24"}`
+	for _, dir := range []string{confDir, kataConfDir} {
+		got, err := os.ReadFile(filepath.Join(dir, cniConfigFileName))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(got))
+	}
+}
@@ -60,6 +60,9 @@ type Metadata struct {
 	RuntimeHandler string
 	// CNIresult resulting configuration for attached network namespace interfaces
 	CNIResult *cni.Result
+	// AdditionalCNIResult is the merged CNI result of the additional
+	// networks the sandbox is attached to, nil without attachments
+	AdditionalCNIResult *cni.Result
 	// ProcessLabel is the SELinux process label for the container
 	ProcessLabel string
 }
@@ -47,6 +47,10 @@ type Sandbox struct {
 	*store.StopCh
 	// Stats contains (mutable) stats for the (pause) sandbox container
 	Stats *stats.ContainerStats
+	// NetworkStats contains (mutable) per-interface network counters of
+	// the sandbox network namespace. Nil for host network sandboxes and
+	// before the first stats collection.
+	NetworkStats *stats.NetworkStats
 }
 
 // NewSandbox creates an internally used sandbox type. This functions reminds
@@ -151,6 +155,30 @@ func (s *Store) UpdateContainerStats(id string, newContainerStats *stats.Contain
 	return nil
 }
 
+// UpdateNetworkStats updates the sandbox specified by ID with the given
+// network namespace counters. Returns errdefs.ErrNotFound if the sandbox
+// does not exist in the store.
+func (s *Store) UpdateNetworkStats(id string, newNetworkStats *stats.NetworkStats) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	id, err := s.idIndex.Get(id)
+	if err != nil {
+		if err == truncindex.ErrNotExist {
+			err = errdefs.ErrNotFound
+		}
+		return err
+	}
+
+	if _, ok := s.sandboxes[id]; !ok {
+		return errdefs.ErrNotFound
+	}
+
+	c := s.sandboxes[id]
+	c.NetworkStats = newNetworkStats
+	s.sandboxes[id] = c
+	return nil
+}
+
 // Delete deletes the sandbox with specified id.
 func (s *Store) Delete(id string) {
 	s.lock.Lock()
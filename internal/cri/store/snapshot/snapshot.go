@@ -40,6 +40,17 @@ type Snapshot struct {
 	Size uint64
 	// Inodes is the number of inodes used by the snapshot
 	Inodes uint64
+	// Parent is the key of the parent snapshot, empty for base layers.
+	Parent string
+	// ParentChain are the keys of the snapshot's ancestors, nearest first.
+	ParentChain []string
+	// ImageRef is the reference of the image backing the snapshot, when
+	// recorded at creation time. Set for container rootfs snapshots created
+	// through CRI.
+	ImageRef string
+	// Driver is the name of the snapshotter driver serving the snapshot,
+	// e.g. "overlayfs".
+	Driver string
 	// Timestamp is latest update time (in nanoseconds) of the snapshot
 	// information.
 	Timestamp int64
@@ -25,3 +25,31 @@ type ContainerStats struct {
 	// Cumulative CPU usage (sum across all cores) since object creation.
 	UsageCoreNanoSeconds uint64
 }
+
+// NetworkStats contains the per-interface network counters collected from
+// a sandbox's network namespace.
+type NetworkStats struct {
+	// Timestamp of when stats were collected
+	Timestamp time.Time
+	// Interfaces contains the counters of every interface in the network
+	// namespace except loopback.
+	Interfaces []InterfaceStats
+}
+
+// InterfaceStats contains the network counters of a single interface.
+type InterfaceStats struct {
+	// Name is the interface name inside the network namespace.
+	Name string
+	// RxBytes is the cumulative number of bytes received.
+	RxBytes uint64
+	// RxPackets is the cumulative number of packets received.
+	RxPackets uint64
+	// RxErrors is the cumulative number of receive errors.
+	RxErrors uint64
+	// TxBytes is the cumulative number of bytes transmitted.
+	TxBytes uint64
+	// TxPackets is the cumulative number of packets transmitted.
+	TxPackets uint64
+	// TxErrors is the cumulative number of transmit errors.
+	TxErrors uint64
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package debuglog lets verbose (debug level) logging be switched on for
+// individual subsystems at runtime without lowering the global log level.
+// Subsystems route their chatty messages through G with a stable name
+// (e.g. "snapshotter", "imagegc"); those messages are filtered by the
+// global level as usual until the subsystem is enabled, typically through
+// the CRI debug config syncer while an incident is being investigated.
+package debuglog
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/containerd/log"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	mu      sync.RWMutex
+	enabled = map[string]struct{}{}
+
+	// verboseLogger emits at debug level regardless of the global level. It
+	// mirrors the output and formatter of the standard logger, synced on
+	// every SetSubsystems call.
+	verboseLogger = logrus.New()
+)
+
+func init() {
+	verboseLogger.SetLevel(logrus.DebugLevel)
+}
+
+// SetSubsystems replaces the set of subsystems with verbose logging enabled.
+func SetSubsystems(subsystems []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = make(map[string]struct{}, len(subsystems))
+	for _, s := range subsystems {
+		enabled[s] = struct{}{}
+	}
+	verboseLogger.SetOutput(log.L.Logger.Out)
+	verboseLogger.SetFormatter(log.L.Logger.Formatter)
+}
+
+// Enabled reports whether verbose logging is enabled for subsystem.
+func Enabled(subsystem string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	_, ok := enabled[subsystem]
+	return ok
+}
+
+// Subsystems returns the sorted subsystems with verbose logging enabled.
+func Subsystems() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	subsystems := make([]string, 0, len(enabled))
+	for s := range enabled {
+		subsystems = append(subsystems, s)
+	}
+	sort.Strings(subsystems)
+	return subsystems
+}
+
+// G returns the logger of ctx tagged with the subsystem name. While verbose
+// logging is enabled for the subsystem the entry logs at debug level even
+// when the global level filters debug messages.
+func G(ctx context.Context, subsystem string) *log.Entry {
+	if Enabled(subsystem) {
+		return verboseLogger.WithContext(ctx).WithField("subsystem", subsystem)
+	}
+	return log.G(ctx).WithField("subsystem", subsystem)
+}
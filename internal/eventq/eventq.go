@@ -32,6 +32,23 @@ type eventSubscription[T any] struct {
 	closeC chan struct{}
 }
 
+type queueOptions struct {
+	replaySize int
+}
+
+// Opt configures an EventQueue.
+type Opt func(*queueOptions)
+
+// WithReplay keeps the last size events queued after delivering them, so
+// subscribers arriving later, such as a client reconnecting to an event
+// stream, receive the recent history before live events. Replayed events
+// still expire after the queue's discard duration.
+func WithReplay(size int) Opt {
+	return func(o *queueOptions) {
+		o.replaySize = size
+	}
+}
+
 func (sub eventSubscription[T]) publish(event T) bool {
 	select {
 	case <-sub.closeC:
@@ -53,7 +70,12 @@ func (sub eventSubscription[T]) Close() error {
 // New provides a queue for sending messages to one or more
 // subscribers. Messages are held for the given discardAfter duration
 // if there are no subscribers.
-func New[T any](discardAfter time.Duration, discardFn func(T)) EventQueue[T] {
+func New[T any](discardAfter time.Duration, discardFn func(T), opts ...Opt) EventQueue[T] {
+	var options queueOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	events := make(chan T)
 	subscriberC := make(chan eventSubscription[T])
 	shutdownC := make(chan struct{})
@@ -62,6 +84,7 @@ func New[T any](discardAfter time.Duration, discardFn func(T)) EventQueue[T] {
 		type queuedEvent struct {
 			event     T
 			discardAt time.Time
+			delivered bool
 		}
 
 		var discardQueue []queuedEvent
@@ -71,13 +94,16 @@ func New[T any](discardAfter time.Duration, discardFn func(T)) EventQueue[T] {
 			select {
 			case <-shutdownC:
 				for _, event := range discardQueue {
-					discardFn(event.event)
+					if !event.delivered {
+						discardFn(event.event)
+					}
 				}
 				for _, sub := range subscribers {
 					close(sub.c)
 				}
 				return
 			case event := <-events:
+				delivered := false
 				if len(subscribers) > 0 {
 					active := subscribers[:0]
 					for _, sub := range subscribers {
@@ -86,28 +112,41 @@ func New[T any](discardAfter time.Duration, discardFn func(T)) EventQueue[T] {
 						}
 					}
 					subscribers = active
+					delivered = len(subscribers) > 0
 				}
-				if len(subscribers) == 0 {
+				if !delivered || options.replaySize > 0 {
 					discardQueue = append(discardQueue, queuedEvent{
 						event:     event,
 						discardAt: time.Now().Add(discardAfter),
+						delivered: delivered,
 					})
+					// Keep the replay buffer bounded, dropping the
+					// oldest events first.
+					if options.replaySize > 0 && len(discardQueue) > options.replaySize {
+						if !discardQueue[0].delivered {
+							discardFn(discardQueue[0].event)
+						}
+						discardQueue = discardQueue[1:]
+					}
 					if discardTime == nil {
 						discardTime = time.After(time.Until(discardQueue[0].discardAt).Abs())
 					}
 				}
 			case s := <-subscriberC:
 				var closed bool
-				for i, event := range discardQueue {
-					if !s.publish(event.event) {
+				for i := range discardQueue {
+					if !s.publish(discardQueue[i].event) {
 						discardQueue = discardQueue[i:]
 						closed = true
 						break
 					}
+					discardQueue[i].delivered = true
 				}
 				if !closed {
-					discardQueue = nil
-					discardTime = nil
+					if options.replaySize == 0 {
+						discardQueue = nil
+						discardTime = nil
+					}
 					subscribers = append(subscribers, s)
 				}
 			case t := <-discardTime:
@@ -115,7 +154,9 @@ func New[T any](discardAfter time.Duration, discardFn func(T)) EventQueue[T] {
 				discardQueue = nil
 				for i, event := range toDiscard {
 					if t.After(event.discardAt) {
-						discardFn(event.event)
+						if !event.delivered {
+							discardFn(event.event)
+						}
 					} else {
 						discardQueue = toDiscard[i:]
 						break
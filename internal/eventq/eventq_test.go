@@ -82,6 +82,39 @@ func TestSubscribersDifferentTime(t *testing.T) {
 	assert.Equal(t, expected[2:], c2.Collected())
 }
 
+func TestReplayToLateSubscriber(t *testing.T) {
+	eq := New[int](3600*time.Second, func(int) {}, WithReplay(100))
+	expected := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	c1 := newCollector(eq)
+	for _, i := range expected[:4] {
+		eq.Send(i)
+	}
+	// The late subscriber sees the events already delivered to c1.
+	c2 := newCollector(eq)
+	for _, i := range expected[4:] {
+		eq.Send(i)
+	}
+
+	eq.Shutdown()
+	assert.Equal(t, expected, c1.Collected())
+	assert.Equal(t, expected, c2.Collected())
+}
+
+func TestReplayBufferBounded(t *testing.T) {
+	discarded := []int{}
+	eq := New[int](3600*time.Second, func(i int) {
+		discarded = append(discarded, i)
+	}, WithReplay(3))
+	for i := 1; i <= 9; i++ {
+		eq.Send(i)
+	}
+	c := newCollector(eq)
+
+	eq.Shutdown()
+	assert.Equal(t, []int{7, 8, 9}, c.Collected())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, discarded)
+}
+
 func TestDiscardedAfterTime(t *testing.T) {
 	discarded := []int{}
 	eq := New[int](time.Microsecond, func(i int) {
@@ -0,0 +1,139 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package ioclass lowers the Linux I/O scheduling class of containerd's own
+// background storage work, so bulk activities like pull unpacking, image
+// conversion, garbage collection and snapshot cleanup do not starve the I/O
+// of latency-sensitive workloads. On other platforms all operations are
+// no-ops.
+package ioclass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Activities whose I/O class can be configured.
+const (
+	// ActivityPullUnpack covers unpacking pulled image layers into snapshots.
+	ActivityPullUnpack = "pull_unpack"
+	// ActivityConversion covers helper processes converting image formats.
+	ActivityConversion = "conversion"
+	// ActivityGC covers garbage collection of image content.
+	ActivityGC = "gc"
+	// ActivityCleanup covers removal of snapshot directories.
+	ActivityCleanup = "cleanup"
+)
+
+// I/O scheduling class names accepted by Configure.
+const (
+	ClassBestEffort = "best-effort"
+	ClassIdle       = "idle"
+)
+
+// Class is an I/O scheduling class with a priority level within it.
+type Class struct {
+	// Name is ClassBestEffort or ClassIdle.
+	Name string
+	// Priority is the level within the class from 0 (highest) to 7. It is
+	// only meaningful for the best-effort class.
+	Priority int
+}
+
+var (
+	classesMu sync.RWMutex
+	classes   = map[string]Class{}
+)
+
+// Configure assigns I/O classes to activities. Values are "idle" or
+// "best-effort[:level]" with level 0-7, e.g. "best-effort:6". Activities not
+// configured keep the daemon's own I/O priority.
+func Configure(config map[string]string) error {
+	parsed := make(map[string]Class, len(config))
+	for activity, value := range config {
+		switch activity {
+		case ActivityPullUnpack, ActivityConversion, ActivityGC, ActivityCleanup:
+		default:
+			return fmt.Errorf("unknown I/O class activity %q", activity)
+		}
+		c, err := parseClass(value)
+		if err != nil {
+			return fmt.Errorf("invalid I/O class for activity %q: %w", activity, err)
+		}
+		parsed[activity] = c
+	}
+	classesMu.Lock()
+	for activity, c := range parsed {
+		classes[activity] = c
+	}
+	classesMu.Unlock()
+	return nil
+}
+
+func parseClass(value string) (Class, error) {
+	name, level, levelled := strings.Cut(value, ":")
+	c := Class{Name: name}
+	switch name {
+	case ClassIdle:
+		if levelled {
+			return Class{}, fmt.Errorf("class %q takes no priority level", name)
+		}
+	case ClassBestEffort:
+		if levelled {
+			p, err := strconv.Atoi(level)
+			if err != nil || p < 0 || p > 7 {
+				return Class{}, fmt.Errorf("priority level %q must be between 0 and 7", level)
+			}
+			c.Priority = p
+		}
+	default:
+		return Class{}, fmt.Errorf("unknown class %q", name)
+	}
+	return c, nil
+}
+
+func classFor(activity string) (Class, bool) {
+	classesMu.RLock()
+	defer classesMu.RUnlock()
+	c, ok := classes[activity]
+	return c, ok
+}
+
+// Run executes fn with the I/O class configured for the activity applied to
+// the calling thread, restoring the previous priority afterwards. The class
+// only covers I/O issued from fn itself, not from goroutines it spawns. When
+// no class is configured for the activity, fn runs unchanged.
+func Run(activity string, fn func()) {
+	c, ok := classFor(activity)
+	if !ok {
+		fn()
+		return
+	}
+	runWithClass(c, fn)
+}
+
+// SetProcess applies the I/O class configured for the activity to a helper
+// process, e.g. a started image conversion command. It is a no-op when no
+// class is configured for the activity.
+func SetProcess(activity string, pid int) error {
+	c, ok := classFor(activity)
+	if !ok {
+		return nil
+	}
+	return setProcessClass(pid, c)
+}
@@ -0,0 +1,88 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ioclass
+
+import (
+	"runtime"
+
+	"github.com/containerd/log"
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_set(2) constants, not exposed by the unix package.
+const (
+	ioprioWhoProcess = 1
+
+	ioprioClassBestEffort = 2
+	ioprioClassIdle       = 3
+
+	ioprioClassShift = 13
+)
+
+func ioprioValue(c Class) int {
+	class := ioprioClassBestEffort
+	if c.Name == ClassIdle {
+		class = ioprioClassIdle
+	}
+	return class<<ioprioClassShift | c.Priority
+}
+
+// setIOPrio sets the raw I/O priority of a process, or of the calling thread
+// when pid is zero.
+func setIOPrio(pid, value int) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), uintptr(value))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func getIOPrio(pid int) (int, error) {
+	value, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(value), nil
+}
+
+// runWithClass runs fn on the calling thread with the given I/O class,
+// restoring the thread's previous I/O priority afterwards. Priority errors
+// are logged and fn still runs at the current priority.
+func runWithClass(c Class, fn func()) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	prev, err := getIOPrio(0)
+	if err == nil {
+		err = setIOPrio(0, ioprioValue(c))
+	}
+	if err != nil {
+		log.L.WithError(err).Warnf("failed to set %s I/O class", c.Name)
+		fn()
+		return
+	}
+	defer func() {
+		if err := setIOPrio(0, prev); err != nil {
+			log.L.WithError(err).Warn("failed to restore I/O priority")
+		}
+	}()
+	fn()
+}
+
+func setProcessClass(pid int, c Class) error {
+	return setIOPrio(pid, ioprioValue(c))
+}
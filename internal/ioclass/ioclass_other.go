@@ -0,0 +1,29 @@
+//go:build !linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package ioclass
+
+// runWithClass runs fn unchanged, I/O classes are only supported on Linux.
+func runWithClass(_ Class, fn func()) {
+	fn()
+}
+
+// setProcessClass is a no-op, I/O classes are only supported on Linux.
+func setProcessClass(int, Class) error {
+	return nil
+}
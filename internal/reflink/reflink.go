@@ -0,0 +1,73 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package reflink clones file contents with the filesystem's reflink support
+// (the FICLONE ioctl), so the clone shares extents with its source instead of
+// duplicating the data. XFS formatted with reflink=1 and btrfs support this;
+// on other filesystems, or on platforms without FICLONE, Clone returns
+// ErrNotSupported and callers fall back to a regular copy.
+package reflink
+
+import (
+	"errors"
+	"os"
+
+	metrics "github.com/docker/go-metrics"
+)
+
+// ErrNotSupported is returned by Clone when the filesystem holding the files,
+// or the platform, cannot clone file extents.
+var ErrNotSupported = errors.New("reflink is not supported")
+
+var (
+	clonedFiles metrics.Counter
+	clonedBytes metrics.Counter
+)
+
+func init() {
+	ns := metrics.NewNamespace("containerd", "reflink", nil)
+	clonedFiles = ns.NewCounter("cloned_files", "cumulative number of files cloned by reflink instead of copied")
+	clonedBytes = ns.NewCounter("cloned_bytes", "cumulative bytes shared with their source by reflink clones instead of being duplicated")
+	metrics.Register(ns)
+}
+
+// Clone makes target a reflink clone of source, sharing its extents until
+// either side is written to. Both files must be on the same filesystem.
+func Clone(target, source string) error {
+	if err := clone(target, source); err != nil {
+		return err
+	}
+	clonedFiles.Inc()
+	if fi, err := os.Stat(target); err == nil {
+		clonedBytes.Inc(float64(fi.Size()))
+	}
+	return nil
+}
+
+// Supported reports whether files on the filesystem holding dir can be
+// cloned, by cloning a temporary file.
+func Supported(dir string) bool {
+	src, err := os.CreateTemp(dir, ".reflink-check-")
+	if err != nil {
+		return false
+	}
+	defer os.Remove(src.Name())
+	defer src.Close()
+
+	target := src.Name() + "-clone"
+	defer os.Remove(target)
+	return clone(target, src.Name()) == nil
+}
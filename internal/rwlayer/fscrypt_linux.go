@@ -0,0 +1,172 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rwlayer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"github.com/containerd/log"
+	"golang.org/x/sys/unix"
+)
+
+// fscryptDriver encrypts writable layer directories with the kernel fscrypt
+// v2 API. The master key of a pod is added to the filesystem on first use and
+// removed again when the pod's key is destroyed, which evicts the inline
+// crypto keys and makes the remaining ciphertext unreadable.
+type fscryptDriver struct {
+	keys KeyProvider
+
+	mu sync.Mutex
+	// identifiers maps pod IDs to the kernel computed master key identifier
+	// returned when the key was added, and a path on the filesystem the key
+	// was added to, both needed to remove the key again.
+	identifiers map[string]addedKey
+}
+
+// addedKey records where a pod's master key was added.
+type addedKey struct {
+	identifier [16]uint8
+	fsPath     string
+}
+
+func newFscryptDriver(keys KeyProvider) (Driver, error) {
+	if keys == nil {
+		return nil, fmt.Errorf("fscrypt rw-layer driver requires a key provider")
+	}
+	return &fscryptDriver{
+		keys:        keys,
+		identifiers: make(map[string]addedKey),
+	}, nil
+}
+
+func (d *fscryptDriver) Setup(ctx context.Context, dir, podID string) error {
+	key, err := d.keys.Key(ctx, podID)
+	if err != nil {
+		return err
+	}
+	if len(key) < 32 {
+		return fmt.Errorf("key for pod %q is too short, fscrypt requires at least 32 bytes", podID)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	identifier, err := d.addKey(f.Fd(), dir, podID, key)
+	if err != nil {
+		return fmt.Errorf("failed to add fscrypt key for pod %q: %w", podID, err)
+	}
+
+	policy := unix.FscryptPolicyV2{
+		Version:                   2, // FSCRYPT_POLICY_V2
+		Contents_encryption_mode:  unix.FSCRYPT_MODE_AES_256_XTS,
+		Filenames_encryption_mode: unix.FSCRYPT_MODE_AES_256_CTS,
+		Flags:                     unix.FSCRYPT_POLICY_FLAGS_PAD_32,
+		Master_key_identifier:     identifier,
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.FS_IOC_SET_ENCRYPTION_POLICY, uintptr(unsafe.Pointer(&policy))); errno != 0 {
+		return fmt.Errorf("failed to set fscrypt policy on %q: %w", dir, errno)
+	}
+	return nil
+}
+
+// addKey adds the pod's master key to the filesystem containing fd and
+// returns the key identifier computed by the kernel.
+func (d *fscryptDriver) addKey(fd uintptr, dir, podID string, key []byte) ([16]uint8, error) {
+	var identifier [16]uint8
+
+	d.mu.Lock()
+	added, ok := d.identifiers[podID]
+	d.mu.Unlock()
+	if ok {
+		// Key already added on this filesystem; adding it again would be
+		// harmless but the identifier is already known.
+		return added.identifier, nil
+	}
+
+	argSize := int(unsafe.Sizeof(unix.FscryptAddKeyArg{}))
+	buf := make([]byte, argSize+len(key))
+	arg := (*unix.FscryptAddKeyArg)(unsafe.Pointer(&buf[0]))
+	arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+	arg.Raw_size = uint32(len(key))
+	copy(buf[argSize:], key)
+
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, unix.FS_IOC_ADD_ENCRYPTION_KEY, uintptr(unsafe.Pointer(&buf[0]))); errno != 0 {
+		return identifier, errno
+	}
+	copy(identifier[:], arg.Key_spec.U[:16])
+
+	d.mu.Lock()
+	d.identifiers[podID] = addedKey{identifier: identifier, fsPath: dir}
+	d.mu.Unlock()
+	return identifier, nil
+}
+
+func (d *fscryptDriver) DestroyPodKey(ctx context.Context, podID string) error {
+	d.mu.Lock()
+	added, ok := d.identifiers[podID]
+	delete(d.identifiers, podID)
+	d.mu.Unlock()
+
+	// Destroy the key at the KMS first so it cannot be fetched again even if
+	// the in-kernel removal below fails.
+	kmsErr := d.keys.Destroy(ctx, podID)
+
+	if ok {
+		// Removing the key requires a file descriptor on the filesystem the
+		// key was added to. The snapshot directory may already be gone, so
+		// fall back to its parent directories. Failures are logged: without
+		// the key material the data is unreadable after the next key
+		// eviction or reboot anyway.
+		f, err := openOnFilesystem(added.fsPath)
+		if err != nil {
+			log.G(ctx).WithError(err).Warnf("failed to open filesystem to remove fscrypt key of pod %q", podID)
+			return kmsErr
+		}
+		defer f.Close()
+		arg := unix.FscryptRemoveKeyArg{}
+		arg.Key_spec.Type = unix.FSCRYPT_KEY_SPEC_TYPE_IDENTIFIER
+		copy(arg.Key_spec.U[:16], added.identifier[:])
+		if _, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), unix.FS_IOC_REMOVE_ENCRYPTION_KEY, uintptr(unsafe.Pointer(&arg))); errno != 0 {
+			log.G(ctx).WithError(errno).Warnf("failed to remove fscrypt key of pod %q from kernel", podID)
+		}
+	}
+	return kmsErr
+}
+
+// openOnFilesystem opens the given path, walking up to the nearest existing
+// parent when it no longer exists, to obtain a descriptor on its filesystem.
+func openOnFilesystem(path string) (*os.File, error) {
+	for {
+		f, err := os.Open(path)
+		if err == nil {
+			return f, nil
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			return nil, err
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rwlayer
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// KeyProvider supplies and destroys per-pod encryption keys.
+type KeyProvider interface {
+	// Key returns the raw key material of a pod. Repeated calls for the same
+	// pod must return the same key until it is destroyed.
+	Key(ctx context.Context, podID string) ([]byte, error)
+	// Destroy irreversibly destroys the key material of a pod.
+	Destroy(ctx context.Context, podID string) error
+}
+
+// commandKeyProvider delegates key management to an external KMS helper
+// binary, invoked as `<cmd> get <podID>` (raw key on stdout) and
+// `<cmd> destroy <podID>`.
+type commandKeyProvider struct {
+	cmd string
+}
+
+// NewCommandKeyProvider returns a KeyProvider backed by the given KMS helper
+// binary.
+func NewCommandKeyProvider(cmd string) KeyProvider {
+	return &commandKeyProvider{cmd: cmd}
+}
+
+func (c *commandKeyProvider) Key(ctx context.Context, podID string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, c.cmd, "get", podID).Output()
+	if err != nil {
+		return nil, fmt.Errorf("kms helper %q failed to provide key for pod %q: %w", c.cmd, podID, err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("kms helper %q returned an empty key for pod %q", c.cmd, podID)
+	}
+	return out, nil
+}
+
+func (c *commandKeyProvider) Destroy(ctx context.Context, podID string) error {
+	if err := exec.CommandContext(ctx, c.cmd, "destroy", podID).Run(); err != nil {
+		return fmt.Errorf("kms helper %q failed to destroy key of pod %q: %w", c.cmd, podID, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package rwlayer defines drivers which prepare the writable layer of an
+// active snapshot. The only variant currently implemented encrypts the layer
+// at rest with fscrypt using per-pod keys from a configurable KMS helper, so
+// node-local writable data of sensitive workloads becomes unreadable once the
+// pod's key is destroyed at pod removal.
+package rwlayer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/containerd/containerd/v2/client/extensions"
+)
+
+// PodLabel is the snapshot label carrying the pod sandbox ID a writable layer
+// belongs to. Snapshotters only apply a rw-layer driver to snapshots bearing
+// this label.
+const PodLabel = extensions.LabelRWLayerPod
+
+// Driver prepares the writable layers of active snapshots.
+type Driver interface {
+	// Setup prepares the still empty writable layer directory of an active
+	// snapshot belonging to the given pod before it is first mounted.
+	Setup(ctx context.Context, dir, podID string) error
+	// DestroyPodKey destroys the key material of a pod, rendering any
+	// remaining writable-layer data of that pod unreadable. It is called at
+	// pod removal, after the pod's containers have been removed.
+	DestroyPodKey(ctx context.Context, podID string) error
+}
+
+var (
+	driversMu sync.Mutex
+	drivers   []Driver
+)
+
+// NewDriver constructs and tracks the named rw-layer driver variant. The
+// returned driver is also reachable through DestroyPodKeys so pod lifecycle
+// handlers can destroy keys without a reference to the snapshotter.
+func NewDriver(name string, keys KeyProvider) (Driver, error) {
+	var (
+		d   Driver
+		err error
+	)
+	switch name {
+	case "fscrypt":
+		d, err = newFscryptDriver(keys)
+	default:
+		return nil, fmt.Errorf("unknown rw-layer driver %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	driversMu.Lock()
+	drivers = append(drivers, d)
+	driversMu.Unlock()
+	return d, nil
+}
+
+// DestroyPodKeys destroys the key material of a pod in every constructed
+// rw-layer driver. It returns the first error encountered but still visits
+// all drivers.
+func DestroyPodKeys(ctx context.Context, podID string) error {
+	driversMu.Lock()
+	ds := append([]Driver(nil), drivers...)
+	driversMu.Unlock()
+	var first error
+	for _, d := range ds {
+		if err := d.DestroyPodKey(ctx, podID); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,116 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package coredump manages per-pod core dump directories. Core dumps are
+// redirected into these directories by the containerd-coredump-helper binary
+// configured as the kernel core_pattern pipe handler. Dumps are grouped by
+// pod and prefixed with the container ID so they stay attributable, and they
+// are removed together with their container or pod so node disks are not
+// filled by leftover dumps.
+package coredump
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnsureDir creates (if necessary) and returns the core dump directory of a
+// pod below the configured base directory.
+func EnsureDir(base, podID string) (string, error) {
+	dir := filepath.Join(base, podID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create core dump directory: %w", err)
+	}
+	return dir, nil
+}
+
+// CleanupContainer removes the dumps of one container from its pod's core
+// dump directory.
+func CleanupContainer(base, podID, containerID string) error {
+	dumps, err := filepath.Glob(filepath.Join(base, podID, containerID+"-*"))
+	if err != nil {
+		return err
+	}
+	for _, d := range dumps {
+		if err := os.Remove(d); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanupPod removes the core dump directory of a pod.
+func CleanupPod(base, podID string) error {
+	if err := os.RemoveAll(filepath.Join(base, podID)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DumpPath returns the file a core dump of the given container process should
+// be written to.
+func DumpPath(base, podID, containerID string, pid int) string {
+	return filepath.Join(base, podID, fmt.Sprintf("%s-%d.core", containerID, pid))
+}
+
+// WriteCapped copies at most max bytes from r to path. When max is exceeded
+// the dump is truncated rather than rejected so at least the beginning of the
+// image remains available. A non-positive max disables the cap.
+func WriteCapped(r io.Reader, path string, max int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if max > 0 {
+		r = io.LimitReader(r, max)
+	}
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// ResolveIDs resolves the pod sandbox and container IDs of a dumping process
+// from its cgroup path. It understands the cgroupfs and systemd cgroup
+// layouts used by the CRI plugin.
+func ResolveIDs(pid int) (podID string, containerID string, err error) {
+	data, err := os.ReadFile(filepath.Join("/proc", fmt.Sprint(pid), "cgroup"))
+	if err != nil {
+		return "", "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// Format: hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || parts[2] == "" {
+			continue
+		}
+		base := filepath.Base(parts[2])
+		// systemd driver: cri-containerd-<id>.scope
+		base = strings.TrimSuffix(base, ".scope")
+		if i := strings.LastIndex(base, "-"); i >= 0 {
+			base = base[i+1:]
+		}
+		if base == "" || base == "/" {
+			continue
+		}
+		containerID = base
+		podID = filepath.Base(filepath.Dir(parts[2]))
+		return podID, containerID, nil
+	}
+	return "", "", fmt.Errorf("no cgroup path found for pid %d", pid)
+}
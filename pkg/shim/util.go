@@ -39,6 +39,11 @@ import (
 	"github.com/containerd/errdefs"
 )
 
+// EnvAnnotation is an OCI spec annotation which requests additional `KEY=VALUE`
+// environment variables for the shim process. Its value is a JSON-encoded list
+// of strings.
+const EnvAnnotation = "io.containerd.runtime.v2.shim-env"
+
 type CommandConfig struct {
 	Runtime      string
 	Address      string
@@ -46,6 +51,7 @@ type CommandConfig struct {
 	Path         string
 	SchedCore    bool
 	Args         []string
+	Env          []string
 	Opts         *types.Any
 }
 
@@ -78,6 +84,7 @@ func Command(ctx context.Context, config *CommandConfig) (*exec.Cmd, error) {
 	if config.SchedCore {
 		cmd.Env = append(cmd.Env, "SCHED_CORE=1")
 	}
+	cmd.Env = append(cmd.Env, config.Env...)
 	cmd.SysProcAttr = getSysProcAttr()
 	if config.Opts != nil {
 		d, err := proto.Marshal(config.Opts)
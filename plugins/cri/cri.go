@@ -25,6 +25,7 @@ import (
 	"github.com/containerd/plugin"
 	"github.com/containerd/plugin/registry"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	runtime "k8s.io/cri-api/pkg/apis/runtime/v1"
 
 	containerd "github.com/containerd/containerd/v2/client"
@@ -164,6 +165,7 @@ func initCRIService(ic *plugin.InitContext) (interface{}, error) {
 		ImageServiceServer:   is,
 		Closer:               s, // TODO: Where is close run?
 		initializer:          s,
+		health:               &healthService{checker: s},
 	}
 
 	if config.DisableTCPService {
@@ -186,12 +188,14 @@ type criGRPCServer struct {
 	runtime.ImageServiceServer
 	io.Closer
 	initializer
+	health *healthService
 }
 
 func (c *criGRPCServer) register(s *grpc.Server) error {
 	instrumented := instrument.NewService(c)
 	runtime.RegisterRuntimeServiceServer(s, instrumented)
 	runtime.RegisterImageServiceServer(s, instrumented)
+	grpc_health_v1.RegisterHealthServer(s, c.health)
 	return nil
 }
 
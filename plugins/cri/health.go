@@ -0,0 +1,95 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package cri
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthWatchInterval is how often watchers of the health service are
+// re-evaluated.
+const healthWatchInterval = time.Second
+
+// healthChecker reports the actual readiness of the CRI service, beyond the
+// socket existing.
+type healthChecker interface {
+	Healthy() error
+}
+
+// healthService serves the standard grpc.health.v1 Health service on the
+// CRI-served sockets, reflecting initialization, state recovery and the
+// health of critical syncers, so node agents and load balancers can gate on
+// real readiness.
+type healthService struct {
+	grpc_health_v1.UnimplementedHealthServer
+	checker healthChecker
+}
+
+func (h *healthService) status(service string) (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	switch service {
+	case "", "runtime.v1.RuntimeService", "runtime.v1.ImageService":
+	default:
+		return 0, status.Errorf(codes.NotFound, "unknown service %q", service)
+	}
+	if err := h.checker.Healthy(); err != nil {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, nil
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING, nil
+}
+
+func (h *healthService) Check(ctx context.Context, r *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s, err := h.status(r.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: s}, nil
+}
+
+func (h *healthService) Watch(r *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	last, err := h.status(r.GetService())
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: last}); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+		s, err := h.status(r.GetService())
+		if err != nil {
+			return err
+		}
+		if s == last {
+			continue
+		}
+		if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: s}); err != nil {
+			return err
+		}
+		last = s
+	}
+}
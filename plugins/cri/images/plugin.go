@@ -22,11 +22,13 @@ import (
 	"path/filepath"
 
 	containerd "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/core/events/exchange"
 	"github.com/containerd/containerd/v2/core/metadata"
 	"github.com/containerd/containerd/v2/core/snapshots"
 	criconfig "github.com/containerd/containerd/v2/internal/cri/config"
 	"github.com/containerd/containerd/v2/internal/cri/constants"
 	"github.com/containerd/containerd/v2/internal/cri/server/images"
+	"github.com/containerd/containerd/v2/internal/ioclass"
 	"github.com/containerd/containerd/v2/plugins"
 	"github.com/containerd/containerd/v2/plugins/services/warning"
 	"github.com/containerd/containerd/v2/version"
@@ -44,6 +46,7 @@ func init() {
 		ID:     "images",
 		Config: &config,
 		Requires: []plugin.Type{
+			plugins.EventPlugin,
 			plugins.LeasePlugin,
 			plugins.MetadataPlugin,
 			plugins.SandboxStorePlugin,
@@ -71,9 +74,21 @@ func init() {
 				}
 			}
 
+			if len(config.StorageIOClasses) > 0 {
+				if err := ioclass.Configure(config.StorageIOClasses); err != nil {
+					return nil, fmt.Errorf("invalid cri image config: %w", err)
+				}
+			}
+
+			ep, err := ic.GetByID(plugins.EventPlugin, "exchange")
+			if err != nil {
+				return nil, err
+			}
+
 			options := &images.CRIImageServiceOptions{
 				Content:          mdb.ContentStore(),
 				Images:           metadata.NewImageStore(mdb),
+				Publisher:        ep.(*exchange.Exchange),
 				RuntimePlatforms: map[string]images.ImagePlatform{},
 				Snapshotters:     map[string]snapshots.Snapshotter{},
 				ImageFSPaths:     map[string]string{},
@@ -85,6 +85,7 @@ func initCRIRuntime(ic *plugin.InitContext) (interface{}, error) {
 			warn.Emit(ctx, w)
 		}
 	}
+	exportSandboxAnnotationPrefixes(ic, pluginConfig)
 
 	// For backward compatibility, we have to keep the rootDir and stateDir the same as before.
 	containerdRootDir := filepath.Dir(ic.Properties[plugins.PropertyRootDir])
@@ -140,21 +141,33 @@ func (r *runtime) LoadOCISpec(filename string) (*oci.Spec, error) {
 func loadBaseOCISpecs(config *criconfig.Config) (map[string]*oci.Spec, error) {
 	specs := map[string]*oci.Spec{}
 	for _, cfg := range config.Runtimes {
-		if cfg.BaseRuntimeSpec == "" {
-			continue
+		files := make([]string, 0, len(cfg.BaseRuntimeSpecs)+1)
+		if cfg.BaseRuntimeSpec != "" {
+			files = append(files, cfg.BaseRuntimeSpec)
 		}
-
-		// Don't load same file twice
-		if _, ok := specs[cfg.BaseRuntimeSpec]; ok {
-			continue
+		for platform, file := range cfg.BaseRuntimeSpecs {
+			if file == "" {
+				continue
+			}
+			if _, err := platforms.Parse(platform); err != nil {
+				return nil, fmt.Errorf("invalid platform %q in base_runtime_specs: %w", platform, err)
+			}
+			files = append(files, file)
 		}
 
-		spec, err := loadOCISpec(cfg.BaseRuntimeSpec)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load base OCI spec from file: %s: %w", cfg.BaseRuntimeSpec, err)
-		}
+		for _, file := range files {
+			// Don't load same file twice
+			if _, ok := specs[file]; ok {
+				continue
+			}
+
+			spec, err := loadOCISpec(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load base OCI spec from file: %s: %w", file, err)
+			}
 
-		specs[cfg.BaseRuntimeSpec] = spec
+			specs[file] = spec
+		}
 	}
 
 	return specs, nil
@@ -175,6 +188,24 @@ func loadOCISpec(filename string) (*oci.Spec, error) {
 	return &spec, nil
 }
 
+// exportSandboxAnnotationPrefixes publishes the per-runtime annotation
+// forwarding as a plugin export so clients can discover via introspection
+// which pod annotations a runtime handler passes through to its shim.
+func exportSandboxAnnotationPrefixes(ic *plugin.InitContext, config *criconfig.RuntimeConfig) {
+	prefixes := make(map[string][]string)
+	for name, r := range config.ContainerdConfig.Runtimes {
+		if len(r.SandboxAnnotationPrefixes) > 0 {
+			prefixes[name] = r.SandboxAnnotationPrefixes
+		}
+	}
+	if len(prefixes) == 0 {
+		return
+	}
+	if data, err := json.Marshal(prefixes); err == nil {
+		ic.Meta.Exports["SandboxAnnotationPrefixes"] = string(data)
+	}
+}
+
 // Set glog level.
 func setGLogLevel() error {
 	l := log.GetLevel()
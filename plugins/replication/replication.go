@@ -0,0 +1,231 @@
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package replication provides an optional warm standby for containerd's
+// metadata. A background replicator periodically writes a consistent
+// snapshot of the main metadata database and crash-consistent copies of the
+// auxiliary databases under the containerd root (snapshotter metadata, CRI
+// stores) to a target directory, typically on a separate disk or a remote
+// mount. After a disk replacement the databases can be copied back with
+// Restore instead of re-pulling all images and state.
+package replication
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/metadata"
+	"github.com/containerd/containerd/v2/internal/tomlext"
+	"github.com/containerd/containerd/v2/plugins"
+	"github.com/containerd/log"
+	"github.com/containerd/plugin"
+	"github.com/containerd/plugin/registry"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Config configures the metadata replicator.
+type Config struct {
+	// TargetDir is the directory the replicated databases are written to,
+	// typically on a separate disk or a remote mount. Empty disables
+	// replication.
+	TargetDir string `toml:"target_dir"`
+	// Interval is the duration between replication passes.
+	//
+	// Default is "1h"
+	Interval tomlext.Duration `toml:"interval"`
+}
+
+func init() {
+	registry.Register(&plugin.Registration{
+		Type: plugins.InternalPlugin,
+		ID:   "metadata-replicator",
+		Requires: []plugin.Type{
+			plugins.MetadataPlugin,
+		},
+		Config: &Config{
+			Interval: tomlext.FromStdTime(time.Hour),
+		},
+		InitFn: func(ic *plugin.InitContext) (interface{}, error) {
+			cfg := ic.Config.(*Config)
+			if cfg.TargetDir == "" {
+				return nil, fmt.Errorf("metadata replication disabled: %w", plugin.ErrSkipPlugin)
+			}
+			md, err := ic.GetSingle(plugins.MetadataPlugin)
+			if err != nil {
+				return nil, err
+			}
+			r := &replicator{
+				db: md.(*metadata.DB),
+				// The plugin root is a direct child of the containerd root,
+				// which holds the databases of the other plugins.
+				root:      filepath.Dir(ic.Properties[plugins.PropertyRootDir]),
+				targetDir: cfg.TargetDir,
+				interval:  tomlext.ToStdTime(cfg.Interval),
+			}
+			go r.run(ic.Context)
+			return r, nil
+		},
+	})
+}
+
+type replicator struct {
+	db        *metadata.DB
+	root      string
+	targetDir string
+	interval  time.Duration
+}
+
+func (r *replicator) run(ctx context.Context) {
+	tick := time.NewTicker(r.interval)
+	defer tick.Stop()
+	for {
+		if err := r.replicate(ctx); err != nil {
+			log.G(ctx).WithError(err).Warn("metadata replication pass failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// replicate performs one replication pass. The main metadata database is
+// streamed from a read transaction, so the copy is consistent even under
+// load; the auxiliary databases are copied as files, which bolt recovers
+// from like a crash.
+func (r *replicator) replicate(ctx context.Context) error {
+	start := time.Now()
+	if err := os.MkdirAll(r.targetDir, 0700); err != nil {
+		return err
+	}
+
+	mainDB := filepath.Join(r.root, plugins.MetadataPlugin.String()+".bolt", "meta.db")
+	if err := r.snapshotMainDB(filepath.Join(r.targetDir, plugins.MetadataPlugin.String()+".bolt", "meta.db")); err != nil {
+		return fmt.Errorf("failed to replicate metadata database: %w", err)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(r.root, "*", "*.db"))
+	if err != nil {
+		return err
+	}
+	for _, src := range matches {
+		if src == mainDB {
+			continue
+		}
+		rel, err := filepath.Rel(r.root, src)
+		if err != nil {
+			return err
+		}
+		if err := copyFileAtomic(filepath.Join(r.targetDir, rel), src); err != nil {
+			return fmt.Errorf("failed to replicate %q: %w", rel, err)
+		}
+	}
+	log.G(ctx).WithField("t", time.Since(start)).Debugf("replicated metadata to %q", r.targetDir)
+	return nil
+}
+
+// snapshotMainDB writes a consistent snapshot of the main metadata database
+// to dst through a read transaction.
+func (r *replicator) snapshotMainDB(dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = r.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(f)
+		return err
+	})
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// Restore copies the databases of a replication target directory back under
+// the containerd root after a node rebuild. The daemon must not be running.
+// Existing databases are only replaced when overwrite is set.
+func Restore(sourceDir, root string, overwrite bool) error {
+	matches, err := filepath.Glob(filepath.Join(sourceDir, "*", "*.db"))
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("no database snapshots found under %q", sourceDir)
+	}
+	if !overwrite {
+		for _, src := range matches {
+			rel, err := filepath.Rel(sourceDir, src)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(filepath.Join(root, rel)); err == nil {
+				return fmt.Errorf("%q already exists, use overwrite to replace it", filepath.Join(root, rel))
+			}
+		}
+	}
+	for _, src := range matches {
+		rel, err := filepath.Rel(sourceDir, src)
+		if err != nil {
+			return err
+		}
+		if err := copyFileAtomic(filepath.Join(root, rel), src); err != nil {
+			return fmt.Errorf("failed to restore %q: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// copyFileAtomic copies src over dst through a temporary file, so a crashed
+// or interrupted copy never leaves a truncated database behind.
+func copyFileAtomic(dst, src string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err1 := out.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
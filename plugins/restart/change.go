@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"strconv"
 	"syscall"
+	"time"
 
 	containerd "github.com/containerd/containerd/v2/client"
 	"github.com/containerd/containerd/v2/core/runtime/restart"
@@ -63,7 +64,8 @@ func (s *startChange) apply(ctx context.Context, client *containerd.Client) erro
 
 	if s.count > 0 {
 		labels := map[string]string{
-			restart.CountLabel: strconv.Itoa(s.count),
+			restart.CountLabel:       strconv.Itoa(s.count),
+			restart.LastRestartLabel: time.Now().UTC().Format(time.RFC3339Nano),
 		}
 		opt := containerd.WithAdditionalContainerLabels(labels)
 		if err := s.container.Update(ctx, containerd.UpdateContainerOpts(opt)); err != nil {
@@ -38,6 +38,12 @@ import (
 type Config struct {
 	// Interval for how long to wait to check for state changes
 	Interval tomlext.Duration `toml:"interval"`
+	// BackoffBase is the initial delay before restarting a container after an
+	// unexpected exit. The delay doubles with every subsequent restart attempt.
+	// Zero disables backoff.
+	BackoffBase tomlext.Duration `toml:"backoff_base"`
+	// BackoffMax caps the exponential restart backoff delay.
+	BackoffMax tomlext.Duration `toml:"backoff_max"`
 }
 
 func init() {
@@ -57,10 +63,15 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
+			config := ic.Config.(*Config)
 			m := &monitor{
-				client: client,
+				client:      client,
+				backoffBase: tomlext.ToStdTime(config.BackoffBase),
+				backoffMax:  tomlext.ToStdTime(config.BackoffMax),
+				trigger:     make(chan struct{}, 1),
 			}
-			go m.run(tomlext.ToStdTime(ic.Config.(*Config).Interval))
+			go m.run(tomlext.ToStdTime(config.Interval))
+			go m.watchExits(ic.Context)
 			return m, nil
 		},
 		ConfigMigration: func(ctx context.Context, configVersion int, pluginConfigs map[string]interface{}) error {
@@ -84,7 +95,10 @@ type change interface {
 }
 
 type monitor struct {
-	client *containerd.Client
+	client      *containerd.Client
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	trigger     chan struct{}
 }
 
 func (m *monitor) run(interval time.Duration) {
@@ -95,7 +109,32 @@ func (m *monitor) run(interval time.Duration) {
 		if err := m.reconcile(context.Background()); err != nil {
 			log.L.WithError(err).Error("reconcile")
 		}
-		time.Sleep(interval)
+		select {
+		case <-time.After(interval):
+		case <-m.trigger:
+		}
+	}
+}
+
+// watchExits triggers a reconcile as soon as a task exits so restarts do not
+// have to wait for the next polling interval. The polling loop remains the
+// source of truth; a lost subscription only delays restarts until the next
+// interval.
+func (m *monitor) watchExits(ctx context.Context) {
+	evts, errs := m.client.EventService().Subscribe(ctx, `topic=="/tasks/exit"`)
+	for {
+		select {
+		case <-evts:
+			select {
+			case m.trigger <- struct{}{}:
+			default:
+			}
+		case err := <-errs:
+			if err != nil {
+				log.L.WithError(err).Error("restart monitor event subscription failed")
+			}
+			return
+		}
 	}
 }
 
@@ -182,6 +221,13 @@ func (m *monitor) monitor(ctx context.Context) ([]change, error) {
 			}
 
 			restartCount, _ := strconv.Atoi(labels[restart.CountLabel])
+			if delay := restartDelay(m.backoffBase, m.backoffMax, restartCount); delay > 0 {
+				if last, err := time.Parse(time.RFC3339Nano, labels[restart.LastRestartLabel]); err == nil && time.Since(last) < delay {
+					// Still backing off; a later reconcile picks the
+					// container up again once the delay has elapsed.
+					continue
+				}
+			}
 			if labels["containerd.io/restart.logpath"] != "" {
 				log.G(ctx).Warn(`Label "containerd.io/restart.logpath" is no longer supported since containerd v2.0. Use "containerd.io/restart.loguri" instead.`)
 			}
@@ -198,3 +244,22 @@ func (m *monitor) monitor(ctx context.Context) ([]change, error) {
 	}
 	return changes, nil
 }
+
+// restartDelay returns the exponential backoff delay to apply before restart
+// attempt count+1. A non-positive base disables backoff.
+func restartDelay(base, max time.Duration, count int) time.Duration {
+	if base <= 0 || count <= 0 {
+		return 0
+	}
+	d := base
+	for i := 1; i < count; i++ {
+		d *= 2
+		if max > 0 && d >= max {
+			return max
+		}
+	}
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package overlay
+
+import (
+	"time"
+
+	metrics "github.com/docker/go-metrics"
+)
+
+var (
+	upperDirPoolFallbacks metrics.Counter
+
+	snapshotOperations     metrics.LabeledCounter
+	snapshotOperationTimer metrics.LabeledTimer
+	activeSnapshots        metrics.Gauge
+	committedSnapshots     metrics.Gauge
+	quotaSetupFailures     metrics.Counter
+)
+
+func init() {
+	ns := metrics.NewNamespace("containerd", "snapshotter", nil)
+
+	upperDirPoolFallbacks = ns.NewCounter("upperdir_pool_fallbacks", "cumulative number of writable layers placed outside of the preferred upper dir pool because it was full")
+
+	snapshotOperations = ns.NewLabeledCounter("operations", "success and failure counters of snapshot operations", "operation", "status")
+	snapshotOperationTimer = ns.NewLabeledTimer("operation_duration", "latency of snapshot operations", "operation")
+	activeSnapshots = ns.NewGauge("active_snapshots", "current number of active snapshots", metrics.Total)
+	committedSnapshots = ns.NewGauge("committed_snapshots", "current number of committed snapshots", metrics.Total)
+	quotaSetupFailures = ns.NewCounter("quota_setup_failures", "cumulative number of writable layer quota setups that failed")
+
+	metrics.Register(ns)
+}
+
+// observeSnapshotOp records the outcome and latency of one snapshot
+// operation.
+func observeSnapshotOp(op string, start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	snapshotOperations.WithValues(op, status).Inc()
+	snapshotOperationTimer.WithValues(op).UpdateSince(start)
+}
@@ -23,14 +23,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/containerd/containerd/v2/client/extensions"
 	"github.com/containerd/containerd/v2/core/mount"
 	"github.com/containerd/containerd/v2/core/snapshots"
 	"github.com/containerd/containerd/v2/core/snapshots/storage"
+	"github.com/containerd/containerd/v2/internal/ioclass"
+	"github.com/containerd/containerd/v2/internal/rwlayer"
 	"github.com/containerd/containerd/v2/plugins/snapshots/overlay/overlayutils"
+	"github.com/containerd/containerd/v2/plugins/snapshots/overlay/quota"
+	"github.com/containerd/containerd/v2/plugins/snapshots/overlay/rodriver"
 	"github.com/containerd/continuity/fs"
+	"github.com/containerd/errdefs"
 	"github.com/containerd/log"
 )
 
@@ -39,14 +48,42 @@ import (
 // the change set between this snapshot and its parent is stored.
 const upperdirKey = "containerd.io/snapshot/overlay.upperdir"
 
+// SnapshotterLabelOverlayActiveQuota is an optional label on active snapshots
+// giving the size limit in bytes to apply to the writable layer through the
+// configured quota driver.
+const SnapshotterLabelOverlayActiveQuota = extensions.LabelActiveQuota
+
+// Labels attached to Stat results of quota-constrained active snapshots,
+// reporting the configured writable layer limit and its current consumption
+// in bytes.
+const (
+	LabelQuotaLimit = "Quota-Limit"
+	LabelQuotaUsed  = "Quota-Used"
+)
+
+// LabelActivePath reports the directory holding the writable layer of an
+// active snapshot when upper dir pools are configured. Setting it through
+// Update migrates the layer to the named pool, or back under the snapshotter
+// root.
+const LabelActivePath = "containerd.io/snapshot/overlay.active-path"
+
 // SnapshotterConfig is used to configure the overlay snapshotter instance
 type SnapshotterConfig struct {
-	asyncRemove   bool
-	upperdirLabel bool
-	ms            MetaStore
-	mountOptions  []string
-	remapIDs      bool
-	slowChown     bool
+	asyncRemove        bool
+	upperdirLabel      bool
+	ms                 MetaStore
+	mountOptions       []string
+	remapIDs           bool
+	slowChown          bool
+	rwDriver           rwlayer.Driver
+	quota              quota.Quota
+	roDriverName       string
+	roDriverFallback   bool
+	upperDirPools      []string
+	noPoolFallback     bool
+	cleanupInterval    time.Duration
+	cleanupParallelism int
+	usageCacheTTL      time.Duration
 }
 
 // Opt is an option to configure the overlay snapshotter
@@ -104,14 +141,123 @@ func WithSlowChown(config *SnapshotterConfig) error {
 	return nil
 }
 
+// WithRWLayerDriver sets a driver which prepares the writable layer of active
+// snapshots labelled with a pod sandbox ID, e.g. to encrypt them at rest.
+func WithRWLayerDriver(d rwlayer.Driver) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.rwDriver = d
+		return nil
+	}
+}
+
+// WithQuota sets the driver enforcing size limits on the writable layer of
+// active snapshots labelled with SnapshotterLabelOverlayActiveQuota.
+func WithQuota(q quota.Quota) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.quota = q
+		return nil
+	}
+}
+
+// WithRoDriver selects the named read-only layer driver from the rodriver
+// registry. An empty name keeps the plain overlayfs behavior.
+func WithRoDriver(name string) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.roDriverName = name
+		return nil
+	}
+}
+
+// WithRoDriverFallback falls back to the "plain" overlayfs driver when the
+// selected ro driver is unavailable on the host (e.g. its binaries are
+// missing), instead of failing snapshotter initialization.
+func WithRoDriverFallback(config *SnapshotterConfig) error {
+	config.roDriverFallback = true
+	return nil
+}
+
+// WithQuotaDriver selects the named quota driver, e.g. "sparsefile".
+func WithQuotaDriver(name string) Opt {
+	return func(config *SnapshotterConfig) error {
+		q, err := quota.New(name)
+		if err != nil {
+			return err
+		}
+		config.quota = q
+		return nil
+	}
+}
+
+// WithUpperDirPools places the writable layers of active snapshots on the
+// given directory pools instead of the snapshotter root, in order of
+// preference. A pool without enough free space for the snapshot is skipped
+// for the next one, and when all pools are full placement falls back to the
+// snapshotter root unless disabled with WithoutUpperDirFallback.
+func WithUpperDirPools(pools ...string) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.upperDirPools = append(config.upperDirPools, pools...)
+		return nil
+	}
+}
+
+// WithBackgroundCleanup reclaims the directories of removed snapshots
+// periodically from a background worker, so with AsynchronousRemove enabled
+// they do not pile up until Cleanup is called externally. parallelism bounds
+// the number of directories removed concurrently per pass; values below one
+// remove them sequentially.
+func WithBackgroundCleanup(interval time.Duration, parallelism int) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.cleanupInterval = interval
+		config.cleanupParallelism = parallelism
+		return nil
+	}
+}
+
+// WithUsageCache caches the disk usage scans of active snapshots for the
+// given duration, so periodic stats collection does not walk every upper dir
+// on every pass. Usage may lag behind writes by up to the TTL.
+func WithUsageCache(ttl time.Duration) Opt {
+	return func(config *SnapshotterConfig) error {
+		config.usageCacheTTL = ttl
+		return nil
+	}
+}
+
+// WithoutUpperDirFallback fails snapshot creation when all upper dir pools
+// are full instead of falling back to the snapshotter root, for setups which
+// must keep writable layers off the root disk.
+func WithoutUpperDirFallback(config *SnapshotterConfig) error {
+	config.noPoolFallback = true
+	return nil
+}
+
 type snapshotter struct {
-	root          string
-	ms            MetaStore
-	asyncRemove   bool
-	upperdirLabel bool
-	options       []string
-	remapIDs      bool
-	slowChown     bool
+	root           string
+	ms             MetaStore
+	asyncRemove    bool
+	upperdirLabel  bool
+	options        []string
+	remapIDs       bool
+	slowChown      bool
+	rwDriver       rwlayer.Driver
+	quota          quota.Quota
+	roDriver       rodriver.Driver
+	upperDirPools  []string
+	noPoolFallback bool
+
+	cleanupParallelism int
+	cleanupStop        chan struct{}
+	cleanupStopOnce    sync.Once
+
+	usageCacheTTL time.Duration
+	usageCacheMu  sync.Mutex
+	usageCache    map[string]usageCacheEntry
+}
+
+// usageCacheEntry is a cached disk usage scan of an active snapshot.
+type usageCacheEntry struct {
+	usage snapshots.Usage
+	at    time.Time
 }
 
 // NewSnapshotter returns a Snapshotter which uses overlayfs. The overlayfs
@@ -161,15 +307,168 @@ func NewSnapshotter(root string, opts ...Opt) (snapshots.Snapshotter, error) {
 		config.mountOptions = append(config.mountOptions, "index=off")
 	}
 
-	return &snapshotter{
-		root:          root,
-		ms:            config.ms,
-		asyncRemove:   config.asyncRemove,
-		upperdirLabel: config.upperdirLabel,
-		options:       config.mountOptions,
-		remapIDs:      config.remapIDs,
-		slowChown:     config.slowChown,
-	}, nil
+	var roDriver rodriver.Driver
+	if config.roDriverName != "" {
+		roDriver, err = rodriver.New(config.roDriverName, root)
+		if err != nil {
+			if !config.roDriverFallback {
+				return nil, err
+			}
+			log.L.WithError(err).Warnf("ro driver %q unavailable, falling back to plain overlayfs", config.roDriverName)
+			roDriver, err = rodriver.New("plain", root)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	o := &snapshotter{
+		root:           root,
+		ms:             config.ms,
+		asyncRemove:    config.asyncRemove,
+		upperdirLabel:  config.upperdirLabel,
+		options:        config.mountOptions,
+		remapIDs:       config.remapIDs,
+		slowChown:      config.slowChown,
+		rwDriver:       config.rwDriver,
+		quota:          config.quota,
+		roDriver:       roDriver,
+		upperDirPools:  config.upperDirPools,
+		noPoolFallback: config.noPoolFallback,
+		usageCacheTTL:  config.usageCacheTTL,
+	}
+	if o.usageCacheTTL > 0 {
+		o.usageCache = make(map[string]usageCacheEntry)
+	}
+	o.seedSnapshotGauges()
+	o.recoverActiveSnapshots()
+	if config.cleanupInterval > 0 {
+		o.cleanupParallelism = config.cleanupParallelism
+		o.cleanupStop = make(chan struct{})
+		go o.backgroundCleanup(config.cleanupInterval)
+	}
+	return o, nil
+}
+
+// backgroundCleanup reclaims the directories of removed snapshots on a timer
+// until the snapshotter is closed.
+func (o *snapshotter) backgroundCleanup(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-o.cleanupStop:
+			return
+		case <-tick.C:
+		}
+		ctx := context.Background()
+		cleanup, err := o.cleanupDirectories(ctx)
+		if err != nil {
+			log.G(ctx).WithError(err).Warn("background snapshot cleanup failed")
+			continue
+		}
+		if len(cleanup) == 0 {
+			continue
+		}
+		log.G(ctx).Debugf("background snapshot cleanup reclaiming %d directories", len(cleanup))
+		o.removeSnapshotDirs(ctx, cleanup)
+	}
+}
+
+// removeSnapshotDirs removes the given snapshot directories with the
+// configured cleanup parallelism, each worker running at the background I/O
+// class so reclamation doesn't compete with workload I/O.
+func (o *snapshotter) removeSnapshotDirs(ctx context.Context, cleanup []string) {
+	workers := o.cleanupParallelism
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(cleanup) {
+		workers = len(cleanup)
+	}
+	dirs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ioclass.Run(ioclass.ActivityCleanup, func() {
+				for dir := range dirs {
+					o.removeSnapshotDir(ctx, dir)
+				}
+			})
+		}()
+	}
+	for _, dir := range cleanup {
+		dirs <- dir
+	}
+	close(dirs)
+	wg.Wait()
+}
+
+// seedSnapshotGauges initializes the active and committed snapshot count
+// gauges from the metadata store, so the counts survive daemon restarts.
+func (o *snapshotter) seedSnapshotGauges() {
+	var active, committed float64
+	if err := o.ms.WithTransaction(context.TODO(), false, func(ctx context.Context) error {
+		return storage.WalkInfo(ctx, func(ctx context.Context, info snapshots.Info) error {
+			switch info.Kind {
+			case snapshots.KindActive:
+				active++
+			case snapshots.KindCommitted:
+				committed++
+			}
+			return nil
+		})
+	}); err != nil {
+		log.L.WithError(err).Warn("failed to count existing snapshots for metrics")
+		return
+	}
+	activeSnapshots.Set(active)
+	committedSnapshots.Set(committed)
+}
+
+// recoverActiveSnapshots re-establishes the ro driver state serving active
+// snapshots after a daemon restart, so running containers don't hit EIO on
+// lower mounts whose backing devices died with the previous daemon. It runs
+// before the snapshotter serves requests; failures are logged per snapshot
+// rather than failing initialization, since the remaining snapshots are
+// unaffected.
+func (o *snapshotter) recoverActiveSnapshots() {
+	r, ok := o.roDriver.(rodriver.Recoverer)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	type active struct {
+		dir     string
+		parents []string
+	}
+	var actives []active
+	if err := o.ms.WithTransaction(ctx, false, func(ctx context.Context) error {
+		return storage.WalkInfo(ctx, func(ctx context.Context, info snapshots.Info) error {
+			if info.Kind != snapshots.KindActive {
+				return nil
+			}
+			s, err := storage.GetSnapshot(ctx, info.Name)
+			if err != nil {
+				return err
+			}
+			actives = append(actives, active{
+				dir:     filepath.Join(o.root, "snapshots", s.ID),
+				parents: o.parentPaths(s.ParentIDs),
+			})
+			return nil
+		})
+	}); err != nil {
+		log.L.WithError(err).Warn("failed to list active snapshots for recovery")
+		return
+	}
+	for _, a := range actives {
+		if err := r.Recover(ctx, a.dir, a.parents); err != nil {
+			log.L.WithError(err).WithField("path", a.dir).Warn("failed to recover ro driver state for active snapshot")
+		}
+	}
 }
 
 func hasOption(options []string, key string, hasValue bool) bool {
@@ -199,37 +498,221 @@ func (o *snapshotter) Stat(ctx context.Context, key string) (info snapshots.Info
 		return info, err
 	}
 
+	o.addLocationLabels(id, &info)
+	return info, nil
+}
+
+// addLocationLabels attaches the optional labels locating the on-disk state
+// of a snapshot: the upperdir label when enabled, and the generic writable
+// layer path consumed by quota handlers when a quota driver is configured.
+func (o *snapshotter) addLocationLabels(id string, info *snapshots.Info) {
 	if o.upperdirLabel {
 		if info.Labels == nil {
 			info.Labels = make(map[string]string)
 		}
 		info.Labels[upperdirKey] = o.upperPath(id)
 	}
-	return info, nil
+	if len(o.upperDirPools) > 0 && info.Kind == snapshots.KindActive {
+		if loc, err := o.backingLocation(id); err == nil {
+			if info.Labels == nil {
+				info.Labels = make(map[string]string)
+			}
+			info.Labels[LabelActivePath] = loc
+		}
+	}
+	if o.quota != nil && info.Kind == snapshots.KindActive {
+		if info.Labels == nil {
+			info.Labels = make(map[string]string)
+		}
+		info.Labels[snapshots.LabelWritableLayerPath] = o.upperPath(id)
+		if limit, ok := info.Labels[SnapshotterLabelOverlayActiveQuota]; ok {
+			info.Labels[LabelQuotaLimit] = limit
+			if u, err := o.quota.Get(context.TODO(), o.upperPath(id)); err == nil {
+				info.Labels[LabelQuotaUsed] = strconv.FormatInt(u.Size, 10)
+			}
+		}
+	}
 }
 
 func (o *snapshotter) Update(ctx context.Context, info snapshots.Info, fieldpaths ...string) (newInfo snapshots.Info, err error) {
 	err = o.ms.WithTransaction(ctx, true, func(ctx context.Context) error {
+		var oldQuota string
+		if o.quota != nil {
+			if _, old, _, err := storage.GetInfo(ctx, info.Name); err == nil {
+				oldQuota = old.Labels[SnapshotterLabelOverlayActiveQuota]
+			}
+		}
+
 		newInfo, err = storage.UpdateInfo(ctx, info, fieldpaths...)
 		if err != nil {
 			return err
 		}
 
-		if o.upperdirLabel {
+		if o.upperdirLabel || o.quota != nil || len(o.upperDirPools) > 0 {
 			id, _, _, err := storage.GetInfo(ctx, newInfo.Name)
 			if err != nil {
 				return err
 			}
-			if newInfo.Labels == nil {
-				newInfo.Labels = make(map[string]string)
+			// Resize the writable layer online when the quota label of an
+			// active snapshot changed.
+			if o.quota != nil && newInfo.Kind == snapshots.KindActive {
+				if v := newInfo.Labels[SnapshotterLabelOverlayActiveQuota]; v != "" && v != oldQuota {
+					size, err := strconv.ParseInt(v, 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid %s label %q: %w", SnapshotterLabelOverlayActiveQuota, v, err)
+					}
+					if err := o.quota.Resize(ctx, o.upperPath(id), size); err != nil {
+						return fmt.Errorf("failed to resize writable layer quota: %w", err)
+					}
+				}
 			}
-			newInfo.Labels[upperdirKey] = o.upperPath(id)
+			// Migrate the writable layer when the active path label names a
+			// different location than the one currently holding it.
+			if len(o.upperDirPools) > 0 && newInfo.Kind == snapshots.KindActive {
+				if v := newInfo.Labels[LabelActivePath]; v != "" {
+					if err := o.migrate(ctx, id, newInfo, v); err != nil {
+						return fmt.Errorf("failed to migrate writable layer: %w", err)
+					}
+				}
+			}
+			o.addLocationLabels(id, &newInfo)
 		}
 		return nil
 	})
 	return newInfo, err
 }
 
+// Migrate moves the writable layer of the active snapshot key to
+// newLocation, either a configured upper dir pool or the snapshotter root.
+// The copy happens under the metadata lock, so no snapshot operations run
+// concurrently; callers should pause I/O on the layer for the duration, and
+// a running overlay mount keeps writing to the previously resolved location
+// until it is remounted.
+func (o *snapshotter) Migrate(ctx context.Context, key, newLocation string) error {
+	return o.ms.WithTransaction(ctx, true, func(ctx context.Context) error {
+		id, info, _, err := storage.GetInfo(ctx, key)
+		if err != nil {
+			return err
+		}
+		return o.migrate(ctx, id, info, newLocation)
+	})
+}
+
+// migrate copies the snapshot directory of id to dest and atomically
+// re-points the snapshot link, leaving the path under the snapshotter root
+// valid throughout. Callers hold a write transaction.
+func (o *snapshotter) migrate(ctx context.Context, id string, info snapshots.Info, dest string) error {
+	if info.Kind != snapshots.KindActive {
+		return fmt.Errorf("only active snapshots can be migrated: %w", errdefs.ErrFailedPrecondition)
+	}
+	if o.quota != nil {
+		if _, ok := info.Labels[SnapshotterLabelOverlayActiveQuota]; ok {
+			return fmt.Errorf("migrating quota-constrained writable layers is not supported: %w", errdefs.ErrNotImplemented)
+		}
+	}
+	dest = filepath.Clean(dest)
+	snapshotDir := filepath.Join(o.root, "snapshots")
+	if dest != snapshotDir {
+		known := false
+		for _, pool := range o.upperDirPools {
+			if dest == filepath.Clean(pool) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%q is neither the snapshotter root nor a configured upper dir pool: %w", dest, errdefs.ErrInvalidArgument)
+		}
+	}
+	current, err := o.backingLocation(id)
+	if err != nil {
+		return err
+	}
+	if current == dest {
+		return nil
+	}
+
+	var (
+		link = filepath.Join(snapshotDir, id)
+		old  = filepath.Join(current, id)
+	)
+	// Stage the copy next to its final location so taking it over is a
+	// rename on the same filesystem.
+	staging, err := os.MkdirTemp(dest, "migrating-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if err1 := os.RemoveAll(staging); err1 != nil {
+				log.G(ctx).WithError(err1).WithField("path", staging).Warn("failed to remove staging directory")
+			}
+		}
+	}()
+	if err = fs.CopyDir(staging, old); err != nil {
+		return fmt.Errorf("failed to copy writable layer to %s: %w", dest, err)
+	}
+
+	if dest == snapshotDir {
+		// Moving back under the root: drop the symlink and take its place.
+		if err = os.Remove(link); err != nil {
+			return err
+		}
+		if err = os.Rename(staging, link); err != nil {
+			return err
+		}
+	} else {
+		backing := filepath.Join(dest, id)
+		if err = os.RemoveAll(backing); err != nil {
+			return err
+		}
+		if err = os.Rename(staging, backing); err != nil {
+			return err
+		}
+		tmp := link + "-migrating"
+		if err = os.Symlink(backing, tmp); err != nil {
+			return err
+		}
+		if current == snapshotDir {
+			// The link path still is the real directory; move it aside so
+			// the symlink can take its place.
+			if err = os.Rename(link, link+"-old"); err != nil {
+				os.Remove(tmp)
+				return err
+			}
+			old = link + "-old"
+		}
+		if err = os.Rename(tmp, link); err != nil {
+			return err
+		}
+	}
+	staging = ""
+	if err := os.RemoveAll(old); err != nil {
+		log.G(ctx).WithError(err).WithField("path", old).Warn("failed to remove previous writable layer location")
+	}
+	log.G(ctx).Infof("migrated writable layer of snapshot %s from %s to %s", id, current, dest)
+	return nil
+}
+
+// backingLocation returns the directory holding the on-disk state of a
+// snapshot: an upper dir pool when the snapshot directory is a link into
+// one, the snapshots directory under the root otherwise.
+func (o *snapshotter) backingLocation(id string) (string, error) {
+	link := filepath.Join(o.root, "snapshots", id)
+	fi, err := os.Lstat(link)
+	if err != nil {
+		return "", err
+	}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		backing, err := os.Readlink(link)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Dir(backing), nil
+	}
+	return filepath.Dir(link), nil
+}
+
 // Usage returns the resources taken by the snapshot identified by key.
 //
 // For active snapshots, this will scan the usage of the overlay "diff" (aka
@@ -251,22 +734,77 @@ func (o *snapshotter) Usage(ctx context.Context, key string) (_ snapshots.Usage,
 
 	if info.Kind == snapshots.KindActive {
 		upperPath := o.upperPath(id)
+		if o.quota != nil {
+			if _, ok := info.Labels[SnapshotterLabelOverlayActiveQuota]; ok {
+				// The quota driver reports consumption of a constrained
+				// layer without scanning the directory.
+				if u, err := o.quota.Get(ctx, upperPath); err == nil {
+					return u, nil
+				}
+			}
+		}
+		if u, ok := o.cachedUsage(id); ok {
+			return u, nil
+		}
 		du, err := fs.DiskUsage(ctx, upperPath)
 		if err != nil {
 			// TODO(stevvooe): Consider not reporting an error in this case.
 			return snapshots.Usage{}, err
 		}
 		usage = snapshots.Usage(du)
+		o.storeUsage(id, usage)
 	}
 	return usage, nil
 }
 
+// cachedUsage returns the cached usage scan of an active snapshot when the
+// cache is enabled and the entry has not expired.
+func (o *snapshotter) cachedUsage(id string) (snapshots.Usage, bool) {
+	if o.usageCache == nil {
+		return snapshots.Usage{}, false
+	}
+	o.usageCacheMu.Lock()
+	defer o.usageCacheMu.Unlock()
+	e, ok := o.usageCache[id]
+	if !ok || time.Since(e.at) > o.usageCacheTTL {
+		return snapshots.Usage{}, false
+	}
+	return e.usage, true
+}
+
+func (o *snapshotter) storeUsage(id string, usage snapshots.Usage) {
+	if o.usageCache == nil {
+		return
+	}
+	o.usageCacheMu.Lock()
+	o.usageCache[id] = usageCacheEntry{usage: usage, at: time.Now()}
+	o.usageCacheMu.Unlock()
+}
+
+func (o *snapshotter) invalidateUsage(id string) {
+	if o.usageCache == nil {
+		return
+	}
+	o.usageCacheMu.Lock()
+	delete(o.usageCache, id)
+	o.usageCacheMu.Unlock()
+}
+
 func (o *snapshotter) Prepare(ctx context.Context, key, parent string, opts ...snapshots.Opt) ([]mount.Mount, error) {
-	return o.createSnapshot(ctx, snapshots.KindActive, key, parent, opts)
+	start := time.Now()
+	m, err := o.createSnapshot(ctx, snapshots.KindActive, key, parent, opts)
+	observeSnapshotOp("prepare", start, err)
+	if err == nil {
+		activeSnapshots.Inc()
+	}
+	return m, err
 }
 
 func (o *snapshotter) View(ctx context.Context, key, parent string, opts ...snapshots.Opt) ([]mount.Mount, error) {
-	return o.createSnapshot(ctx, snapshots.KindView, key, parent, opts)
+	start := time.Now()
+	m, err := o.createSnapshot(ctx, snapshots.KindView, key, parent, opts)
+	observeSnapshotOp("view", start, err)
+	return m, err
 }
 
 // Mounts returns the mounts for the transaction identified by key. Can be
@@ -274,6 +812,8 @@ func (o *snapshotter) View(ctx context.Context, key, parent string, opts ...snap
 //
 // This can be used to recover mounts after calling View or Prepare.
 func (o *snapshotter) Mounts(ctx context.Context, key string) (_ []mount.Mount, err error) {
+	start := time.Now()
+	defer func() { observeSnapshotOp("mounts", start, err) }()
 	var s storage.Snapshot
 	var info snapshots.Info
 	if err := o.ms.WithTransaction(ctx, false, func(ctx context.Context) error {
@@ -290,10 +830,18 @@ func (o *snapshotter) Mounts(ctx context.Context, key string) (_ []mount.Mount,
 	}); err != nil {
 		return nil, err
 	}
-	return o.mounts(s, info), nil
+	return o.driverMounts(ctx, s, info)
 }
 
-func (o *snapshotter) Commit(ctx context.Context, name, key string, opts ...snapshots.Opt) error {
+func (o *snapshotter) Commit(ctx context.Context, name, key string, opts ...snapshots.Opt) (err error) {
+	start := time.Now()
+	defer func() {
+		observeSnapshotOp("commit", start, err)
+		if err == nil {
+			activeSnapshots.Dec()
+			committedSnapshots.Inc()
+		}
+	}()
 	return o.ms.WithTransaction(ctx, true, func(ctx context.Context) error {
 		// grab the existing id
 		id, _, _, err := storage.GetInfo(ctx, key)
@@ -309,6 +857,14 @@ func (o *snapshotter) Commit(ctx context.Context, name, key string, opts ...snap
 		if _, err = storage.CommitActive(ctx, key, name, snapshots.Usage(usage), opts...); err != nil {
 			return fmt.Errorf("failed to commit snapshot %s: %w", key, err)
 		}
+		// The usage committed above is final, drop the cached scan.
+		o.invalidateUsage(id)
+
+		if o.roDriver != nil {
+			if err := o.roDriver.Commit(ctx, filepath.Join(o.root, "snapshots", id)); err != nil {
+				return fmt.Errorf("ro driver failed to commit snapshot %s: %w", key, err)
+			}
+		}
 		return nil
 	})
 }
@@ -317,6 +873,19 @@ func (o *snapshotter) Commit(ctx context.Context, name, key string, opts ...snap
 // immediately become unavailable and unrecoverable. Disk space will
 // be freed up on the next call to `Cleanup`.
 func (o *snapshotter) Remove(ctx context.Context, key string) (err error) {
+	start := time.Now()
+	var kind snapshots.Kind
+	defer func() {
+		observeSnapshotOp("remove", start, err)
+		if err == nil {
+			switch kind {
+			case snapshots.KindActive:
+				activeSnapshots.Dec()
+			case snapshots.KindCommitted:
+				committedSnapshots.Dec()
+			}
+		}
+	}()
 	var removals []string
 	// Remove directories after the transaction is closed, failures must not
 	// return error since the transaction is committed with the removal
@@ -324,17 +893,17 @@ func (o *snapshotter) Remove(ctx context.Context, key string) (err error) {
 	defer func() {
 		if err == nil {
 			for _, dir := range removals {
-				if err := os.RemoveAll(dir); err != nil {
-					log.G(ctx).WithError(err).WithField("path", dir).Warn("failed to remove directory")
-				}
+				o.removeSnapshotDir(ctx, dir)
 			}
 		}
 	}()
 	return o.ms.WithTransaction(ctx, true, func(ctx context.Context) error {
-		_, _, err = storage.Remove(ctx, key)
+		var id string
+		id, kind, err = storage.Remove(ctx, key)
 		if err != nil {
 			return fmt.Errorf("failed to remove snapshot %s: %w", key, err)
 		}
+		o.invalidateUsage(id)
 
 		if !o.asyncRemove {
 			removals, err = o.getCleanupDirectories(ctx)
@@ -349,16 +918,13 @@ func (o *snapshotter) Remove(ctx context.Context, key string) (err error) {
 // Walk the snapshots.
 func (o *snapshotter) Walk(ctx context.Context, fn snapshots.WalkFunc, fs ...string) error {
 	return o.ms.WithTransaction(ctx, false, func(ctx context.Context) error {
-		if o.upperdirLabel {
+		if o.upperdirLabel || o.quota != nil {
 			return storage.WalkInfo(ctx, func(ctx context.Context, info snapshots.Info) error {
 				id, _, _, err := storage.GetInfo(ctx, info.Name)
 				if err != nil {
 					return err
 				}
-				if info.Labels == nil {
-					info.Labels = make(map[string]string)
-				}
-				info.Labels[upperdirKey] = o.upperPath(id)
+				o.addLocationLabels(id, &info)
 				return fn(ctx, info)
 			}, fs...)
 		}
@@ -368,20 +934,80 @@ func (o *snapshotter) Walk(ctx context.Context, fn snapshots.WalkFunc, fs ...str
 
 // Cleanup cleans up disk resources from removed or abandoned snapshots
 func (o *snapshotter) Cleanup(ctx context.Context) error {
+	return o.Reconcile(ctx)
+}
+
+// Reconcile repairs host state left behind by crashes. It removes snapshot
+// directories no metadata references and, when the ro driver keeps state
+// outside the snapshot directories, asks it to drop whatever references none
+// of the remaining ones. It is reachable remotely through the Cleanup RPC.
+func (o *snapshotter) Reconcile(ctx context.Context) error {
 	cleanup, err := o.cleanupDirectories(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, dir := range cleanup {
-		if err := os.RemoveAll(dir); err != nil {
-			log.G(ctx).WithError(err).WithField("path", dir).Warn("failed to remove directory")
+	// Removal of abandoned snapshot content is background work, run it at
+	// the configured background I/O class and cleanup parallelism.
+	o.removeSnapshotDirs(ctx, cleanup)
+
+	r, ok := o.roDriver.(rodriver.Reconciler)
+	if !ok {
+		return nil
+	}
+	var referenced map[string]struct{}
+	if err := o.ms.WithTransaction(ctx, false, func(ctx context.Context) error {
+		ids, err := storage.IDMap(ctx)
+		if err != nil {
+			return err
+		}
+		referenced = make(map[string]struct{}, len(ids))
+		for id := range ids {
+			referenced[filepath.Join(o.root, "snapshots", id)] = struct{}{}
 		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := r.Reconcile(ctx, referenced); err != nil {
+		return fmt.Errorf("failed to reconcile ro driver state: %w", err)
 	}
-
 	return nil
 }
 
+// removeSnapshotDir removes a snapshot directory, releasing its quota and
+// following the symbolic link to the backing directory when the writable
+// layer was placed on an upper dir pool.
+func (o *snapshotter) removeSnapshotDir(ctx context.Context, dir string) {
+	if o.roDriver != nil {
+		if err := o.roDriver.Cleanup(ctx, dir); err != nil {
+			log.G(ctx).WithError(err).WithField("path", dir).Warn("ro driver failed to clean up snapshot dir")
+		}
+	}
+	o.releaseQuota(ctx, dir)
+	if fi, err := os.Lstat(dir); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if backing, err := os.Readlink(dir); err == nil {
+			if err := os.RemoveAll(backing); err != nil {
+				log.G(ctx).WithError(err).WithField("path", backing).Warn("failed to remove backing directory")
+			}
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.G(ctx).WithError(err).WithField("path", dir).Warn("failed to remove directory")
+	}
+}
+
+// releaseQuota releases the writable layer quota of a snapshot directory
+// about to be removed, when a quota driver is configured.
+func (o *snapshotter) releaseQuota(ctx context.Context, dir string) {
+	if o.quota == nil {
+		return
+	}
+	if err := o.quota.Remove(ctx, filepath.Join(dir, "fs")); err != nil {
+		log.G(ctx).WithError(err).WithField("path", dir).Warn("failed to release writable layer quota")
+	}
+}
+
 func (o *snapshotter) cleanupDirectories(ctx context.Context) (_ []string, err error) {
 	var cleanupDirs []string
 	// Get a write transaction to ensure no other write transaction can be entered
@@ -484,7 +1110,14 @@ func (o *snapshotter) createSnapshot(ctx context.Context, kind snapshots.Kind, k
 
 	if err := o.ms.WithTransaction(ctx, true, func(ctx context.Context) (err error) {
 		snapshotDir := filepath.Join(o.root, "snapshots")
-		td, err = o.prepareDirectory(ctx, snapshotDir, kind)
+		prepareDir := snapshotDir
+		if kind == snapshots.KindActive && len(o.upperDirPools) > 0 {
+			prepareDir, err = o.selectUpperDirPool(ctx, requiredPoolSpace(opts))
+			if err != nil {
+				return err
+			}
+		}
+		td, err = o.prepareDirectory(ctx, prepareDir, kind)
 		if err != nil {
 			return fmt.Errorf("failed to create prepare snapshot dir: %w", err)
 		}
@@ -538,19 +1171,97 @@ func (o *snapshotter) createSnapshot(ctx context.Context, kind snapshots.Kind, k
 			}
 		}
 
+		if o.rwDriver != nil && kind == snapshots.KindActive {
+			if pod := info.Labels[rwlayer.PodLabel]; pod != "" {
+				if err := o.rwDriver.Setup(ctx, filepath.Join(td, "fs"), pod); err != nil {
+					return fmt.Errorf("failed to set up writable layer for pod %q: %w", pod, err)
+				}
+			}
+		}
+
+		if o.quota != nil && kind == snapshots.KindActive {
+			if v, ok := info.Labels[SnapshotterLabelOverlayActiveQuota]; ok {
+				size, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %s label %q: %w", SnapshotterLabelOverlayActiveQuota, v, err)
+				}
+				if err := o.quota.Setup(ctx, filepath.Join(td, "fs"), size); err != nil {
+					quotaSetupFailures.Inc()
+					return fmt.Errorf("failed to set up writable layer quota: %w", err)
+				}
+			}
+		}
+
 		path = filepath.Join(snapshotDir, s.ID)
-		if err = os.Rename(td, path); err != nil {
-			return fmt.Errorf("failed to rename: %w", err)
+		if prepareDir != snapshotDir {
+			backing := filepath.Join(prepareDir, s.ID)
+			if err = os.Rename(td, backing); err != nil {
+				return fmt.Errorf("failed to rename: %w", err)
+			}
+			td = ""
+			if err = os.Symlink(backing, path); err != nil {
+				if err1 := os.RemoveAll(backing); err1 != nil {
+					log.G(ctx).WithError(err1).WithField("path", backing).Warn("failed to remove backing directory")
+				}
+				return fmt.Errorf("failed to link snapshot dir to pool: %w", err)
+			}
+		} else {
+			if err = os.Rename(td, path); err != nil {
+				return fmt.Errorf("failed to rename: %w", err)
+			}
+			td = ""
+		}
+
+		if o.roDriver != nil {
+			if err := o.roDriver.PreProcess(ctx, path, o.parentPaths(s.ParentIDs), info.Labels); err != nil {
+				return fmt.Errorf("ro driver failed to preprocess snapshot: %w", err)
+			}
 		}
-		td = ""
 
 		return nil
 	}); err != nil {
 		return nil, err
 	}
+	return o.driverMounts(ctx, s, info)
+}
+
+// driverMounts consults the ro driver for the mounts serving a snapshot and
+// falls back to the plain overlayfs mounts when no driver is configured or
+// the driver passes on the snapshot.
+func (o *snapshotter) driverMounts(ctx context.Context, s storage.Snapshot, info snapshots.Info) ([]mount.Mount, error) {
+	if o.roDriver != nil {
+		var (
+			dir     = filepath.Join(o.root, "snapshots", s.ID)
+			parents = o.parentPaths(s.ParentIDs)
+
+			m   []mount.Mount
+			ok  bool
+			err error
+		)
+		if s.Kind == snapshots.KindActive {
+			m, ok, err = o.roDriver.ActiveMount(ctx, dir, parents)
+		} else {
+			m, ok, err = o.roDriver.PrepareMount(ctx, dir, parents)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ro driver failed to mount snapshot: %w", err)
+		}
+		if ok {
+			return m, nil
+		}
+	}
 	return o.mounts(s, info), nil
 }
 
+// parentPaths returns the upperdir paths of the given parent IDs.
+func (o *snapshotter) parentPaths(ids []string) []string {
+	paths := make([]string, 0, len(ids))
+	for _, id := range ids {
+		paths = append(paths, o.upperPath(id))
+	}
+	return paths
+}
+
 func (o *snapshotter) prepareDirectory(ctx context.Context, snapshotDir string, kind snapshots.Kind) (string, error) {
 	td, err := os.MkdirTemp(snapshotDir, "new-")
 	if err != nil {
@@ -570,6 +1281,57 @@ func (o *snapshotter) prepareDirectory(ctx context.Context, snapshotDir string,
 	return td, nil
 }
 
+// requiredPoolSpace returns the writable layer size a new snapshot asks for
+// through the quota label, used to skip pools without enough free space. Zero
+// when no quota is requested; validation of the label happens at quota setup.
+func requiredPoolSpace(opts []snapshots.Opt) int64 {
+	var base snapshots.Info
+	for _, opt := range opts {
+		if err := opt(&base); err != nil {
+			return 0
+		}
+	}
+	if v, ok := base.Labels[SnapshotterLabelOverlayActiveQuota]; ok {
+		if size, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return size
+		}
+	}
+	return 0
+}
+
+// selectUpperDirPool returns the first configured upper dir pool with more
+// than the required bytes free, falling back to the snapshotter root when all
+// pools are full unless fallback is disabled. Any placement outside of the
+// preferred pool is counted and logged.
+func (o *snapshotter) selectUpperDirPool(ctx context.Context, required int64) (string, error) {
+	for i, pool := range o.upperDirPools {
+		if err := os.MkdirAll(pool, 0700); err != nil {
+			log.G(ctx).WithError(err).Warnf("Skipping unusable upper dir pool %q", pool)
+			continue
+		}
+		var st syscall.Statfs_t
+		if err := syscall.Statfs(pool, &st); err != nil {
+			log.G(ctx).WithError(err).Warnf("Skipping unusable upper dir pool %q", pool)
+			continue
+		}
+		if free := int64(st.Bavail) * int64(st.Bsize); free <= required {
+			log.G(ctx).Warnf("Upper dir pool %q has %d bytes free, need more than %d", pool, free, required)
+			continue
+		}
+		if i > 0 {
+			upperDirPoolFallbacks.Inc()
+			log.G(ctx).Warnf("Placing writable layer on fallback upper dir pool %q", pool)
+		}
+		return pool, nil
+	}
+	if o.noPoolFallback {
+		return "", fmt.Errorf("all upper dir pools are full and fallback to the snapshotter root is disabled")
+	}
+	upperDirPoolFallbacks.Inc()
+	log.G(ctx).Warn("All upper dir pools are full, placing writable layer under the snapshotter root")
+	return filepath.Join(o.root, "snapshots"), nil
+}
+
 func (o *snapshotter) mounts(s storage.Snapshot, info snapshots.Info) []mount.Mount {
 	var options []string
 
@@ -645,6 +1407,11 @@ func (o *snapshotter) workPath(id string) string {
 
 // Close closes the snapshotter
 func (o *snapshotter) Close() error {
+	if o.cleanupStop != nil {
+		o.cleanupStopOnce.Do(func() {
+			close(o.cleanupStop)
+		})
+	}
 	return o.ms.Close()
 }
 
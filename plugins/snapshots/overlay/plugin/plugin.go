@@ -20,10 +20,15 @@ package overlay
 
 import (
 	"errors"
+	"strconv"
 
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/containerd/v2/internal/rwlayer"
+	"github.com/containerd/containerd/v2/internal/tomlext"
 	"github.com/containerd/containerd/v2/plugins"
 	"github.com/containerd/containerd/v2/plugins/snapshots/overlay"
 	"github.com/containerd/containerd/v2/plugins/snapshots/overlay/overlayutils"
+	"github.com/containerd/containerd/v2/plugins/snapshots/overlay/quota"
 	"github.com/containerd/platforms"
 	"github.com/containerd/plugin"
 	"github.com/containerd/plugin/registry"
@@ -32,6 +37,10 @@ import (
 const (
 	capaRemapIDs     = "remap-ids"
 	capaOnlyRemapIDs = "only-remap-ids"
+
+	// exportIDMappedMounts exposes whether the kernel supports idmapped
+	// mounts for overlayfs in the plugin's introspection exports.
+	exportIDMappedMounts = "idmapped_mounts"
 )
 
 // Config represents configuration for the overlay plugin.
@@ -44,10 +53,54 @@ type Config struct {
 	// slowChown allows the plugin to fallback to a recursive chown if fast options (like
 	// idmap mounts) are not available. See more info about the overhead this can have in
 	// github.com/containerd/containerd/docs/user-namespaces/.
+	// The fallback is enabled automatically when the kernel does not support
+	// idmapped mounts for overlayfs.
 	SlowChown bool `toml:"slow_chown"`
 
 	// MountOptions are options used for the overlay mount (not used on bind mounts)
 	MountOptions []string `toml:"mount_options"`
+
+	// RWLayerDriver names an optional driver preparing the writable layer of
+	// pod-labelled active snapshots, e.g. "fscrypt" to encrypt them at rest.
+	RWLayerDriver string `toml:"rw_layer_driver"`
+	// RWLayerKMSCommand is the KMS helper binary providing and destroying
+	// per-pod keys for the rw-layer driver.
+	RWLayerKMSCommand string `toml:"rw_layer_kms_command"`
+
+	// RoDriver names an optional driver serving the read-only lower layers
+	// of snapshots, selected from the rodriver registry. Empty keeps the
+	// plain overlayfs behavior.
+	RoDriver string `toml:"ro_driver"`
+	// RoDriverFallback falls back to the "plain" overlayfs driver when the
+	// configured ro driver is unavailable on the host instead of failing
+	// snapshotter initialization, so clean hosts stay usable.
+	RoDriverFallback bool `toml:"ro_driver_fallback"`
+
+	// QuotaDriver names an optional driver enforcing size limits on the
+	// writable layer of active snapshots labelled with a quota, e.g.
+	// "sparsefile".
+	QuotaDriver string `toml:"quota_driver"`
+
+	// CleanupInterval runs a background worker reclaiming the directories
+	// of removed snapshots on the given interval when async removal is
+	// enabled, e.g. "5m". Zero disables the worker.
+	CleanupInterval tomlext.Duration `toml:"cleanup_interval"`
+	// CleanupParallelism bounds how many directories a cleanup pass removes
+	// concurrently. Values below one remove them sequentially.
+	CleanupParallelism int `toml:"cleanup_parallelism"`
+
+	// UsageCacheTTL caches the disk usage scans of active snapshots for the
+	// given duration, e.g. "1m", so periodic stats collection does not walk
+	// every upper dir each pass. Zero disables the cache.
+	UsageCacheTTL tomlext.Duration `toml:"usage_cache_ttl"`
+
+	// UpperDirPools are directories, in order of preference, to place the
+	// writable layers of active snapshots in instead of the snapshotter root,
+	// typically on a dedicated disk. Full pools are skipped for the next one.
+	UpperDirPools []string `toml:"upper_dir_pools"`
+	// DisableUpperDirFallback fails snapshot creation when all upper dir
+	// pools are full instead of falling back to the snapshotter root.
+	DisableUpperDirFallback bool `toml:"disable_upper_dir_fallback"`
 }
 
 func init() {
@@ -79,12 +132,55 @@ func init() {
 			if len(config.MountOptions) > 0 {
 				oOpts = append(oOpts, overlay.WithMountOptions(config.MountOptions))
 			}
-			if ok, err := overlayutils.SupportsIDMappedMounts(); err == nil && ok {
+			idmapped, err := overlayutils.SupportsIDMappedMounts()
+			idmapped = idmapped && err == nil
+			if idmapped {
 				oOpts = append(oOpts, overlay.WithRemapIDs)
 				ic.Meta.Capabilities = append(ic.Meta.Capabilities, capaRemapIDs)
 			}
 
-			if config.SlowChown {
+			if config.RWLayerDriver != "" {
+				d, err := rwlayer.NewDriver(config.RWLayerDriver, rwlayer.NewCommandKeyProvider(config.RWLayerKMSCommand))
+				if err != nil {
+					return nil, err
+				}
+				oOpts = append(oOpts, overlay.WithRWLayerDriver(d))
+			}
+
+			if config.RoDriver != "" {
+				oOpts = append(oOpts, overlay.WithRoDriver(config.RoDriver))
+				if config.RoDriverFallback {
+					oOpts = append(oOpts, overlay.WithRoDriverFallback)
+				}
+			}
+
+			if config.QuotaDriver != "" {
+				q, err := quota.New(config.QuotaDriver)
+				if err != nil {
+					return nil, err
+				}
+				oOpts = append(oOpts, overlay.WithQuota(q))
+				snapshots.RegisterWritableLayerQuota("overlayfs", quota.AsWritableLayerQuota(q))
+			}
+
+			if interval := tomlext.ToStdTime(config.CleanupInterval); interval > 0 {
+				oOpts = append(oOpts, overlay.WithBackgroundCleanup(interval, config.CleanupParallelism))
+			}
+
+			if ttl := tomlext.ToStdTime(config.UsageCacheTTL); ttl > 0 {
+				oOpts = append(oOpts, overlay.WithUsageCache(ttl))
+			}
+
+			if len(config.UpperDirPools) > 0 {
+				oOpts = append(oOpts, overlay.WithUpperDirPools(config.UpperDirPools...))
+				if config.DisableUpperDirFallback {
+					oOpts = append(oOpts, overlay.WithoutUpperDirFallback)
+				}
+			}
+
+			if config.SlowChown || !idmapped {
+				// Fall back to a recursive chown when the kernel cannot idmap
+				// overlay mounts, so userns pods keep working, just slower.
 				oOpts = append(oOpts, overlay.WithSlowChown)
 			} else {
 				// If slowChown is false, we use capaOnlyRemapIDs to signal we only
@@ -92,6 +188,7 @@ func init() {
 				ic.Meta.Capabilities = append(ic.Meta.Capabilities, capaOnlyRemapIDs)
 			}
 
+			ic.Meta.Exports[exportIDMappedMounts] = strconv.FormatBool(idmapped)
 			ic.Meta.Exports[plugins.SnapshotterRootDir] = root
 			return overlay.NewSnapshotter(root, oOpts...)
 		},
@@ -0,0 +1,244 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/core/snapshots"
+)
+
+// Constants of the kernel project quota interface, shared by XFS and ext4
+// through the generic quotactl layer.
+const (
+	// FS_IOC_FSGETXATTR / FS_IOC_FSSETXATTR read and write the fsxattr of
+	// an inode, which carries its project ID and inheritance flag.
+	fsIocFsGetXattr = 0x801c581f
+	fsIocFsSetXattr = 0x401c5820
+	// FS_XFLAG_PROJINHERIT makes new children inherit the project ID.
+	fsXflagProjinherit = 0x00000200
+
+	// Q_GETQUOTA / Q_SETQUOTA quotactl subcommands for PRJQUOTA (type 2),
+	// already shifted per QCMD().
+	qGetPrjQuota = 0x80000700 | 2
+	qSetPrjQuota = 0x80000800 | 2
+
+	// qifBLimits marks the block limit fields of a dqblk as valid.
+	qifBLimits = 1
+	// qifDQBlkSize is the unit of the dqblk block limit fields.
+	qifDQBlkSize = 1024
+)
+
+// fsxattr mirrors struct fsxattr of the FS_IOC_FSGETXATTR ioctl.
+type fsxattr struct {
+	xflags     uint32
+	extsize    uint32
+	nextents   uint32
+	projid     uint32
+	cowextsize uint32
+	pad        [8]byte
+}
+
+// dqblk mirrors struct if_dqblk of the generic quotactl interface.
+type dqblk struct {
+	bHardLimit uint64
+	bSoftLimit uint64
+	curSpace   uint64
+	iHardLimit uint64
+	iSoftLimit uint64
+	curInodes  uint64
+	bTime      uint64
+	iTime      uint64
+	valid      uint32
+}
+
+// projectQuota enforces writable layer limits with XFS/ext4 project quotas
+// directly on the backing filesystem, avoiding the loop mount overhead and
+// mkfs latency of the sparsefile driver. The filesystem must be mounted with
+// project quota accounting enabled (prjquota mount option).
+type projectQuota struct{}
+
+func newProjectQuota() *projectQuota {
+	return &projectQuota{}
+}
+
+func (p *projectQuota) Setup(ctx context.Context, dir string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid quota size %d", size)
+	}
+	projID, err := assignProjectID(dir)
+	if err != nil {
+		return err
+	}
+	dev, err := backingDevice(dir)
+	if err != nil {
+		return err
+	}
+	d := dqblk{
+		bHardLimit: uint64(size) / qifDQBlkSize,
+		bSoftLimit: uint64(size) / qifDQBlkSize,
+		valid:      qifBLimits,
+	}
+	if err := quotactl(qSetPrjQuota, dev, projID, &d); err != nil {
+		return fmt.Errorf("failed to set project quota %d on %s (is it mounted with prjquota?): %w", projID, dev, err)
+	}
+	return nil
+}
+
+func (p *projectQuota) Get(ctx context.Context, dir string) (snapshots.Usage, error) {
+	projID, err := getProjectID(dir)
+	if err != nil {
+		return snapshots.Usage{}, err
+	}
+	dev, err := backingDevice(dir)
+	if err != nil {
+		return snapshots.Usage{}, err
+	}
+	var d dqblk
+	if err := quotactl(qGetPrjQuota, dev, projID, &d); err != nil {
+		return snapshots.Usage{}, fmt.Errorf("failed to get project quota %d on %s: %w", projID, dev, err)
+	}
+	return snapshots.Usage{
+		Size:   int64(d.curSpace),
+		Inodes: int64(d.curInodes),
+	}, nil
+}
+
+// Resize changes the project limits to the new size; the kernel applies it
+// immediately to the running project.
+func (p *projectQuota) Resize(ctx context.Context, dir string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid quota size %d", size)
+	}
+	projID, err := getProjectID(dir)
+	if err != nil {
+		return err
+	}
+	if projID == 0 {
+		return fmt.Errorf("%s has no project quota assigned", dir)
+	}
+	dev, err := backingDevice(dir)
+	if err != nil {
+		return err
+	}
+	d := dqblk{
+		bHardLimit: uint64(size) / qifDQBlkSize,
+		bSoftLimit: uint64(size) / qifDQBlkSize,
+		valid:      qifBLimits,
+	}
+	if err := quotactl(qSetPrjQuota, dev, projID, &d); err != nil {
+		return fmt.Errorf("failed to resize project quota %d on %s: %w", projID, dev, err)
+	}
+	return nil
+}
+
+func (p *projectQuota) Remove(ctx context.Context, dir string) error {
+	projID, err := getProjectID(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if projID == 0 {
+		// Not constrained by Setup, nothing to release.
+		return nil
+	}
+	dev, err := backingDevice(dir)
+	if err != nil {
+		return err
+	}
+	// Clear the limits so the quota record doesn't constrain a future
+	// project reusing the ID.
+	d := dqblk{valid: qifBLimits}
+	if err := quotactl(qSetPrjQuota, dev, projID, &d); err != nil {
+		return fmt.Errorf("failed to clear project quota %d on %s: %w", projID, dev, err)
+	}
+	return nil
+}
+
+// assignProjectID gives dir a project ID derived from its inode number and
+// marks it inheritable, so everything created below it is accounted to the
+// same project.
+func assignProjectID(dir string) (uint32, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var st syscall.Stat_t
+	if err := syscall.Fstat(int(f.Fd()), &st); err != nil {
+		return 0, err
+	}
+	projID := uint32(st.Ino)
+
+	var attr fsxattr
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocFsGetXattr, uintptr(unsafe.Pointer(&attr))); errno != 0 {
+		return 0, fmt.Errorf("failed to get fsxattr of %s: %w", dir, errno)
+	}
+	attr.projid = projID
+	attr.xflags |= fsXflagProjinherit
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocFsSetXattr, uintptr(unsafe.Pointer(&attr))); errno != 0 {
+		return 0, fmt.Errorf("failed to set project ID on %s: %w", dir, errno)
+	}
+	return projID, nil
+}
+
+// getProjectID reads the project ID assigned to dir.
+func getProjectID(dir string) (uint32, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var attr fsxattr
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocFsGetXattr, uintptr(unsafe.Pointer(&attr))); errno != 0 {
+		return 0, fmt.Errorf("failed to get fsxattr of %s: %w", dir, errno)
+	}
+	return attr.projid, nil
+}
+
+// backingDevice returns the block device of the filesystem containing dir.
+func backingDevice(dir string) (string, error) {
+	info, err := mount.Lookup(dir)
+	if err != nil {
+		return "", err
+	}
+	return info.Source, nil
+}
+
+// quotactl wraps the quotactl syscall for project quota commands.
+func quotactl(cmd int, dev string, projID uint32, d *dqblk) error {
+	devp, err := syscall.BytePtrFromString(dev)
+	if err != nil {
+		return err
+	}
+	if _, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, uintptr(cmd),
+		uintptr(unsafe.Pointer(devp)), uintptr(projID), uintptr(unsafe.Pointer(d)), 0, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/containerd/v2/core/mount"
+	"github.com/containerd/containerd/v2/core/snapshots"
+	"github.com/containerd/errdefs"
+)
+
+// quotaImageName is the name of the backing image file, stored next to the
+// constrained "fs" directory inside the snapshot directory.
+const quotaImageName = "quota.img"
+
+// sparseFileQuota backs the writable layer with an ext4 filesystem in a
+// sparse image file sized to the quota and loop-mounted over the layer
+// directory, so the kernel enforces the limit without requiring project
+// quota support from the backing filesystem.
+type sparseFileQuota struct{}
+
+func newSparseFileQuota() *sparseFileQuota {
+	return &sparseFileQuota{}
+}
+
+func (s *sparseFileQuota) Setup(ctx context.Context, dir string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid quota size %d", size)
+	}
+	st, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	image := filepath.Join(filepath.Dir(dir), quotaImageName)
+	f, err := os.OpenFile(image, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create quota image: %w", err)
+	}
+	err = f.Truncate(size)
+	f.Close()
+	if err != nil {
+		os.Remove(image)
+		return fmt.Errorf("failed to size quota image to %d bytes: %w", size, err)
+	}
+	if out, err := exec.CommandContext(ctx, "mkfs.ext4", "-F", "-q", image).CombinedOutput(); err != nil {
+		os.Remove(image)
+		return fmt.Errorf("mkfs.ext4 %s failed: %s: %w", image, out, err)
+	}
+	m := mount.Mount{
+		Type:    "ext4",
+		Source:  image,
+		Options: []string{"loop"},
+	}
+	if err := m.Mount(dir); err != nil {
+		os.Remove(image)
+		return fmt.Errorf("failed to mount quota image on %s: %w", dir, err)
+	}
+	// The fresh filesystem hides the prepared layer directory; restore its
+	// ownership and mode on the new root and drop the ext4 artifacts.
+	if err := os.RemoveAll(filepath.Join(dir, "lost+found")); err != nil {
+		return err
+	}
+	if stat, ok := st.Sys().(*syscall.Stat_t); ok {
+		if err := os.Lchown(dir, int(stat.Uid), int(stat.Gid)); err != nil {
+			return fmt.Errorf("failed to chown quota root: %w", err)
+		}
+	}
+	return os.Chmod(dir, st.Mode().Perm())
+}
+
+// Get reports the space and inodes consumed inside the loop filesystem
+// backing the constrained directory.
+func (s *sparseFileQuota) Get(ctx context.Context, dir string) (snapshots.Usage, error) {
+	image := filepath.Join(filepath.Dir(dir), quotaImageName)
+	if _, err := os.Stat(image); err != nil {
+		if os.IsNotExist(err) {
+			return snapshots.Usage{}, fmt.Errorf("%s has no quota: %w", dir, errdefs.ErrNotFound)
+		}
+		return snapshots.Usage{}, err
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return snapshots.Usage{}, fmt.Errorf("failed to statfs quota filesystem on %s: %w", dir, err)
+	}
+	return snapshots.Usage{
+		Size:   int64(st.Blocks-st.Bfree) * int64(st.Bsize),
+		Inodes: int64(st.Files - st.Ffree),
+	}, nil
+}
+
+// Resize grows the backing image to the new size and expands the mounted
+// loop filesystem into it online. Shrinking a mounted ext4 filesystem is not
+// supported by the kernel and fails in resize2fs.
+func (s *sparseFileQuota) Resize(ctx context.Context, dir string, size int64) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid quota size %d", size)
+	}
+	image := filepath.Join(filepath.Dir(dir), quotaImageName)
+	if _, err := os.Stat(image); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s has no quota: %w", dir, errdefs.ErrNotFound)
+		}
+		return err
+	}
+	dev, err := loopDevice(ctx, image)
+	if err != nil {
+		return err
+	}
+	if err := os.Truncate(image, size); err != nil {
+		return fmt.Errorf("failed to resize quota image to %d bytes: %w", size, err)
+	}
+	// Update the loop device capacity before growing the filesystem into it.
+	if out, err := exec.CommandContext(ctx, "losetup", "-c", dev).CombinedOutput(); err != nil {
+		return fmt.Errorf("losetup -c %s failed: %s: %w", dev, out, err)
+	}
+	if out, err := exec.CommandContext(ctx, "resize2fs", dev).CombinedOutput(); err != nil {
+		return fmt.Errorf("resize2fs %s failed: %s: %w", dev, out, err)
+	}
+	return nil
+}
+
+// loopDevice returns the loop device serving the given backing image.
+func loopDevice(ctx context.Context, image string) (string, error) {
+	out, err := exec.CommandContext(ctx, "losetup", "-j", image).Output()
+	if err != nil {
+		return "", fmt.Errorf("losetup -j %s failed: %w", image, err)
+	}
+	dev, _, ok := strings.Cut(strings.TrimSpace(string(out)), ":")
+	if !ok || dev == "" {
+		return "", fmt.Errorf("quota image %s is not attached to a loop device", image)
+	}
+	return dev, nil
+}
+
+func (s *sparseFileQuota) Remove(ctx context.Context, dir string) error {
+	image := filepath.Join(filepath.Dir(dir), quotaImageName)
+	if _, err := os.Stat(image); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := mount.UnmountAll(dir, 0); err != nil {
+		return fmt.Errorf("failed to unmount quota filesystem on %s: %w", dir, err)
+	}
+	return os.Remove(image)
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package quota provides drivers enforcing a disk quota on the writable
+// layer of active overlay snapshots.
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containerd/containerd/v2/core/snapshots"
+)
+
+// Quota enforces a size limit on the writable layer directory of an active
+// snapshot.
+type Quota interface {
+	// Setup constrains the still empty writable layer directory to size
+	// bytes before it is first mounted.
+	Setup(ctx context.Context, dir string, size int64) error
+	// Get reports the current usage of a constrained directory.
+	Get(ctx context.Context, dir string) (snapshots.Usage, error)
+	// Resize changes the limit of a constrained directory to size bytes
+	// while it may be in use.
+	Resize(ctx context.Context, dir string, size int64) error
+	// Remove releases the quota backing of a directory. It is called
+	// before the snapshot directory is deleted and is a no-op for
+	// directories without a quota.
+	Remove(ctx context.Context, dir string) error
+}
+
+// New constructs the named quota driver variant.
+func New(name string) (Quota, error) {
+	switch name {
+	case "sparsefile":
+		return newSparseFileQuota(), nil
+	case "prjquota":
+		return newProjectQuota(), nil
+	default:
+		return nil, fmt.Errorf("unknown quota driver %q", name)
+	}
+}
+
+// AsWritableLayerQuota adapts a quota driver to the generic
+// snapshots.WritableLayerQuota interface consumed by the CRI layer.
+func AsWritableLayerQuota(q Quota) snapshots.WritableLayerQuota {
+	return writableLayerQuota{q: q}
+}
+
+type writableLayerQuota struct {
+	q Quota
+}
+
+func (w writableLayerQuota) Setup(ctx context.Context, dir string, size int64) error {
+	return w.q.Setup(ctx, dir, size)
+}
+
+func (w writableLayerQuota) Resize(ctx context.Context, dir string, size int64) error {
+	return w.q.Resize(ctx, dir, size)
+}
+
+func (w writableLayerQuota) Usage(ctx context.Context, dir string) (snapshots.Usage, error) {
+	return w.q.Get(ctx, dir)
+}
+
+func (w writableLayerQuota) Remove(ctx context.Context, dir string) error {
+	return w.q.Remove(ctx, dir)
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package rodriver
+
+import (
+	"context"
+
+	"github.com/containerd/containerd/v2/core/mount"
+)
+
+func init() {
+	Register("plain", func(root string) (Driver, error) {
+		return plainDriver{}, nil
+	})
+}
+
+// plainDriver keeps the standard overlayfs semantics: it needs no
+// preprocessing, passes on all mounts so the snapshotter serves its plain
+// overlay mounts, and holds no state to finalize or clean up. It is the
+// fallback when a lazy-loading driver is configured but unavailable on the
+// host.
+type plainDriver struct{}
+
+func (plainDriver) PreProcess(ctx context.Context, dir string, parents []string, labels map[string]string) error {
+	return nil
+}
+
+func (plainDriver) ActiveMount(ctx context.Context, dir string, parents []string) ([]mount.Mount, bool, error) {
+	return nil, false, nil
+}
+
+func (plainDriver) PrepareMount(ctx context.Context, dir string, parents []string) ([]mount.Mount, bool, error) {
+	return nil, false, nil
+}
+
+func (plainDriver) Commit(ctx context.Context, dir string) error {
+	return nil
+}
+
+func (plainDriver) Cleanup(ctx context.Context, dir string) error {
+	return nil
+}
@@ -0,0 +1,316 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package procman supervises the backend processes ro drivers launch per
+// snapshot, such as block device servers. It records pids in a pidfile under
+// the snapshot directory so processes are terminated directly by pid rather
+// than discovered through ps pipelines, restarts crashed backends, and can
+// reattach to backends started by a previous daemon instance.
+package procman
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/containerd/log"
+)
+
+const (
+	// pidFileName is the name of the pid record inside the snapshot
+	// directory.
+	pidFileName = "backend.pid"
+
+	// defaultHealthInterval is how often a configured health check runs.
+	defaultHealthInterval = 30 * time.Second
+	// defaultStopTimeout is how long Stop waits after SIGTERM before
+	// escalating to SIGKILL.
+	defaultStopTimeout = 10 * time.Second
+	// restartBackoff delays restarting a crashed backend so a broken
+	// binary cannot spin the supervisor.
+	restartBackoff = time.Second
+)
+
+// Config describes a backend process to supervise.
+type Config struct {
+	// Binary is the backend executable, resolved through PATH when not
+	// absolute.
+	Binary string
+	// Args are the arguments passed to the backend, without the binary
+	// name.
+	Args []string
+	// HealthCheck probes a running backend, e.g. through its control
+	// socket. A non-nil error kills and restarts the backend. Nil disables
+	// probing; crashes are still detected by waiting on the process.
+	HealthCheck func(ctx context.Context) error
+	// HealthInterval is the probe period, defaulting to 30s.
+	HealthInterval time.Duration
+	// MaxRestarts bounds how often a crashed or unhealthy backend is
+	// relaunched before the supervisor gives up. Zero disables restarts.
+	MaxRestarts int
+}
+
+// Process is a supervised backend process bound to a snapshot directory.
+type Process struct {
+	dir string
+	cfg Config
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	restarts int
+	stopped  bool
+	done     chan struct{}
+}
+
+// Start launches the configured backend for the snapshot directory and
+// records its pid under it. The returned process is supervised until Stop is
+// called.
+func Start(ctx context.Context, dir string, cfg Config) (*Process, error) {
+	if cfg.Binary == "" {
+		return nil, fmt.Errorf("no backend binary configured for %s", dir)
+	}
+	if cfg.HealthInterval <= 0 {
+		cfg.HealthInterval = defaultHealthInterval
+	}
+	p := &Process{
+		dir:  dir,
+		cfg:  cfg,
+		done: make(chan struct{}),
+	}
+	if err := p.launch(ctx); err != nil {
+		return nil, err
+	}
+	go p.supervise(context.WithoutCancel(ctx))
+	return p, nil
+}
+
+// Attach binds to a backend recorded in the pidfile of the snapshot
+// directory by a previous daemon instance. The reattached process is not
+// restarted on crash, but Stop terminates it directly by pid.
+func Attach(dir string) (*Process, error) {
+	pid, err := readPidFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !alive(pid) {
+		return nil, fmt.Errorf("recorded backend pid %d of %s is not running", pid, dir)
+	}
+	return &Process{
+		dir:     dir,
+		stopped: true, // no supervision loop to signal
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Pid returns the pid of the running backend, or zero when it is not
+// running.
+func (p *Process) Pid() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != nil && p.cmd.Process != nil {
+		return p.cmd.Process.Pid
+	}
+	pid, err := readPidFile(p.dir)
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// Stop terminates the backend and removes its pid record. It signals
+// SIGTERM first and escalates to SIGKILL after a grace period.
+func (p *Process) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	close(p.done)
+	return Kill(ctx, p.dir)
+}
+
+// Kill terminates the backend recorded in the pidfile of the snapshot
+// directory, for teardown paths without a live Process, and removes the
+// record. Directories without a record or with a dead backend are a no-op.
+func Kill(ctx context.Context, dir string) error {
+	pid, err := readPidFile(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if alive(pid) {
+		if err := terminate(ctx, pid); err != nil {
+			return fmt.Errorf("failed to terminate backend %d of %s: %w", pid, dir, err)
+		}
+	}
+	return os.Remove(pidFilePath(dir))
+}
+
+// launch starts the backend and records its pid. Callers hold no locks.
+func (p *Process) launch(ctx context.Context) error {
+	cmd := exec.Command(p.cfg.Binary, p.cfg.Args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start backend %s for %s: %w", p.cfg.Binary, p.dir, err)
+	}
+	if err := writePidFile(p.dir, cmd.Process.Pid); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	p.mu.Lock()
+	p.cmd = cmd
+	p.mu.Unlock()
+	return nil
+}
+
+// supervise waits on the backend, probes its health and relaunches it on
+// failure until Stop is called or the restart budget is spent.
+func (p *Process) supervise(ctx context.Context) {
+	for {
+		p.mu.Lock()
+		cmd := p.cmd
+		p.mu.Unlock()
+
+		exited := make(chan error, 1)
+		go func() {
+			exited <- cmd.Wait()
+		}()
+
+		err := p.watch(ctx, exited)
+		if err == nil {
+			// Stopped deliberately.
+			return
+		}
+
+		p.mu.Lock()
+		stopped := p.stopped
+		restart := p.restarts < p.cfg.MaxRestarts
+		if restart {
+			p.restarts++
+		}
+		p.mu.Unlock()
+		if stopped {
+			return
+		}
+		if !restart {
+			log.G(ctx).WithError(err).Errorf("backend for %s failed and exhausted %d restarts", p.dir, p.cfg.MaxRestarts)
+			return
+		}
+		log.G(ctx).WithError(err).Warnf("restarting backend for %s", p.dir)
+		time.Sleep(restartBackoff)
+		if err := p.launch(ctx); err != nil {
+			log.G(ctx).WithError(err).Errorf("failed to restart backend for %s", p.dir)
+			return
+		}
+	}
+}
+
+// watch blocks until the backend exits, fails a health probe or Stop is
+// called. A nil return means Stop; otherwise the error describes why the
+// backend needs a restart.
+func (p *Process) watch(ctx context.Context, exited <-chan error) error {
+	ticker := time.NewTicker(p.cfg.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return nil
+		case err := <-exited:
+			if err == nil {
+				err = fmt.Errorf("backend exited")
+			}
+			return err
+		case <-ticker.C:
+			if p.cfg.HealthCheck == nil {
+				continue
+			}
+			if err := p.cfg.HealthCheck(ctx); err != nil {
+				p.mu.Lock()
+				cmd := p.cmd
+				p.mu.Unlock()
+				if cmd.Process != nil {
+					cmd.Process.Kill()
+					<-exited
+				}
+				return fmt.Errorf("health check failed: %w", err)
+			}
+		}
+	}
+}
+
+// terminate signals pid with SIGTERM and escalates to SIGKILL when it does
+// not exit within the grace period.
+func terminate(ctx context.Context, pid int) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		if err == syscall.ESRCH {
+			return nil
+		}
+		return err
+	}
+	deadline := time.Now().Add(defaultStopTimeout)
+	for time.Now().Before(deadline) {
+		if !alive(pid) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if err := syscall.Kill(pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
+
+// alive reports whether pid names a running process.
+func alive(pid int) bool {
+	return syscall.Kill(pid, 0) == nil
+}
+
+func pidFilePath(dir string) string {
+	return filepath.Join(dir, pidFileName)
+}
+
+func writePidFile(dir string, pid int) error {
+	if err := os.WriteFile(pidFilePath(dir), []byte(strconv.Itoa(pid)), 0600); err != nil {
+		return fmt.Errorf("failed to record backend pid for %s: %w", dir, err)
+	}
+	return nil
+}
+
+func readPidFile(dir string) (int, error) {
+	b, err := os.ReadFile(pidFilePath(dir))
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid backend pid record in %s", dir)
+	}
+	return pid, nil
+}
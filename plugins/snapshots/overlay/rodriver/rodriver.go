@@ -0,0 +1,112 @@
+//go:build linux
+
+/*
+   Copyright The containerd Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package rodriver provides pluggable drivers serving the read-only lower
+// layers of overlay snapshots. The plain overlayfs behavior needs no driver;
+// alternative drivers can back committed layers with lazy-loading block
+// devices or other read-only filesystems, and are selected by name through
+// the snapshotter configuration without recompiling.
+package rodriver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/containerd/containerd/v2/core/mount"
+)
+
+// Driver serves the read-only lower layers of overlay snapshots.
+type Driver interface {
+	// PreProcess prepares driver state for a newly created snapshot before
+	// it is first mounted. dir is the snapshot directory and parents are
+	// the upperdir paths of its ancestors, nearest first.
+	PreProcess(ctx context.Context, dir string, parents []string, labels map[string]string) error
+	// ActiveMount returns the mounts serving an active snapshot. Returning
+	// ok false falls back to the snapshotter's plain overlayfs mounts.
+	ActiveMount(ctx context.Context, dir string, parents []string) (mounts []mount.Mount, ok bool, err error)
+	// PrepareMount returns the mounts serving a committed or view snapshot.
+	// Returning ok false falls back to the plain overlayfs mounts.
+	PrepareMount(ctx context.Context, dir string, parents []string) (mounts []mount.Mount, ok bool, err error)
+	// Commit finalizes driver state when the active snapshot in dir is
+	// committed.
+	Commit(ctx context.Context, dir string) error
+	// Cleanup releases driver state of a snapshot directory about to be
+	// removed.
+	Cleanup(ctx context.Context, dir string) error
+}
+
+// Reconciler is optionally implemented by drivers keeping host state outside
+// the snapshot directories, such as configfs targets or loopback devices.
+// Reconcile removes driver state referencing none of the given snapshot
+// directories, repairing resources leaked by crashes.
+type Reconciler interface {
+	Reconcile(ctx context.Context, referenced map[string]struct{}) error
+}
+
+// Recoverer is optionally implemented by drivers whose mounts depend on
+// host state that does not survive a daemon restart, such as attached block
+// devices or loopback mounts. Recover verifies the state serving an active
+// snapshot is still alive and re-establishes it when missing, before any
+// container resumes I/O on it.
+type Recoverer interface {
+	Recover(ctx context.Context, dir string, parents []string) error
+}
+
+// Factory constructs a driver rooted at the snapshotter root directory.
+type Factory func(root string) (Driver, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Factory{}
+)
+
+// Register makes a driver factory selectable by name. Drivers register
+// themselves from an init function; registering the same name twice panics.
+func Register(name string, f Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if _, ok := drivers[name]; ok {
+		panic(fmt.Sprintf("ro driver %q is already registered", name))
+	}
+	drivers[name] = f
+}
+
+// New constructs the named driver rooted at the snapshotter root.
+func New(name, root string) (Driver, error) {
+	driversMu.RLock()
+	f, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown ro driver %q, registered drivers are %v", name, Drivers())
+	}
+	return f(root)
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}